@@ -0,0 +1,142 @@
+package runn
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"go.uber.org/multierr"
+)
+
+// BaselineVersion is the version of the format of the baseline file.
+const BaselineVersion = 1
+
+// defaultBaselineTolerance - The step fails when its elapsed time is more than 20% slower than the baseline.
+const defaultBaselineTolerance = 0.2
+
+// BaselineFile - Elapsed time of each step written to the baseline file ( JSON ) by UpdateBaseline and compared by Baseline.
+type BaselineFile struct {
+	// Version is the version of the format ( BaselineVersion )
+	Version int `json:"version"`
+	// Books are the elapsed times ( milliseconds ) of the steps by the step keys, by the runbooks.
+	// A runbook is identified in the same way as the checkpoint file ( e.g. "path/to/book.yml (env=dev)" ).
+	Books map[string]map[string]float64 `json:"books"`
+}
+
+// baseline - Elapsed time of each step to guard against latency regressions.
+type baseline struct {
+	path string
+	// write the elapsed times to the baseline file instead of comparing them
+	update bool
+	// allowed ratio of slowdown from the baseline ( 0.2: 20% )
+	tolerance float64
+	books     map[string]map[string]float64
+	mu        sync.Mutex
+}
+
+func newBaseline(path string, update bool, tolerance *float64) (*baseline, error) {
+	bf, err := readBaselineFile(path)
+	if err != nil {
+		return nil, err
+	}
+	b := &baseline{
+		path:      path,
+		update:    update,
+		tolerance: defaultBaselineTolerance,
+		books:     bf.Books,
+	}
+	if tolerance != nil {
+		b.tolerance = *tolerance
+	}
+	return b, nil
+}
+
+// readBaselineFile reads the baseline file. If the file does not exist, it returns the empty baseline.
+func readBaselineFile(p string) (*BaselineFile, error) {
+	bf := &BaselineFile{Version: BaselineVersion, Books: map[string]map[string]float64{}}
+	b, err := os.ReadFile(p)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return bf, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(b, bf); err != nil {
+		return nil, fmt.Errorf("invalid baseline file %s: %w", p, err)
+	}
+	if bf.Version != BaselineVersion {
+		return nil, fmt.Errorf("unsupported version of baseline file %s: %d", p, bf.Version)
+	}
+	if bf.Books == nil {
+		bf.Books = map[string]map[string]float64{}
+	}
+	return bf, nil
+}
+
+// apply writes the elapsed times of the steps of the runbook to the baseline file ( UpdateBaseline ),
+// or compares them with the baseline and fails the steps slower than the baseline beyond the tolerance.
+func (b *baseline) apply(o *operator) error {
+	key := o.checkpointKey()
+	if b.update {
+		return b.write(key, o.steps)
+	}
+	b.mu.Lock()
+	base := b.books[key]
+	b.mu.Unlock()
+	var merr error
+	for i, s := range o.steps {
+		if s.result == nil || s.result.Skipped || s.result.Err != nil {
+			continue
+		}
+		ms, ok := base[s.key]
+		if !ok {
+			continue
+		}
+		want := time.Duration(ms * float64(time.Millisecond))
+		limit := time.Duration(float64(want) * (1 + b.tolerance))
+		if s.result.Elapsed <= limit {
+			continue
+		}
+		err := fmt.Errorf("elapsed time regression on %s: %v is more than %v%% slower than the baseline %v", o.stepName(i), s.result.Elapsed, b.tolerance*100, want)
+		s.result.Err = err
+		merr = multierr.Append(merr, err)
+	}
+	return merr
+}
+
+// write records the elapsed times of the steps that passed and writes the baseline file.
+func (b *baseline) write(key string, steps []*step) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	elapsed := map[string]float64{}
+	for _, s := range steps {
+		if s.result == nil || s.result.Skipped || s.result.Err != nil {
+			continue
+		}
+		elapsed[s.key] = milliseconds(s.result.Elapsed)
+	}
+	b.books[key] = elapsed
+	out, err := json.MarshalIndent(&BaselineFile{Version: BaselineVersion, Books: b.books}, "", "  ")
+	if err != nil {
+		return err
+	}
+	// write to the temporary file and rename it so that the baseline file is not broken when interrupted
+	tmp, err := os.CreateTemp(filepath.Dir(b.path), filepath.Base(b.path)+".*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(append(out, '\n')); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), b.path)
+}
@@ -0,0 +1,72 @@
+package runn
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBaseline(t *testing.T) {
+	ctx := context.Background()
+	p := filepath.Join(t.TempDir(), "baseline.json")
+	book := "testdata/book/baseline.yml"
+
+	// update
+	o, err := New(Book(book), Baseline(p), UpdateBaseline(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := o.Run(ctx); err != nil {
+		t.Fatal(err)
+	}
+	bf, err := readBaselineFile(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, ok := bf.Books[book]
+	if !ok {
+		t.Fatalf("the baseline of %s is not written: %v", book, bf.Books)
+	}
+	if len(got) != 2 || got["0"] < 50 {
+		t.Errorf("invalid baseline: %v", got)
+	}
+
+	tests := []struct {
+		wait      string
+		tolerance float64
+		wantErr   bool
+	}{
+		{"50ms", 1.0, false},
+		{"300ms", 1.0, true},
+		{"300ms", 10.0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.wait, func(t *testing.T) {
+			o, err := New(Book(book), Baseline(p), BaselineTolerance(tt.tolerance), Var("wait", tt.wait))
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = o.Run(ctx)
+			if !tt.wantErr {
+				if err != nil {
+					t.Error(err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), "elapsed time regression") {
+				t.Errorf("got %v\nwant elapsed time regression", err)
+			}
+			srs := o.Result().StepResults
+			if srs[0].Err == nil || srs[1].Err != nil {
+				t.Errorf("got %v, %v\nwant the regression of steps[0] only", srs[0].Err, srs[1].Err)
+			}
+		})
+	}
+}
+
+func TestBaselineTolerance(t *testing.T) {
+	if _, err := New(BaselineTolerance(-0.1)); err == nil {
+		t.Error("want error")
+	}
+}
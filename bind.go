@@ -26,15 +26,23 @@ func (rnr *bindRunner) Run(ctx context.Context, cond map[string]string, first bo
 		store[storePreviousKey] = rnr.operator.store.previous()
 		store[storeCurrentKey] = rnr.operator.store.latest()
 	}
+	o := rnr.operator
+	stepKey := ""
+	if o.stepIdx < len(o.steps) {
+		stepKey = o.steps[o.stepIdx].key
+	}
 	for k, v := range cond {
 		if k == storeVarsKey || k == storeStepsKey || k == storeParentKey || k == storeIncludedKey || k == storeCurrentKey || k == storePreviousKey || k == loopCountVarKey {
 			return fmt.Errorf("'%s' is reserved", k)
 		}
-		vv, err := Eval(v, store)
+		if k == storeBoundKey && rnr.operator.store.boundVars != nil {
+			return fmt.Errorf("'%s' is reserved", k)
+		}
+		vv, err := Eval(rnr.operator.store.resolveRelativeIndex(v).(string), store)
 		if err != nil {
 			return err
 		}
-		rnr.operator.store.bindVars[k] = vv
+		rnr.operator.store.bind(stepKey, k, vv)
 	}
 	if first {
 		rnr.operator.record(nil)
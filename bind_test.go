@@ -2,6 +2,7 @@ package runn
 
 import (
 	"context"
+	"fmt"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -24,7 +25,8 @@ func TestBindRunnerRun(t *testing.T) {
 				steps: []map[string]interface{}{
 					{"run": true},
 				},
-				vars: map[string]interface{}{},
+				stepIdxs: []int{0},
+				vars:     map[string]interface{}{},
 			},
 			map[string]interface{}{
 				"steps": []map[string]interface{}{
@@ -48,6 +50,7 @@ func TestBindRunnerRun(t *testing.T) {
 				steps: []map[string]interface{}{
 					{"run": true},
 				},
+				stepIdxs: []int{0},
 				vars: map[string]interface{}{
 					"key": "value",
 				},
@@ -155,3 +158,64 @@ func TestBindRunnerRunError(t *testing.T) {
 		}
 	}
 }
+
+func TestBindThenTestInSameStep(t *testing.T) {
+	ctx := context.Background()
+	o, err := New(Book("testdata/book/bind.yml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := o.Run(ctx); err != nil {
+		t.Error(err)
+	}
+	want := map[string]interface{}{
+		"first":    "hello\n",
+		"greeting": "hello",
+		"second":   "hello\n",
+	}
+	if diff := cmp.Diff(o.store.bindVars, want, nil); diff != "" {
+		t.Errorf("%s", diff)
+	}
+}
+
+func TestNamespaceBinds(t *testing.T) {
+	tests := []struct {
+		enable  bool
+		wantErr bool
+	}{
+		{true, false},
+		{false, true},
+	}
+	ctx := context.Background()
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%v", tt.enable), func(t *testing.T) {
+			o, err := New(Book("testdata/namespace_binds.yml"), NamespaceBinds(tt.enable))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := o.Run(ctx); err != nil {
+				if !tt.wantErr {
+					t.Error(err)
+				}
+				return
+			}
+			if tt.wantErr {
+				t.Error("want error")
+			}
+		})
+	}
+
+	t.Run("reserved", func(t *testing.T) {
+		o, err := New(NamespaceBinds(true))
+		if err != nil {
+			t.Fatal(err)
+		}
+		r, err := newBindRunner(o)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := r.Run(ctx, map[string]string{"bound": "1"}, true); err == nil {
+			t.Error("want error")
+		}
+	})
+}
@@ -1,11 +1,14 @@
 package runn
 
 import (
+	"context"
 	"net/http"
 	"net/url"
 	"os"
+	"path/filepath"
 	"reflect"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -84,6 +87,45 @@ func TestLoadBook(t *testing.T) {
 	}
 }
 
+func TestLoadBookWithStepsFiles(t *testing.T) {
+	tests := []struct {
+		path     string
+		wantKeys []string
+		wantErr  string
+	}{
+		{"testdata/steps_files/main.yml", []string{"setup", "signup", "login"}, ""},
+		{"testdata/steps_files/duplicate.yml", nil, "duplicate step keys: signup"},
+		{"testdata/steps_files/mixed.yml", nil, "only steps of the same type can be concatenated"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			bk, err := LoadBook(tt.path)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("got %v\nwant %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(bk.stepKeys, tt.wantKeys, nil); diff != "" {
+				t.Error(diff)
+			}
+			if got := bk.stepSources[1].path; !strings.HasSuffix(got, filepath.Join("steps_files", "signup.yml")) {
+				t.Errorf("got %v\nwant the path of the steps file", got)
+			}
+			o, err := New(Book(tt.path))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := o.Run(context.Background()); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
 func TestApplyOptions(t *testing.T) {
 	tests := []struct {
 		opts []Option
@@ -151,3 +193,121 @@ func TestParseRunnerForHttpRunner(t *testing.T) {
 		}
 	}
 }
+
+func TestParseRunnerForDBRunner(t *testing.T) {
+	tests := []struct {
+		v               interface{}
+		wantTimeLayouts []string
+		wantNumericAs   string
+		wantErr         bool
+	}{
+		{"sqlite://test.db", nil, "", false},
+		{map[string]interface{}{"dsn": "sqlite://test.db"}, nil, "", false},
+		{
+			map[string]interface{}{
+				"dsn":         "sqlite://test.db",
+				"timeLayouts": []interface{}{"02/01/2006", "2006.01.02"},
+			},
+			[]string{"02/01/2006", "2006.01.02"},
+			"",
+			false,
+		},
+		{map[string]interface{}{"dsn": "sqlite://test.db", "numericAs": "float"}, nil, "float", false},
+		{map[string]interface{}{"dsn": "sqlite://test.db", "numericAs": "decimal"}, nil, "", true},
+	}
+	for _, tt := range tests {
+		bk := newBook()
+		if err := bk.parseRunner("db", tt.v); err != nil {
+			if !tt.wantErr {
+				t.Error(err)
+			}
+			continue
+		}
+		if tt.wantErr {
+			t.Errorf("want error: %v", tt.v)
+			continue
+		}
+		got, ok := bk.dbRunners["db"]
+		if !ok {
+			t.Fatalf("db runner not found: %v", tt.v)
+		}
+		if diff := cmp.Diff(got.timeLayouts, tt.wantTimeLayouts); diff != "" {
+			t.Error(diff)
+		}
+		if got.numericAs != tt.wantNumericAs {
+			t.Errorf("got %q\nwant %q", got.numericAs, tt.wantNumericAs)
+		}
+	}
+}
+
+func TestParseRunnerForDBRunnerGroup(t *testing.T) {
+	tests := []struct {
+		v       interface{}
+		want    *dbRunnerGroup
+		wantErr bool
+	}{
+		{map[string]interface{}{"read": "reader", "write": "writer"}, &dbRunnerGroup{read: "reader", write: "writer"}, false},
+		{map[string]interface{}{"read": "reader"}, nil, true},
+		{map[string]interface{}{"dsn": "sqlite://test.db"}, nil, false},
+	}
+	for _, tt := range tests {
+		bk := newBook()
+		if err := bk.parseRunner("db", tt.v); err != nil {
+			if !tt.wantErr {
+				t.Error(err)
+			}
+			continue
+		}
+		if tt.wantErr {
+			t.Errorf("want error: %v", tt.v)
+			continue
+		}
+		got, ok := bk.dbRunners["db"]
+		if !ok {
+			t.Fatalf("db runner not found: %v", tt.v)
+		}
+		if diff := cmp.Diff(got.group, tt.want, cmp.AllowUnexported(dbRunnerGroup{})); diff != "" {
+			t.Error(diff)
+		}
+	}
+}
+
+func TestDeepMerge(t *testing.T) {
+	tests := []struct {
+		dst  map[string]interface{}
+		src  map[string]interface{}
+		want map[string]interface{}
+	}{
+		{
+			nil,
+			map[string]interface{}{"a": 1},
+			map[string]interface{}{"a": 1},
+		},
+		{
+			map[string]interface{}{"a": map[string]interface{}{"b": 1, "c": 2}},
+			map[string]interface{}{"a": map[string]interface{}{"c": 3, "d": 4}},
+			map[string]interface{}{"a": map[string]interface{}{"b": 1, "c": 3, "d": 4}},
+		},
+		{
+			map[string]interface{}{"a": []interface{}{1, 2}, "b": "x"},
+			map[string]interface{}{"a": []interface{}{3}},
+			map[string]interface{}{"a": []interface{}{3}, "b": "x"},
+		},
+		{
+			map[string]interface{}{"a": "x"},
+			map[string]interface{}{"a": map[string]interface{}{"b": 1}},
+			map[string]interface{}{"a": map[string]interface{}{"b": 1}},
+		},
+		{
+			map[string]interface{}{"a": map[string]interface{}{"b": 1}},
+			map[string]interface{}{"a": nil},
+			map[string]interface{}{"a": nil},
+		},
+	}
+	for _, tt := range tests {
+		got := deepMerge(tt.dst, tt.src)
+		if diff := cmp.Diff(got, tt.want, nil); diff != "" {
+			t.Errorf("%s", diff)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package builtin
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// Approx returns whether |x-y| <= epsilon.
+// Numeric strings ( e.g. DECIMAL columns ) are also accepted.
+func Approx(x, y, epsilon interface{}) bool {
+	ok, err := approx(x, y, epsilon)
+	if err != nil {
+		panic(err)
+	}
+	return ok
+}
+
+func approx(x, y, epsilon interface{}) (bool, error) {
+	fx, err := toFloat64(x)
+	if err != nil {
+		return false, fmt.Errorf("approx: invalid first argument: %w", err)
+	}
+	fy, err := toFloat64(y)
+	if err != nil {
+		return false, fmt.Errorf("approx: invalid second argument: %w", err)
+	}
+	fe, err := toFloat64(epsilon)
+	if err != nil {
+		return false, fmt.Errorf("approx: invalid epsilon: %w", err)
+	}
+	if fe < 0 {
+		return false, fmt.Errorf("approx: epsilon should be non-negative: %v", epsilon)
+	}
+	return math.Abs(fx-fy) <= fe, nil
+}
+
+func toFloat64(v interface{}) (float64, error) {
+	switch vv := v.(type) {
+	case nil:
+		return 0, fmt.Errorf("nil is not a number")
+	case float64:
+		return vv, nil
+	case float32:
+		return float64(vv), nil
+	case int:
+		return float64(vv), nil
+	case int8:
+		return float64(vv), nil
+	case int16:
+		return float64(vv), nil
+	case int32:
+		return float64(vv), nil
+	case int64:
+		return float64(vv), nil
+	case uint:
+		return float64(vv), nil
+	case uint8:
+		return float64(vv), nil
+	case uint16:
+		return float64(vv), nil
+	case uint32:
+		return float64(vv), nil
+	case uint64:
+		return float64(vv), nil
+	case json.Number:
+		return vv.Float64()
+	case string:
+		f, err := strconv.ParseFloat(vv, 64)
+		if err != nil {
+			return 0, fmt.Errorf("%q is not a number", vv)
+		}
+		return f, nil
+	default:
+		return 0, fmt.Errorf("%T(%v) is not a number", v, v)
+	}
+}
@@ -0,0 +1,40 @@
+package builtin
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestApprox(t *testing.T) {
+	tests := []struct {
+		x       interface{}
+		y       interface{}
+		epsilon interface{}
+		want    bool
+		wantErr bool
+	}{
+		{1.0, 1.0, 0, true, false},
+		{0.1 + 0.2, 0.3, 1e-9, true, false},
+		{1.0, 1.1, 0.01, false, false},
+		{1.0, 1.1, 0.2, true, false},
+		{uint64(3), 3.0001, 0.001, true, false},
+		{int64(-3), -2.5, 0.5, true, false},
+		{"12.50", 12.5, 0.001, true, false},
+		{json.Number("1.005"), 1.0, 0.01, true, false},
+		{nil, 1.0, 0.01, false, true},
+		{1.0, "foo", 0.01, false, true},
+		{1.0, 1.0, true, false, true},
+		{1.0, 1.0, -0.1, false, true},
+		{map[string]interface{}{}, 1.0, 0.01, false, true},
+	}
+	for _, tt := range tests {
+		got, err := approx(tt.x, tt.y, tt.epsilon)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("approx(%v, %v, %v): got error %v", tt.x, tt.y, tt.epsilon, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("approx(%v, %v, %v): got %v want %v", tt.x, tt.y, tt.epsilon, got, tt.want)
+		}
+	}
+}
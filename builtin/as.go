@@ -0,0 +1,120 @@
+package builtin
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// AsInt converts the value to int regardless of the representation of the driver ( e.g. int64, float64, numeric strings and []byte ).
+// Booleans are converted to 1 or 0. It fails if the value is not an integer.
+func AsInt(v interface{}) int {
+	i, err := asInt(v)
+	if err != nil {
+		panic(err)
+	}
+	return i
+}
+
+// AsFloat converts the value to float64 regardless of the representation of the driver ( e.g. int64, numeric strings and []byte ).
+func AsFloat(v interface{}) float64 {
+	f, err := asFloat(v)
+	if err != nil {
+		panic(err)
+	}
+	return f
+}
+
+// AsString converts the value to string regardless of the representation of the driver ( e.g. []byte, int64 and float64 ).
+// Numbers are formatted without exponents and times are formatted in RFC 3339.
+func AsString(v interface{}) string {
+	s, err := asString(v)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+func asInt(v interface{}) (int, error) {
+	switch vv := v.(type) {
+	case bool:
+		if vv {
+			return 1, nil
+		}
+		return 0, nil
+	case int:
+		return vv, nil
+	case int64:
+		return int(vv), nil
+	case []byte:
+		return asInt(string(vv))
+	case string:
+		s := strings.TrimSpace(vv)
+		if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return int(i), nil
+		}
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return 0, fmt.Errorf("asInt: %q is not a number", vv)
+		}
+		return floatToInt(f, v)
+	case json.Number:
+		if i, err := vv.Int64(); err == nil {
+			return int(i), nil
+		}
+	}
+	f, err := toFloat64(v)
+	if err != nil {
+		return 0, fmt.Errorf("asInt: %w", err)
+	}
+	return floatToInt(f, v)
+}
+
+func floatToInt(f float64, v interface{}) (int, error) {
+	if f != math.Trunc(f) || math.IsInf(f, 0) {
+		return 0, fmt.Errorf("asInt: %v is not an integer", v)
+	}
+	return int(f), nil
+}
+
+func asFloat(v interface{}) (float64, error) {
+	switch vv := v.(type) {
+	case []byte:
+		return asFloat(string(vv))
+	case string:
+		v = strings.TrimSpace(vv)
+	}
+	f, err := toFloat64(v)
+	if err != nil {
+		return 0, fmt.Errorf("asFloat: %w", err)
+	}
+	return f, nil
+}
+
+func asString(v interface{}) (string, error) {
+	switch vv := v.(type) {
+	case nil:
+		return "", fmt.Errorf("asString: nil is not a string")
+	case string:
+		return vv, nil
+	case []byte:
+		return string(vv), nil
+	case bool:
+		return strconv.FormatBool(vv), nil
+	case float32:
+		return strconv.FormatFloat(float64(vv), 'f', -1, 32), nil
+	case float64:
+		return strconv.FormatFloat(vv, 'f', -1, 64), nil
+	case json.Number:
+		return vv.String(), nil
+	case time.Time:
+		return vv.Format(time.RFC3339Nano), nil
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return fmt.Sprintf("%d", vv), nil
+	default:
+		return "", fmt.Errorf("asString: %T(%v) is not a scalar", v, v)
+	}
+}
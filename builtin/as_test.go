@@ -0,0 +1,100 @@
+package builtin
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestAsInt(t *testing.T) {
+	tests := []struct {
+		v       interface{}
+		want    int
+		wantErr bool
+	}{
+		{1, 1, false},
+		{int64(42), 42, false},
+		{uint8(7), 7, false},
+		{float64(3), 3, false},
+		{"12", 12, false},
+		{" 12 ", 12, false},
+		{"12.0", 12, false},
+		{[]byte("-5"), -5, false},
+		{json.Number("9007199254740993"), 9007199254740993, false},
+		{true, 1, false},
+		{false, 0, false},
+		{1.5, 0, true},
+		{"1.5", 0, true},
+		{"foo", 0, true},
+		{nil, 0, true},
+		{map[string]interface{}{}, 0, true},
+	}
+	for _, tt := range tests {
+		got, err := asInt(tt.v)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("asInt(%v): got error %v", tt.v, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("asInt(%v): got %v want %v", tt.v, got, tt.want)
+		}
+	}
+}
+
+func TestAsFloat(t *testing.T) {
+	tests := []struct {
+		v       interface{}
+		want    float64
+		wantErr bool
+	}{
+		{1, 1.0, false},
+		{int64(42), 42.0, false},
+		{1.5, 1.5, false},
+		{"12.50", 12.5, false},
+		{[]byte("0.25"), 0.25, false},
+		{json.Number("1.005"), 1.005, false},
+		{"foo", 0, true},
+		{nil, 0, true},
+		{true, 0, true},
+	}
+	for _, tt := range tests {
+		got, err := asFloat(tt.v)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("asFloat(%v): got error %v", tt.v, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("asFloat(%v): got %v want %v", tt.v, got, tt.want)
+		}
+	}
+}
+
+func TestAsString(t *testing.T) {
+	tests := []struct {
+		v       interface{}
+		want    string
+		wantErr bool
+	}{
+		{"foo", "foo", false},
+		{[]byte("bar"), "bar", false},
+		{int64(42), "42", false},
+		{uint64(42), "42", false},
+		{12.5, "12.5", false},
+		{float64(1e21), "1000000000000000000000", false},
+		{json.Number("1.005"), "1.005", false},
+		{true, "true", false},
+		{time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC), "2023-01-02T03:04:05Z", false},
+		{nil, "", true},
+		{[]interface{}{1}, "", true},
+	}
+	for _, tt := range tests {
+		got, err := asString(tt.v)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("asString(%v): got error %v", tt.v, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("asString(%v): got %v want %v", tt.v, got, tt.want)
+		}
+	}
+}
@@ -40,6 +40,36 @@ func TestCompareWithIgnoreKeys(t *testing.T) {
 		{map[string]interface{}{"foo": "1", "bar": true}, map[string]interface{}{"foo": "1", "bar": false}, []string{"bar"}, true},
 		{map[string]interface{}{"foo": "1", "bar": true}, map[string]interface{}{"foo": "1", "bar": false}, []string{"foo"}, false},
 		{map[string]interface{}{"foo": "1", "bar": true}, map[string]interface{}{}, []string{"foo", "bar"}, true},
+		{
+			map[string]interface{}{"headers": map[string]interface{}{"Date": "a"}, "body": map[string]interface{}{"Date": "a"}},
+			map[string]interface{}{"headers": map[string]interface{}{"Date": "b"}, "body": map[string]interface{}{"Date": "b"}},
+			[]string{"headers.Date"},
+			false,
+		},
+		{
+			map[string]interface{}{"headers": map[string]interface{}{"Date": "a"}, "body": map[string]interface{}{"Date": "a"}},
+			map[string]interface{}{"headers": map[string]interface{}{"Date": "b"}, "body": map[string]interface{}{"Date": "b"}},
+			[]string{"headers.Date", "body.Date"},
+			true,
+		},
+		{
+			map[string]interface{}{"items": []interface{}{map[string]interface{}{"id": 1, "updated_at": "a"}, map[string]interface{}{"id": 2, "updated_at": "a"}}},
+			map[string]interface{}{"items": []interface{}{map[string]interface{}{"id": 1, "updated_at": "b"}, map[string]interface{}{"id": 2, "updated_at": "b"}}},
+			[]string{"items[*].updated_at"},
+			true,
+		},
+		{
+			map[string]interface{}{"items": []interface{}{map[string]interface{}{"id": 1, "updated_at": "a"}, map[string]interface{}{"id": 2, "updated_at": "a"}}},
+			map[string]interface{}{"items": []interface{}{map[string]interface{}{"id": 1, "updated_at": "b"}, map[string]interface{}{"id": 2, "updated_at": "b"}}},
+			[]string{"items[0].updated_at"},
+			false,
+		},
+		{
+			map[string]interface{}{"items": []interface{}{map[string]interface{}{"id": 1}}},
+			map[string]interface{}{"items": []interface{}{map[string]interface{}{"id": 2}}},
+			[]string{"items.id"},
+			false,
+		},
 	}
 	for _, tt := range tests {
 		got := Compare(tt.x, tt.y, tt.ignorekeys...)
@@ -0,0 +1,80 @@
+package builtin
+
+import (
+	"encoding/json"
+	"reflect"
+)
+
+// Contains returns true if all fields declared in expected match the fields in actual recursively (partial match).
+// Extra fields of maps in actual are ignored, and each element of arrays in expected should be contained by any element of the array in actual.
+func Contains(actual, expected interface{}) bool {
+	va, err := normalize(actual)
+	if err != nil {
+		return false
+	}
+	ve, err := normalize(expected)
+	if err != nil {
+		return false
+	}
+	if a, ok := va.([]interface{}); ok {
+		if _, ok := ve.(map[string]interface{}); ok {
+			// whether any element of the list contains the fields ( e.g. `contains(steps[0].columns, {name: 'email'})` )
+			for _, av := range a {
+				if contains(av, ve) {
+					return true
+				}
+			}
+			return false
+		}
+	}
+	return contains(va, ve)
+}
+
+func contains(actual, expected interface{}) bool {
+	switch e := expected.(type) {
+	case map[string]interface{}:
+		a, ok := actual.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		for k, ev := range e {
+			av, ok := a[k]
+			if !ok {
+				return false
+			}
+			if !contains(av, ev) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		a, ok := actual.([]interface{})
+		if !ok {
+			return false
+		}
+	L:
+		for _, ev := range e {
+			for _, av := range a {
+				if contains(av, ev) {
+					continue L
+				}
+			}
+			return false
+		}
+		return true
+	default:
+		return reflect.DeepEqual(actual, expected)
+	}
+}
+
+func normalize(v interface{}) (interface{}, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var n interface{}
+	if err := json.Unmarshal(b, &n); err != nil {
+		return nil, err
+	}
+	return n, nil
+}
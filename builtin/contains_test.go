@@ -0,0 +1,45 @@
+package builtin
+
+import "testing"
+
+func TestContains(t *testing.T) {
+	tests := []struct {
+		actual   interface{}
+		expected interface{}
+		want     bool
+	}{
+		{map[string]interface{}{"status": "ok", "id": 1}, map[string]interface{}{"status": "ok"}, true},
+		{map[string]interface{}{"status": "ok", "id": 1}, map[string]interface{}{"status": "ng"}, false},
+		{map[string]interface{}{"status": "ok"}, map[string]interface{}{"status": "ok", "id": 1}, false},
+		{map[string]interface{}{"id": float64(1)}, map[string]interface{}{"id": 1}, true},
+		{
+			map[string]interface{}{"user": map[string]interface{}{"name": "alice", "age": 20}},
+			map[string]interface{}{"user": map[string]interface{}{"name": "alice"}},
+			true,
+		},
+		{
+			map[string]interface{}{"users": []interface{}{map[string]interface{}{"name": "alice", "age": 20}, map[string]interface{}{"name": "bob", "age": 30}}},
+			map[string]interface{}{"users": []interface{}{map[string]interface{}{"name": "bob"}}},
+			true,
+		},
+		{
+			map[string]interface{}{"users": []interface{}{map[string]interface{}{"name": "alice"}}},
+			map[string]interface{}{"users": []interface{}{map[string]interface{}{"name": "bob"}}},
+			false,
+		},
+		{[]int{1, 2, 3}, []int{3, 1}, true},
+		{[]int{1, 2, 3}, []int{4}, false},
+		{[]int{1, 2, 3}, []int{}, true},
+		{"ok", "ok", true},
+		{map[string]interface{}{"status": "ok"}, "ok", false},
+		{nil, map[string]interface{}{"status": "ok"}, false},
+		{[]interface{}{map[string]interface{}{"name": "id", "type": "INTEGER"}, map[string]interface{}{"name": "email", "type": "TEXT"}}, map[string]interface{}{"name": "email"}, true},
+		{[]interface{}{map[string]interface{}{"name": "id", "type": "INTEGER"}}, map[string]interface{}{"name": "email"}, false},
+	}
+	for _, tt := range tests {
+		got := Contains(tt.actual, tt.expected)
+		if got != tt.want {
+			t.Errorf("Contains(%v, %v): got %v want %v", tt.actual, tt.expected, got, tt.want)
+		}
+	}
+}
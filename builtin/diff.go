@@ -2,6 +2,10 @@ package builtin
 
 import (
 	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
@@ -35,14 +39,75 @@ func diff(x, y interface{}, ignoreKeys ...string) (string, error) {
 		return "", err
 	}
 
+	keys := []string{}
+	paths := []*regexp.Regexp{}
+	for _, ignore := range ignoreKeys {
+		if !strings.ContainsAny(ignore, ".[") {
+			keys = append(keys, ignore)
+			continue
+		}
+		re, err := ignorePathRe(ignore)
+		if err != nil {
+			return "", err
+		}
+		paths = append(paths, re)
+	}
+
 	diff := cmp.Diff(vx, vy, cmpopts.IgnoreMapEntries(func(key string, val interface{}) bool {
-		for _, ignore := range ignoreKeys {
+		for _, ignore := range keys {
 			if key == ignore {
 				return true
 			}
 		}
 		return false
-	}))
+	}), cmp.FilterPath(func(p cmp.Path) bool {
+		if len(paths) == 0 {
+			return false
+		}
+		s := pathString(p)
+		for _, re := range paths {
+			if re.MatchString(s) {
+				return true
+			}
+		}
+		return false
+	}, cmp.Ignore()))
 
 	return diff, nil
 }
+
+// ignorePathRe returns the regexp of the path to ignore such as `body.items[*].updated_at` ( `[*]` matches any index ).
+func ignorePathRe(p string) (*regexp.Regexp, error) {
+	q := strings.ReplaceAll(regexp.QuoteMeta(p), `\[\*\]`, `\[\d+\]`)
+	re, err := regexp.Compile(fmt.Sprintf("^%s$", q))
+	if err != nil {
+		return nil, fmt.Errorf("invalid path to ignore: %s: %w", p, err)
+	}
+	return re, nil
+}
+
+// pathString returns the path of the value such as `body.items[0].updated_at`.
+func pathString(p cmp.Path) string {
+	var b strings.Builder
+	for _, ps := range p {
+		switch s := ps.(type) {
+		case cmp.MapIndex:
+			if b.Len() > 0 {
+				b.WriteString(".")
+			}
+			k := s.Key()
+			if k.Kind() == reflect.String {
+				b.WriteString(k.String())
+			} else {
+				b.WriteString(fmt.Sprintf("%v", k.Interface()))
+			}
+		case cmp.SliceIndex:
+			i, j := s.SplitKeys()
+			if i < 0 {
+				i = j
+			}
+			b.WriteString(fmt.Sprintf("[%d]", i))
+		}
+	}
+	return b.String()
+}
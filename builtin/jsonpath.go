@@ -0,0 +1,36 @@
+package builtin
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/ohler55/ojg/jp"
+)
+
+// compiled JSONPath expressions
+var jsonPathCache sync.Map
+
+// JSONPath returns the values matched by the JSONPath expression as a slice.
+func JSONPath(obj interface{}, path string) ([]interface{}, error) {
+	x, err := compileJSONPath(path)
+	if err != nil {
+		return nil, err
+	}
+	got := x.Get(obj)
+	if got == nil {
+		return []interface{}{}, nil
+	}
+	return got, nil
+}
+
+func compileJSONPath(path string) (jp.Expr, error) {
+	if v, ok := jsonPathCache.Load(path); ok {
+		return v.(jp.Expr), nil
+	}
+	x, err := jp.ParseString(path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JSONPath %q: %w", path, err)
+	}
+	jsonPathCache.Store(path, x)
+	return x, nil
+}
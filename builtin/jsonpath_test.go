@@ -0,0 +1,53 @@
+package builtin
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestJSONPath(t *testing.T) {
+	obj := map[string]interface{}{
+		"items": []interface{}{
+			map[string]interface{}{"id": float64(1), "active": true},
+			map[string]interface{}{"id": float64(2), "active": false},
+			map[string]interface{}{"id": float64(3), "active": true},
+		},
+	}
+	tests := []struct {
+		obj     interface{}
+		path    string
+		want    []interface{}
+		wantErr bool
+	}{
+		{obj, "$.items[?(@.active == true)].id", []interface{}{float64(1), float64(3)}, false},
+		{obj, "$.items[*].id", []interface{}{float64(1), float64(2), float64(3)}, false},
+		{obj, "$.items[?(@.id > 1)].id", []interface{}{float64(2), float64(3)}, false},
+		{obj, "$..id", []interface{}{float64(1), float64(2), float64(3)}, false},
+		{obj, "$.nonexistent", []interface{}{}, false},
+		{
+			[]map[string]interface{}{{"name": "alice"}, {"name": "bob"}},
+			"$[*].name",
+			[]interface{}{"alice", "bob"},
+			false,
+		},
+		{obj, "$.items[", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got, err := JSONPath(tt.obj, tt.path)
+			if err != nil {
+				if !tt.wantErr {
+					t.Error(err)
+				}
+				return
+			}
+			if tt.wantErr {
+				t.Error("want error")
+			}
+			if diff := cmp.Diff(got, tt.want); diff != "" {
+				t.Errorf("%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,160 @@
+package builtin
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// schemaKey - key of the candidate of MatchesAny to be treated as JSON Schema.
+const schemaKey = "$schema"
+
+// MatchesAny returns true if the value matches at least one of the candidates.
+// A candidate that has `$schema` is a JSON Schema ( validated as the Schema Object of OpenAPI 3 ),
+// and the other candidates are matched partially in the same way as Contains.
+func MatchesAny(v interface{}, candidates []interface{}) bool {
+	d, err := matchesAnyDiff(v, candidates)
+	if err != nil {
+		panic(err)
+	}
+	return d == ""
+}
+
+// MatchesAnyDiff returns why the value matches none of the candidates of MatchesAny ( empty if the value matches any ).
+func MatchesAnyDiff(v interface{}, candidates []interface{}) string {
+	d, err := matchesAnyDiff(v, candidates)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+func matchesAnyDiff(v interface{}, candidates []interface{}) (string, error) {
+	if len(candidates) == 0 {
+		return "", errors.New("matchesAny: no candidates")
+	}
+	nv, err := normalize(v)
+	if err != nil {
+		return "", fmt.Errorf("matchesAny: invalid value: %w", err)
+	}
+	var b strings.Builder
+	for i, c := range candidates {
+		nc, err := normalize(c)
+		if err != nil {
+			return "", fmt.Errorf("matchesAny: invalid candidate[%d]: %w", i, err)
+		}
+		var reasons []string
+		if m, ok := nc.(map[string]interface{}); ok && m[schemaKey] != nil {
+			reasons, err = schemaReasons(nv, m)
+			if err != nil {
+				return "", fmt.Errorf("matchesAny: invalid schema of candidate[%d]: %w", i, err)
+			}
+			if len(reasons) == 0 {
+				return "", nil
+			}
+			_, _ = fmt.Fprintf(&b, "candidate[%d] (schema):\n", i)
+		} else {
+			reasons = containsReasons(nv, nc, "")
+			if len(reasons) == 0 {
+				return "", nil
+			}
+			_, _ = fmt.Fprintf(&b, "candidate[%d]:\n", i)
+		}
+		for _, r := range reasons {
+			_, _ = fmt.Fprintf(&b, "  %s\n", r)
+		}
+	}
+	return b.String(), nil
+}
+
+// schemaReasons returns the reasons why the value is invalid for the schema.
+func schemaReasons(v interface{}, schema map[string]interface{}) ([]string, error) {
+	b, err := json.Marshal(schema)
+	if err != nil {
+		return nil, err
+	}
+	s := &openapi3.Schema{}
+	if err := s.UnmarshalJSON(b); err != nil {
+		return nil, err
+	}
+	err = s.VisitJSON(v, openapi3.MultiErrors())
+	if err == nil {
+		return nil, nil
+	}
+	var errs []error
+	var me openapi3.MultiError
+	if errors.As(err, &me) {
+		errs = me
+	} else {
+		errs = []error{err}
+	}
+	var reasons []string
+	for _, e := range errs {
+		var se *openapi3.SchemaError
+		if !errors.As(e, &se) {
+			reasons = append(reasons, e.Error())
+			continue
+		}
+		p := "/" + strings.Join(se.JSONPointer(), "/")
+		reasons = append(reasons, fmt.Sprintf("%s: %s", p, se.Reason))
+	}
+	sort.Strings(reasons)
+	return reasons, nil
+}
+
+// containsReasons returns the paths of the fields of expected that are not contained by actual ( the opposite of contains ).
+func containsReasons(actual, expected interface{}, path string) []string {
+	switch e := expected.(type) {
+	case map[string]interface{}:
+		a, ok := actual.(map[string]interface{})
+		if !ok {
+			return []string{fmt.Sprintf("%s: got %s, want object", pathOrRoot(path), jsonString(actual))}
+		}
+		keys := make([]string, 0, len(e))
+		for k := range e {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		var reasons []string
+		for _, k := range keys {
+			p := fmt.Sprintf("%s.%s", path, k)
+			av, ok := a[k]
+			if !ok {
+				reasons = append(reasons, fmt.Sprintf("%s: missing", p))
+				continue
+			}
+			reasons = append(reasons, containsReasons(av, e[k], p)...)
+		}
+		return reasons
+	case []interface{}:
+		if !contains(actual, expected) {
+			return []string{fmt.Sprintf("%s: got %s, want to contain %s", pathOrRoot(path), jsonString(actual), jsonString(expected))}
+		}
+		return nil
+	default:
+		if !reflect.DeepEqual(actual, expected) {
+			return []string{fmt.Sprintf("%s: got %s, want %s", pathOrRoot(path), jsonString(actual), jsonString(expected))}
+		}
+		return nil
+	}
+}
+
+func pathOrRoot(path string) string {
+	if path == "" {
+		return "."
+	}
+	return path
+}
+
+func jsonString(v interface{}) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(b)
+}
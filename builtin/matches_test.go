@@ -0,0 +1,65 @@
+package builtin
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMatchesAny(t *testing.T) {
+	userSchema := map[string]interface{}{
+		"$schema":  "https://json-schema.org/draft/2020-12/schema",
+		"type":     "object",
+		"required": []interface{}{"id", "name"},
+		"properties": map[string]interface{}{
+			"id":   map[string]interface{}{"type": "integer"},
+			"name": map[string]interface{}{"type": "string"},
+		},
+	}
+	errorPartial := map[string]interface{}{"error": map[string]interface{}{"code": 404}}
+	tests := []struct {
+		v          interface{}
+		candidates []interface{}
+		want       bool
+		wantDiffs  []string
+		wantErr    bool
+	}{
+		{map[string]interface{}{"id": 1, "name": "alice"}, []interface{}{userSchema, errorPartial}, true, nil, false},
+		{map[string]interface{}{"error": map[string]interface{}{"code": 404, "message": "not found"}}, []interface{}{userSchema, errorPartial}, true, nil, false},
+		{
+			map[string]interface{}{"id": "1"},
+			[]interface{}{userSchema, errorPartial},
+			false,
+			[]string{"candidate[0] (schema):", `/id: value must be an integer`, `/name: property "name" is missing`, "candidate[1]:", ".error: missing"},
+			false,
+		},
+		{
+			map[string]interface{}{"error": map[string]interface{}{"code": 500}},
+			[]interface{}{errorPartial},
+			false,
+			[]string{".error.code: got 500, want 404"},
+			false,
+		},
+		{[]interface{}{1, 2}, []interface{}{[]interface{}{3}}, false, []string{".: got [1,2], want to contain [3]"}, false},
+		{"ok", []interface{}{"ok"}, true, nil, false},
+		{"ok", []interface{}{}, false, nil, true},
+		{"ok", []interface{}{map[string]interface{}{"$schema": "x", "type": 1}}, false, nil, true},
+	}
+	for _, tt := range tests {
+		got, err := matchesAnyDiff(tt.v, tt.candidates)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("matchesAny(%v, %v): got error %v", tt.v, tt.candidates, err)
+			continue
+		}
+		if tt.wantErr {
+			continue
+		}
+		if (got == "") != tt.want {
+			t.Errorf("matchesAny(%v, %v): got %q", tt.v, tt.candidates, got)
+		}
+		for _, want := range tt.wantDiffs {
+			if !strings.Contains(got, want) {
+				t.Errorf("got %s\nwant %s", got, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package builtin
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// IsSorted returns whether the rows ( slice of maps ) are ordered by the value of the key.
+// order is 'asc' ( default ) or 'desc'. Equal values are allowed to be adjacent.
+// Numeric strings ( e.g. DECIMAL columns ) are compared as numbers, and nil ( NULL ) is smaller than any other value.
+func IsSorted(rows interface{}, key string, order ...string) bool {
+	ok, err := isSorted(rows, key, order...)
+	if err != nil {
+		panic(err)
+	}
+	return ok
+}
+
+func isSorted(rows interface{}, key string, order ...string) (bool, error) {
+	desc := false
+	if len(order) > 0 {
+		switch strings.ToLower(order[0]) {
+		case "asc":
+		case "desc":
+			desc = true
+		default:
+			return false, fmt.Errorf("isSorted: invalid order: %s", order[0])
+		}
+	}
+	rv := reflect.ValueOf(rows)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return false, fmt.Errorf("isSorted: rows should be a slice: %T", rows)
+	}
+	values := make([]interface{}, rv.Len())
+	for i := 0; i < rv.Len(); i++ {
+		row := reflect.ValueOf(rv.Index(i).Interface())
+		if row.Kind() != reflect.Map || row.Type().Key().Kind() != reflect.String {
+			return false, fmt.Errorf("isSorted: row %d should be a map: %v", i, rv.Index(i).Interface())
+		}
+		v := row.MapIndex(reflect.ValueOf(key).Convert(row.Type().Key()))
+		if !v.IsValid() {
+			return false, fmt.Errorf("isSorted: row %d does not have the key: %s", i, key)
+		}
+		values[i] = v.Interface()
+	}
+	for i := 1; i < len(values); i++ {
+		c, err := compareValues(values[i-1], values[i])
+		if err != nil {
+			return false, fmt.Errorf("isSorted: rows %d and %d: %w", i-1, i, err)
+		}
+		if (!desc && c > 0) || (desc && c < 0) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// compareValues returns -1, 0 or 1 by comparing x and y.
+func compareValues(x, y interface{}) (int, error) {
+	switch {
+	case x == nil && y == nil:
+		return 0, nil
+	case x == nil:
+		return -1, nil
+	case y == nil:
+		return 1, nil
+	}
+	if fx, err := toFloat64(x); err == nil {
+		if fy, err := toFloat64(y); err == nil {
+			switch {
+			case fx < fy:
+				return -1, nil
+			case fx > fy:
+				return 1, nil
+			}
+			return 0, nil
+		}
+	}
+	switch xx := x.(type) {
+	case string:
+		if yy, ok := y.(string); ok {
+			return strings.Compare(xx, yy), nil
+		}
+	case time.Time:
+		if yy, ok := y.(time.Time); ok {
+			return xx.Compare(yy), nil
+		}
+	}
+	return 0, fmt.Errorf("cannot compare %T(%v) and %T(%v)", x, x, y, y)
+}
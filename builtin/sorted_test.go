@@ -0,0 +1,48 @@
+package builtin
+
+import (
+	"testing"
+	"time"
+)
+
+func TestIsSorted(t *testing.T) {
+	t1 := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	t2 := t1.Add(time.Hour)
+	tests := []struct {
+		rows    interface{}
+		key     string
+		order   []string
+		want    bool
+		wantErr bool
+	}{
+		{[]interface{}{}, "id", nil, true, false},
+		{[]interface{}{map[string]interface{}{"id": 1}}, "id", nil, true, false},
+		{[]interface{}{map[string]interface{}{"id": 1}, map[string]interface{}{"id": 2}, map[string]interface{}{"id": 2}}, "id", nil, true, false},
+		{[]interface{}{map[string]interface{}{"id": 2}, map[string]interface{}{"id": 1}}, "id", nil, false, false},
+		{[]interface{}{map[string]interface{}{"id": 2}, map[string]interface{}{"id": 1}}, "id", []string{"desc"}, true, false},
+		{[]interface{}{map[string]interface{}{"id": 1}, map[string]interface{}{"id": 2}}, "id", []string{"DESC"}, false, false},
+		{[]map[string]interface{}{{"id": int64(9)}, {"id": int64(10)}}, "id", []string{"asc"}, true, false},
+		{[]interface{}{map[string]interface{}{"price": "9.50"}, map[string]interface{}{"price": "10.00"}}, "price", nil, true, false},
+		{[]interface{}{map[string]interface{}{"name": "alice"}, map[string]interface{}{"name": "bob"}}, "name", nil, true, false},
+		{[]interface{}{map[string]interface{}{"name": "bob"}, map[string]interface{}{"name": "alice"}}, "name", nil, false, false},
+		{[]interface{}{map[string]interface{}{"created": t1}, map[string]interface{}{"created": t2}}, "created", nil, true, false},
+		{[]interface{}{map[string]interface{}{"created": t2}, map[string]interface{}{"created": t1}}, "created", nil, false, false},
+		{[]interface{}{map[string]interface{}{"id": nil}, map[string]interface{}{"id": 1}}, "id", nil, true, false},
+		{[]interface{}{map[string]interface{}{"id": 1}, map[string]interface{}{"id": nil}}, "id", nil, false, false},
+		{[]interface{}{map[string]interface{}{"id": 1}, map[string]interface{}{"name": "bob"}}, "id", nil, false, true},
+		{[]interface{}{map[string]interface{}{"id": 1}, map[string]interface{}{"id": "bob"}}, "id", nil, false, true},
+		{[]interface{}{map[string]interface{}{"id": 1}}, "id", []string{"random"}, false, true},
+		{[]interface{}{1, 2}, "id", nil, false, true},
+		{map[string]interface{}{"id": 1}, "id", nil, false, true},
+	}
+	for _, tt := range tests {
+		got, err := isSorted(tt.rows, tt.key, tt.order...)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("isSorted(%v, %v, %v): got error %v", tt.rows, tt.key, tt.order, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("isSorted(%v, %v, %v): got %v want %v", tt.rows, tt.key, tt.order, got, tt.want)
+		}
+	}
+}
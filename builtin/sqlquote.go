@@ -0,0 +1,137 @@
+package builtin
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SQLQuote returns the value as a SQL literal that can be embedded in SQL safely.
+// Strings are quoted as SQL string literals, and single quotes in them are escaped by doubling.
+// For the dialects treating backslashes in strings as escape characters ( MySQL and Spanner ), backslashes are also escaped.
+// Without the dialect, strings containing backslashes are rejected because they cannot be quoted safely for all databases.
+// Numbers and booleans are not quoted, nil is NULL, and slices are joined with commas for `IN ( ... )`.
+func SQLQuote(v interface{}, dialect ...string) string {
+	if len(dialect) > 1 {
+		panic(fmt.Errorf("sqlquote: too many arguments: %v", dialect))
+	}
+	var d string
+	if len(dialect) == 1 {
+		d = dialect[0]
+	}
+	s, err := sqlQuote(v, d)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+func sqlQuote(v interface{}, dialect string) (string, error) {
+	switch vv := v.(type) {
+	case nil:
+		return "NULL", nil
+	case string:
+		return quoteSQLString(vv, dialect)
+	case []byte:
+		return quoteSQLString(string(vv), dialect)
+	case bool:
+		if vv {
+			return "TRUE", nil
+		}
+		return "FALSE", nil
+	case int:
+		return strconv.Itoa(vv), nil
+	case int64:
+		return strconv.FormatInt(vv, 10), nil
+	case uint64:
+		return strconv.FormatUint(vv, 10), nil
+	case float64:
+		return strconv.FormatFloat(vv, 'f', -1, 64), nil
+	case json.Number:
+		if _, err := vv.Float64(); err != nil {
+			return "", fmt.Errorf("sqlquote: invalid number: %v", vv)
+		}
+		return vv.String(), nil
+	case time.Time:
+		return quoteSQLString(vv.Format(time.RFC3339Nano), dialect)
+	case []interface{}:
+		if len(vv) == 0 {
+			return "", fmt.Errorf("sqlquote: empty list")
+		}
+		quoted := make([]string, 0, len(vv))
+		for _, vvv := range vv {
+			if _, ok := vvv.([]interface{}); ok {
+				return "", fmt.Errorf("sqlquote: nested list is not supported: %v", vv)
+			}
+			s, err := sqlQuote(vvv, dialect)
+			if err != nil {
+				return "", err
+			}
+			quoted = append(quoted, s)
+		}
+		return strings.Join(quoted, ", "), nil
+	default:
+		return "", fmt.Errorf("sqlquote: unsupported type: %T(%v)", v, v)
+	}
+}
+
+// SQLQuoteIdent returns the identifier ( e.g. table and column names ) quoted for the dialect of the database.
+// The dialect is the name of the database or the scheme of the DSN ( e.g. mysql, postgres, sqlite, sqlserver and spanner ).
+// Dot-separated parts ( e.g. schema.table ) are quoted separately.
+func SQLQuoteIdent(ident, dialect string) string {
+	s, err := sqlQuoteIdent(ident, dialect)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+func sqlQuoteIdent(ident, dialect string) (string, error) {
+	var open, close string
+	switch strings.ToLower(dialect) {
+	case "mysql", "my", "mariadb", "maria", "tidb", "spanner", "sp":
+		open, close = "`", "`"
+	case "postgres", "postgresql", "pg", "pgsql", "pgx", "sqlite", "sqlite3", "sq", "moderncsqlite", "file":
+		open, close = `"`, `"`
+	case "sqlserver", "mssql", "ms", "azuresql":
+		open, close = "[", "]"
+	default:
+		return "", fmt.Errorf("sqlident: unsupported dialect: %s", dialect)
+	}
+	if ident == "" || strings.ContainsRune(ident, 0) {
+		return "", fmt.Errorf("sqlident: invalid identifier: %q", ident)
+	}
+	parts := strings.Split(ident, ".")
+	for i, p := range parts {
+		if p == "" {
+			return "", fmt.Errorf("sqlident: invalid identifier: %q", ident)
+		}
+		parts[i] = open + strings.ReplaceAll(p, close, close+close) + close
+	}
+	return strings.Join(parts, "."), nil
+}
+
+func quoteSQLString(s, dialect string) (string, error) {
+	if strings.ContainsRune(s, 0) {
+		return "", fmt.Errorf("sqlquote: string containing NUL cannot be quoted")
+	}
+	switch strings.ToLower(dialect) {
+	case "":
+		if strings.Contains(s, `\`) {
+			return "", fmt.Errorf("sqlquote: string containing backslash requires the dialect ( e.g. sqlquote(v, 'mysql') ): %q", s)
+		}
+		return "'" + strings.ReplaceAll(s, "'", "''") + "'", nil
+	case "mysql", "my", "mariadb", "maria", "tidb":
+		// backslashes are escape characters unless NO_BACKSLASH_ESCAPES is enabled
+		return "'" + strings.ReplaceAll(strings.ReplaceAll(s, `\`, `\\`), "'", "''") + "'", nil
+	case "spanner", "sp":
+		// GoogleSQL escapes quotes with backslashes
+		return "'" + strings.ReplaceAll(strings.ReplaceAll(s, `\`, `\\`), "'", `\'`) + "'", nil
+	case "postgres", "postgresql", "pg", "pgsql", "pgx", "sqlite", "sqlite3", "sq", "moderncsqlite", "file", "sqlserver", "mssql", "ms", "azuresql":
+		return "'" + strings.ReplaceAll(s, "'", "''") + "'", nil
+	default:
+		return "", fmt.Errorf("sqlquote: unsupported dialect: %s", dialect)
+	}
+}
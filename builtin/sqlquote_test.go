@@ -0,0 +1,88 @@
+package builtin
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestSQLQuote(t *testing.T) {
+	tests := []struct {
+		v       interface{}
+		dialect string
+		want    string
+		wantErr bool
+	}{
+		{"alice", "", "'alice'", false},
+		{"O'Reilly", "", "'O''Reilly'", false},
+		{"'; DROP TABLE users; --", "", "'''; DROP TABLE users; --'", false},
+		{"123", "", "'123'", false},
+		{"", "", "''", false},
+		{[]byte("it's"), "", "'it''s'", false},
+		{uint64(3), "", "3", false},
+		{int64(-3), "", "-3", false},
+		{1.5, "", "1.5", false},
+		{json.Number("10"), "", "10", false},
+		{true, "", "TRUE", false},
+		{nil, "", "NULL", false},
+		{time.Date(2023, 3, 2, 1, 2, 3, 0, time.UTC), "", "'2023-03-02T01:02:03Z'", false},
+		{[]interface{}{uint64(1), "a'b", nil}, "", "1, 'a''b', NULL", false},
+		{[]interface{}{}, "", "", true},
+		{[]interface{}{[]interface{}{1}}, "", "", true},
+		{"a\x00b", "", "", true},
+		{json.Number("x"), "", "", true},
+		{map[string]interface{}{}, "", "", true},
+		{`a\`, "", "", true},
+		{`a\`, "mysql", `'a\\'`, false},
+		{`\'`, "mysql", `'\\'''`, false},
+		{`a\`, "postgres", `'a\'`, false},
+		{`\'`, "sqlite3", `'\'''`, false},
+		{`\'`, "spanner", `'\\\''`, false},
+		{[]interface{}{`a\`}, "mysql", `'a\\'`, false},
+		{"a", "oracle", "", true},
+	}
+	for _, tt := range tests {
+		got, err := sqlQuote(tt.v, tt.dialect)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("sqlquote(%v, %v): got error %v", tt.v, tt.dialect, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("sqlquote(%v, %v): got %v want %v", tt.v, tt.dialect, got, tt.want)
+		}
+	}
+}
+
+func TestSQLQuoteIdent(t *testing.T) {
+	tests := []struct {
+		ident   string
+		dialect string
+		want    string
+		wantErr bool
+	}{
+		{"order", "mysql", "`order`", false},
+		{"User", "postgres", `"User"`, false},
+		{"user", "pg", `"user"`, false},
+		{"user", "sqlite3", `"user"`, false},
+		{"user", "sqlserver", "[user]", false},
+		{"user", "spanner", "`user`", false},
+		{"public.User", "postgres", `"public"."User"`, false},
+		{"we`ird", "mysql", "`we``ird`", false},
+		{`we"ird`, "postgres", `"we""ird"`, false},
+		{"we]ird", "sqlserver", "[we]]ird]", false},
+		{"user", "MySQL", "`user`", false},
+		{"user", "oracle", "", true},
+		{"", "mysql", "", true},
+		{"public.", "postgres", "", true},
+	}
+	for _, tt := range tests {
+		got, err := sqlQuoteIdent(tt.ident, tt.dialect)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("sqlQuoteIdent(%v, %v): got error %v", tt.ident, tt.dialect, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("sqlQuoteIdent(%v, %v): got %v want %v", tt.ident, tt.dialect, got, tt.want)
+		}
+	}
+}
@@ -13,3 +13,11 @@ func Time(v interface{}) time.Time {
 	}
 	return t
 }
+
+func Duration(v interface{}) time.Duration {
+	d, err := time.ParseDuration(v.(string))
+	if err != nil {
+		return 0
+	}
+	return d
+}
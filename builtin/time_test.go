@@ -23,3 +23,20 @@ func TestTime(t *testing.T) {
 		}
 	}
 }
+
+func TestDuration(t *testing.T) {
+	tests := []struct {
+		v    interface{}
+		want time.Duration
+	}{
+		{"720h", 720 * time.Hour},
+		{"1m30s", 90 * time.Second},
+		{"err", 0},
+	}
+	for _, tt := range tests {
+		got := Duration(tt.v)
+		if got != tt.want {
+			t.Errorf("got %v\nwant %v", got, tt.want)
+		}
+	}
+}
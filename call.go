@@ -0,0 +1,202 @@
+package runn
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	callRunnerKey       = "call"
+	functionsRunbookKey = "functions"
+)
+
+// maxCallDepth - maximum depth of nested calls of functions to stop the infinite recursion of `call:`.
+const maxCallDepth = 32
+
+// function - Reusable sequence of steps of `functions:` called by `call:` of steps.
+type function struct {
+	// names of the parameters bound to `vars` of the steps
+	params []string
+	steps  []interface{}
+	// values returned to the caller ( the same as `export:` of runbooks )
+	exports map[string]string
+}
+
+type callRunner struct {
+	operator *operator
+}
+
+type callConfig struct {
+	name string
+	args map[string]interface{}
+	step *step
+}
+
+func newCallRunner(o *operator) (*callRunner, error) {
+	return &callRunner{
+		operator: o,
+	}, nil
+}
+
+// Run runs the steps of the function with the arguments bound to `vars`, and records the values of `export:` of the function.
+func (rnr *callRunner) Run(ctx context.Context, c *callConfig) error {
+	o := rnr.operator
+	if o.thisT != nil {
+		o.thisT.Helper()
+	}
+	fn, ok := o.functions[c.name]
+	if !ok {
+		return fmt.Errorf("function not found: %s", c.name)
+	}
+	stack := append(append([]string{}, o.callStack...), c.name)
+	if len(stack) > maxCallDepth {
+		return fmt.Errorf("too deep calls of functions (max: %d): %s", maxCallDepth, strings.Join(stack, " -> "))
+	}
+	e, err := o.expandBeforeRecord(c.args)
+	if err != nil {
+		return err
+	}
+	args, ok := e.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("invalid args of function '%s': %v", c.name, e)
+	}
+	for k := range args {
+		if !contains(fn.params, k) {
+			return fmt.Errorf("unknown argument of function '%s': %s", c.name, k)
+		}
+	}
+	for _, p := range fn.params {
+		if _, ok := args[p]; !ok {
+			return fmt.Errorf("missing argument of function '%s': %s", c.name, p)
+		}
+	}
+	m := map[string]interface{}{
+		"desc":  fmt.Sprintf("%s (function %s)", o.desc, c.name),
+		"steps": fn.steps,
+	}
+	if len(fn.exports) > 0 {
+		m["export"] = fn.exports
+	}
+	if len(o.templates) > 0 {
+		m["templates"] = o.templates
+	}
+	oo, err := o.newNestedOperator(c.step, BookFromMap(m))
+	if err != nil {
+		return fmt.Errorf("invalid function '%s': %w", c.name, err)
+	}
+	oo.root = o.root
+	oo.functions = o.functions
+	oo.callStack = stack
+	for k, v := range args {
+		oo.store.vars[k] = v
+	}
+	err = oo.run(ctx)
+	rnr.operator.addBytes(oo.bytesSent, oo.bytesReceived)
+	if err != nil {
+		return err
+	}
+	exported := map[string]interface{}{}
+	for k, v := range oo.exported {
+		exported[k] = v
+	}
+	o.record(exported)
+	o.restoreRunners(oo)
+	return nil
+}
+
+// parseFunctions parses `functions:` of the runbook.
+func parseFunctions(in map[string]interface{}) (map[string]*function, error) {
+	if len(in) == 0 {
+		return nil, nil
+	}
+	fns := map[string]*function{}
+	names := make([]string, 0, len(in))
+	for k := range in {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		v, ok := in[name].(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid function '%s': %v", name, in[name])
+		}
+		fn := &function{}
+		for k, vv := range v {
+			switch k {
+			case "params":
+				ps, ok := vv.([]interface{})
+				if !ok {
+					return nil, fmt.Errorf("invalid params of function '%s': %v", name, vv)
+				}
+				for _, p := range ps {
+					s, ok := p.(string)
+					if !ok || s == "" {
+						return nil, fmt.Errorf("invalid params of function '%s': %v", name, vv)
+					}
+					fn.params = append(fn.params, s)
+				}
+			case "steps":
+				fn.steps, ok = vv.([]interface{})
+				if !ok {
+					return nil, fmt.Errorf("invalid steps of function '%s': should be a list of steps: %v", name, vv)
+				}
+			case "export":
+				ex, ok := vv.(map[string]interface{})
+				if !ok {
+					return nil, fmt.Errorf("invalid export of function '%s': %v", name, vv)
+				}
+				fn.exports = map[string]string{}
+				for ek, ev := range ex {
+					s, ok := ev.(string)
+					if !ok {
+						return nil, fmt.Errorf("invalid export of function '%s': %v", name, vv)
+					}
+					fn.exports[ek] = s
+				}
+			default:
+				return nil, fmt.Errorf("invalid function '%s': unknown key '%s'", name, k)
+			}
+		}
+		if len(fn.steps) == 0 {
+			return nil, fmt.Errorf("invalid function '%s': no steps", name)
+		}
+		fns[name] = fn
+	}
+	return fns, nil
+}
+
+// parseCallConfig parses `call:` of the step ( `call: login` is the shorthand of `call: { name: login }` ).
+func parseCallConfig(v interface{}) (*callConfig, error) {
+	c := &callConfig{args: map[string]interface{}{}}
+	switch vv := v.(type) {
+	case string:
+		c.name = vv
+	case map[string]interface{}:
+		for k := range vv {
+			switch k {
+			case "name", "args":
+			default:
+				return nil, fmt.Errorf("invalid call: unknown key '%s': %v", k, v)
+			}
+		}
+		name, ok := vv["name"].(string)
+		if !ok {
+			return nil, fmt.Errorf("invalid call: %v", v)
+		}
+		c.name = name
+		if args, ok := vv["args"]; ok {
+			c.args, ok = args.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("invalid call: args should be a map: %v", v)
+			}
+		}
+	default:
+		return nil, fmt.Errorf("invalid call: %v", v)
+	}
+	if c.name == "" {
+		return nil, fmt.Errorf("invalid call: %v", v)
+	}
+	return c, nil
+}
@@ -0,0 +1,121 @@
+package runn
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestCallRunner(t *testing.T) {
+	o, err := New(Book("testdata/functions.yml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := o.Run(context.Background()); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestCallRunnerRecursion(t *testing.T) {
+	tests := []struct {
+		book    string
+		wantErr string
+	}{
+		{"testdata/functions_recursive.yml", ""},
+		{"testdata/functions_infinite.yml", "too deep calls of functions (max: 32): ping -> pong -> ping"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.book, func(t *testing.T) {
+			o, err := New(Book(tt.book))
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = o.Run(context.Background())
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Error(err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("got %v want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCallRunnerInvalidArgs(t *testing.T) {
+	tests := []struct {
+		call    interface{}
+		wantErr string
+	}{
+		{"greet", "missing argument of function 'greet': name"},
+		{map[string]interface{}{"name": "greet", "args": map[string]interface{}{"name": "alice", "age": 20}}, "unknown argument of function 'greet': age"},
+		{map[string]interface{}{"name": "unknown"}, "function not found: unknown"},
+	}
+	ctx := context.Background()
+	for _, tt := range tests {
+		o, err := New(Book("testdata/functions.yml"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		o.steps = nil
+		if err := o.AppendStep("0", map[string]interface{}{"call": tt.call}); err != nil {
+			t.Fatal(err)
+		}
+		err = o.Run(ctx)
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("got %v want %q", err, tt.wantErr)
+		}
+	}
+}
+
+func TestParseFunctions(t *testing.T) {
+	tests := []struct {
+		in      map[string]interface{}
+		wantErr bool
+	}{
+		{map[string]interface{}{"f": map[string]interface{}{"steps": []interface{}{map[string]interface{}{"test": true}}}}, false},
+		{map[string]interface{}{"f": map[string]interface{}{"params": []interface{}{"a"}, "steps": []interface{}{map[string]interface{}{"test": true}}, "export": map[string]interface{}{"b": "vars.a"}}}, false},
+		{map[string]interface{}{"f": map[string]interface{}{}}, true},
+		{map[string]interface{}{"f": map[string]interface{}{"steps": map[string]interface{}{"0": map[string]interface{}{"test": true}}}}, true},
+		{map[string]interface{}{"f": map[string]interface{}{"params": []interface{}{1}, "steps": []interface{}{map[string]interface{}{"test": true}}}}, true},
+		{map[string]interface{}{"f": map[string]interface{}{"unknown": true, "steps": []interface{}{map[string]interface{}{"test": true}}}}, true},
+		{map[string]interface{}{"f": "steps"}, true},
+	}
+	for _, tt := range tests {
+		_, err := parseFunctions(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("got %v\nwantErr %v", err, tt.wantErr)
+		}
+	}
+}
+
+func TestParseCallConfig(t *testing.T) {
+	tests := []struct {
+		in      interface{}
+		want    string
+		wantErr bool
+	}{
+		{"login", "login", false},
+		{map[string]interface{}{"name": "login", "args": map[string]interface{}{"user": "alice"}}, "login", false},
+		{map[string]interface{}{"name": "login", "args": "alice"}, "", true},
+		{map[string]interface{}{"name": "login", "vars": map[string]interface{}{}}, "", true},
+		{map[string]interface{}{"args": map[string]interface{}{}}, "", true},
+		{"", "", true},
+		{1, "", true},
+	}
+	for _, tt := range tests {
+		got, err := parseCallConfig(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("got %v\nwantErr %v", err, tt.wantErr)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if got.name != tt.want {
+			t.Errorf("got %v\nwant %v", got.name, tt.want)
+		}
+	}
+}
@@ -399,6 +399,34 @@ func (c *cRunbook) CaptureExecCommand(command string) {
 	r.Steps = append(r.Steps, step)
 }
 
+func (c *cRunbook) CaptureExecEnv(env map[string]string) {
+	if len(env) == 0 {
+		return
+	}
+	r := c.currentRunbook()
+	if r == nil {
+		return
+	}
+	step := r.latestStep()
+	exec, ok := step[0].Value.(yaml.MapSlice)
+	if !ok {
+		c.errs = multierr.Append(c.errs, fmt.Errorf("failed to get step[0].Value: %s", step[0].Value))
+		return
+	}
+	keys := make([]string, 0, len(env))
+	for k := range env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	e := yaml.MapSlice{}
+	for _, k := range keys {
+		e = append(e, yaml.MapItem{Key: k, Value: env[k]})
+	}
+	exec = append(exec, yaml.MapItem{Key: "env", Value: e})
+	step[0].Value = exec
+	r.replaceLatestStep(step)
+}
+
 func (c *cRunbook) CaptureExecStdin(stdin string) {
 	if stdin == "" {
 		return
@@ -22,6 +22,7 @@ func TestRunbook(t *testing.T) {
 		{filepath.Join(testutil.Testdata(), "book", "grpc.yml")},
 		{filepath.Join(testutil.Testdata(), "book", "db.yml")},
 		{filepath.Join(testutil.Testdata(), "book", "exec.yml")},
+		{filepath.Join(testutil.Testdata(), "book", "exec_env.yml")},
 		{filepath.Join(testutil.Testdata(), "book", "include_main.yml")},
 	}
 	ctx := context.Background()
@@ -70,6 +71,7 @@ func TestRunnable(t *testing.T) {
 		{filepath.Join(testutil.Testdata(), "book", "grpc.yml")},
 		{filepath.Join(testutil.Testdata(), "book", "db.yml")},
 		{filepath.Join(testutil.Testdata(), "book", "exec.yml")},
+		{filepath.Join(testutil.Testdata(), "book", "exec_env.yml")},
 	}
 	ctx := context.Background()
 	for _, tt := range tests {
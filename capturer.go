@@ -37,6 +37,7 @@ type Capturer interface {
 	CaptureDBResponse(name string, res *DBResponse)
 
 	CaptureExecCommand(command string)
+	CaptureExecEnv(env map[string]string)
 	CaptureExecStdin(stdin string)
 	CaptureExecStdout(stdout string)
 	CaptureExecStderr(stderr string)
@@ -190,6 +191,12 @@ func (cs capturers) captureExecCommand(command string) {
 	}
 }
 
+func (cs capturers) captureExecEnv(env map[string]string) {
+	for _, c := range cs {
+		c.CaptureExecEnv(env)
+	}
+}
+
 func (cs capturers) captureExecStdin(stdin string) {
 	for _, c := range cs {
 		c.CaptureExecStdin(stdin)
@@ -0,0 +1,98 @@
+package runn
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// CheckpointVersion is the version of the format of the checkpoint file.
+const CheckpointVersion = 1
+
+// CheckpointFile - Progress of RunN written to the checkpoint file ( JSON ) by Checkpoint and read by ResumeFrom.
+type CheckpointFile struct {
+	// Version is the version of the format ( CheckpointVersion )
+	Version int `json:"version"`
+	// Passed are the runbooks that passed, in the order in which they finished.
+	// A runbook is identified by its path ( with the combination of `matrix:` such as "path/to/book.yml (env=dev)" ).
+	Passed []string `json:"passed"`
+}
+
+// checkpoint - Progress of RunN.
+type checkpoint struct {
+	path   string
+	passed []string
+	mu     sync.Mutex
+}
+
+// readCheckpointFile reads the checkpoint file. If the file does not exist, it returns the empty progress.
+func readCheckpointFile(p string) (*CheckpointFile, error) {
+	b, err := os.ReadFile(p)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return &CheckpointFile{Version: CheckpointVersion}, nil
+		}
+		return nil, err
+	}
+	cf := &CheckpointFile{}
+	if err := json.Unmarshal(b, cf); err != nil {
+		return nil, fmt.Errorf("invalid checkpoint file %s: %w", p, err)
+	}
+	if cf.Version != CheckpointVersion {
+		return nil, fmt.Errorf("unsupported version of checkpoint file %s: %d", p, cf.Version)
+	}
+	return cf, nil
+}
+
+// pass records that the runbook passed and writes the progress to the checkpoint file.
+func (c *checkpoint) pass(key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !contains(c.passed, key) {
+		c.passed = append(c.passed, key)
+	}
+	if c.path == "" {
+		return nil
+	}
+	b, err := json.MarshalIndent(&CheckpointFile{Version: CheckpointVersion, Passed: c.passed}, "", "  ")
+	if err != nil {
+		return err
+	}
+	// write to the temporary file and rename it so that the checkpoint file is not broken when interrupted
+	tmp, err := os.CreateTemp(filepath.Dir(c.path), filepath.Base(c.path)+".*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(append(b, '\n')); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), c.path)
+}
+
+// hasPassed returns whether the runbook passed.
+func (c *checkpoint) hasPassed(key string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return contains(c.passed, key)
+}
+
+// checkpointKey returns the key of the runbook in the checkpoint file.
+func (o *operator) checkpointKey() string {
+	key := o.bookPath
+	if o.matrixVars != nil {
+		key = fmt.Sprintf("%s (%s)", key, matrixName(o.matrixVars))
+	}
+	if o.datasetName != "" {
+		key = fmt.Sprintf("%s (%s)", key, o.datasetName)
+	}
+	return key
+}
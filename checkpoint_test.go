@@ -0,0 +1,94 @@
+package runn
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCheckpoint(t *testing.T) {
+	ctx := context.Background()
+	p := filepath.Join(t.TempDir(), "checkpoint.json")
+	wantPassed := []string{"testdata/book/runn_0_success.yml", "testdata/book/runn_2_success.yml"}
+
+	ops, err := Load("testdata/book/runn_*", Checkpoint(p), ResumeFrom(p))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ops.RunN(ctx); err != nil {
+		t.Fatal(err)
+	}
+	cf, err := readCheckpointFile(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(cf.Passed, wantPassed); diff != "" {
+		t.Error(diff)
+	}
+
+	// resume
+	ops, err = Load("testdata/book/runn_*", Checkpoint(p), ResumeFrom(p))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ops.RunN(ctx); err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]bool{}
+	for _, r := range ops.Result().RunResults {
+		got[r.Path] = r.Skipped
+	}
+	want := map[string]bool{
+		"testdata/book/runn_0_success.yml": true,
+		"testdata/book/runn_1_fail.yml":    false,
+		"testdata/book/runn_2_success.yml": true,
+		"testdata/book/runn_3.skip.yml":    true,
+	}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Error(diff)
+	}
+	cf, err = readCheckpointFile(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(cf.Passed, wantPassed); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestReadCheckpointFile(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		content string
+		want    []string
+		wantErr bool
+	}{
+		{"", nil, false},
+		{`{"version": 1, "passed": ["a.yml", "b.yml (env=dev)"]}`, []string{"a.yml", "b.yml (env=dev)"}, false},
+		{`{"version": 2, "passed": ["a.yml"]}`, nil, true},
+		{`passed`, nil, true},
+	}
+	for i, tt := range tests {
+		p := filepath.Join(dir, "checkpoint.json")
+		_ = os.Remove(p)
+		if tt.content != "" {
+			if err := os.WriteFile(p, []byte(tt.content), os.ModePerm); err != nil {
+				t.Fatal(err)
+			}
+		}
+		got, err := readCheckpointFile(p)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("[%d] got error %v", i, err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if diff := cmp.Diff(got.Passed, tt.want); diff != "" {
+			t.Errorf("[%d] %s", i, diff)
+		}
+	}
+}
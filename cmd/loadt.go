@@ -102,17 +102,25 @@ var loadtCmd = &cobra.Command{
 func init() {
 	rootCmd.AddCommand(loadtCmd)
 	loadtCmd.Flags().BoolVarP(&flgs.Debug, "debug", "", false, flgs.Usage("Debug"))
+	loadtCmd.Flags().BoolVarP(&flgs.Trace, "trace", "", false, flgs.Usage("Trace"))
 	loadtCmd.Flags().BoolVarP(&flgs.FailFast, "fail-fast", "", false, flgs.Usage("FailFast"))
 	loadtCmd.Flags().BoolVarP(&flgs.SkipTest, "skip-test", "", false, flgs.Usage("SkipTest"))
 	loadtCmd.Flags().BoolVarP(&flgs.SkipIncluded, "skip-included", "", false, flgs.Usage("SkipIncluded"))
 	loadtCmd.Flags().BoolVarP(&flgs.GRPCNoTLS, "grpc-no-tls", "", false, flgs.Usage("GRPCNoTLS"))
+	loadtCmd.Flags().BoolVarP(&flgs.StrictVars, "strict-vars", "", false, flgs.Usage("StrictVars"))
+	loadtCmd.Flags().BoolVarP(&flgs.StrictKeys, "strict-keys", "", false, flgs.Usage("StrictKeys"))
+	loadtCmd.Flags().BoolVarP(&flgs.CollectExprErrors, "collect-expr-errors", "", false, flgs.Usage("CollectExprErrors"))
+	loadtCmd.Flags().BoolVarP(&flgs.NamespaceBinds, "namespace-binds", "", false, flgs.Usage("NamespaceBinds"))
+	loadtCmd.Flags().BoolVarP(&flgs.UseJSONNumber, "use-json-number", "", false, flgs.Usage("UseJSONNumber"))
 	loadtCmd.Flags().StringVarP(&flgs.CaptureDir, "capture", "", "", flgs.Usage("CaptureDir"))
 	loadtCmd.Flags().StringSliceVarP(&flgs.Vars, "var", "", []string{}, flgs.Usage("Vars"))
 	loadtCmd.Flags().StringSliceVarP(&flgs.Runners, "runner", "", []string{}, flgs.Usage("Runners"))
 	loadtCmd.Flags().StringSliceVarP(&flgs.Overlays, "overlay", "", []string{}, flgs.Usage("Overlays"))
 	loadtCmd.Flags().StringSliceVarP(&flgs.Underlays, "underlay", "", []string{}, flgs.Usage("Underlays"))
 	loadtCmd.Flags().IntVarP(&flgs.Sample, "sample", "", 0, flgs.Usage("Sample"))
+	loadtCmd.Flags().StringVarP(&flgs.SampleSeed, "sample-seed", "", "", flgs.Usage("SampleSeed"))
 	loadtCmd.Flags().StringVarP(&flgs.Shuffle, "shuffle", "", "off", flgs.Usage("Shuffle"))
+	loadtCmd.Flags().StringVarP(&flgs.ShuffleSteps, "shuffle-steps", "", "off", flgs.Usage("ShuffleSteps"))
 	loadtCmd.Flags().StringVarP(&flgs.Concurrent, "concurrent", "", "off", flgs.Usage("Concurrent"))
 	loadtCmd.Flags().IntVarP(&flgs.Random, "random", "", 0, flgs.Usage("Random"))
 	loadtCmd.Flags().IntVarP(&flgs.ShardIndex, "shard-index", "", 0, flgs.Usage("ShardIndex"))
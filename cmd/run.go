@@ -45,7 +45,7 @@ var runCmd = &cobra.Command{
 		if err != nil {
 			return err
 		}
-		if flgs.Format == "" {
+		if flgs.Format == "" && flgs.Template == "" {
 			opts = append(opts, runn.Capture(runn.NewCmdOut(os.Stdout, flgs.Verbose)))
 		}
 
@@ -67,8 +67,12 @@ var runCmd = &cobra.Command{
 			return err
 		}
 		r := o.Result()
-		switch flgs.Format {
-		case "json":
+		switch {
+		case flgs.Template != "":
+			if err := r.OutTemplate(os.Stdout, flgs.Template); err != nil {
+				return err
+			}
+		case flgs.Format == "json":
 			if err := r.OutJSON(os.Stdout); err != nil {
 				return err
 			}
@@ -104,22 +108,41 @@ var runCmd = &cobra.Command{
 func init() {
 	rootCmd.AddCommand(runCmd)
 	runCmd.Flags().BoolVarP(&flgs.Debug, "debug", "", false, flgs.Usage("Debug"))
+	runCmd.Flags().BoolVarP(&flgs.Trace, "trace", "", false, flgs.Usage("Trace"))
 	runCmd.Flags().BoolVarP(&flgs.FailFast, "fail-fast", "", false, flgs.Usage("FailFast"))
 	runCmd.Flags().BoolVarP(&flgs.SkipTest, "skip-test", "", false, flgs.Usage("SkipTest"))
 	runCmd.Flags().BoolVarP(&flgs.SkipIncluded, "skip-included", "", false, flgs.Usage("SkipIncluded"))
 	runCmd.Flags().BoolVarP(&flgs.GRPCNoTLS, "grpc-no-tls", "", false, flgs.Usage("GRPCNoTLS"))
+	runCmd.Flags().BoolVarP(&flgs.StrictVars, "strict-vars", "", false, flgs.Usage("StrictVars"))
+	runCmd.Flags().BoolVarP(&flgs.StrictKeys, "strict-keys", "", false, flgs.Usage("StrictKeys"))
+	runCmd.Flags().BoolVarP(&flgs.CollectExprErrors, "collect-expr-errors", "", false, flgs.Usage("CollectExprErrors"))
+	runCmd.Flags().BoolVarP(&flgs.NamespaceBinds, "namespace-binds", "", false, flgs.Usage("NamespaceBinds"))
+	runCmd.Flags().BoolVarP(&flgs.UseJSONNumber, "use-json-number", "", false, flgs.Usage("UseJSONNumber"))
 	runCmd.Flags().StringVarP(&flgs.CaptureDir, "capture", "", "", flgs.Usage("CaptureDir"))
+	runCmd.Flags().StringVarP(&flgs.Checkpoint, "checkpoint", "", "", flgs.Usage("Checkpoint"))
+	runCmd.Flags().StringVarP(&flgs.Dataset, "dataset", "", "", flgs.Usage("Dataset"))
+	runCmd.Flags().StringVarP(&flgs.DatasetKey, "dataset-key", "", "", flgs.Usage("DatasetKey"))
+	runCmd.Flags().StringVarP(&flgs.ResumeFrom, "resume-from", "", "", flgs.Usage("ResumeFrom"))
+	runCmd.Flags().StringVarP(&flgs.Baseline, "baseline", "", "", flgs.Usage("Baseline"))
+	runCmd.Flags().BoolVarP(&flgs.UpdateBaseline, "update-baseline", "", false, flgs.Usage("UpdateBaseline"))
+	runCmd.Flags().Float64VarP(&flgs.BaselineTolerance, "baseline-tolerance", "", 0.2, flgs.Usage("BaselineTolerance"))
+	runCmd.Flags().StringVarP(&flgs.TraceFile, "trace-file", "", "", flgs.Usage("TraceFile"))
+	runCmd.Flags().StringVarP(&flgs.CorrelationID, "correlation-id-header", "", "", flgs.Usage("CorrelationID"))
 	runCmd.Flags().StringSliceVarP(&flgs.Vars, "var", "", []string{}, flgs.Usage("Vars"))
 	runCmd.Flags().StringSliceVarP(&flgs.Runners, "runner", "", []string{}, flgs.Usage("Runners"))
 	runCmd.Flags().StringSliceVarP(&flgs.Overlays, "overlay", "", []string{}, flgs.Usage("Overlays"))
 	runCmd.Flags().StringSliceVarP(&flgs.Underlays, "underlay", "", []string{}, flgs.Usage("Underlays"))
 	runCmd.Flags().IntVarP(&flgs.Sample, "sample", "", 0, flgs.Usage("Sample"))
+	runCmd.Flags().StringVarP(&flgs.SampleSeed, "sample-seed", "", "", flgs.Usage("SampleSeed"))
 	runCmd.Flags().StringVarP(&flgs.Shuffle, "shuffle", "", "off", flgs.Usage("Shuffle"))
+	runCmd.Flags().StringVarP(&flgs.ShuffleSteps, "shuffle-steps", "", "off", flgs.Usage("ShuffleSteps"))
 	runCmd.Flags().StringVarP(&flgs.Concurrent, "concurrent", "", "off", flgs.Usage("Concurrent"))
 	runCmd.Flags().IntVarP(&flgs.ShardIndex, "shard-index", "", 0, flgs.Usage("ShardIndex"))
 	runCmd.Flags().IntVarP(&flgs.ShardN, "shard-n", "", 0, flgs.Usage("ShardN"))
 	runCmd.Flags().IntVarP(&flgs.Random, "random", "", 0, flgs.Usage("Random"))
 	runCmd.Flags().StringVarP(&flgs.Format, "format", "", "", flgs.Usage("Format"))
+	runCmd.Flags().BoolVarP(&flgs.IncludeStore, "include-store", "", false, flgs.Usage("IncludeStore"))
+	runCmd.Flags().StringVarP(&flgs.Template, "template", "", "", flgs.Usage("Template"))
 	runCmd.Flags().BoolVarP(&flgs.Profile, "profile", "", false, flgs.Usage("Profile"))
 	runCmd.Flags().StringVarP(&flgs.ProfileOut, "profile-out", "", "runn.prof", flgs.Usage("ProfileOut"))
 	runCmd.Flags().StringVarP(&flgs.CacheDir, "cache-dir", "", "", flgs.Usage("CacheDir"))
@@ -94,6 +94,7 @@ func (d *cmdOut) CaptureSSHStderr(stderr string)
 func (d *cmdOut) CaptureDBStatement(name string, stmt string)                        {}
 func (d *cmdOut) CaptureDBResponse(name string, res *DBResponse)                     {}
 func (d *cmdOut) CaptureExecCommand(command string)                                  {}
+func (d *cmdOut) CaptureExecEnv(env map[string]string)                               {}
 func (d *cmdOut) CaptureExecStdin(stdin string)                                      {}
 func (d *cmdOut) CaptureExecStdout(stdout string)                                    {}
 func (d *cmdOut) CaptureExecStderr(stderr string)                                    {}
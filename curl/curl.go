@@ -0,0 +1,79 @@
+// Package curl provides functions to convert curl commands into HTTP steps of runn.
+package curl
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/k1LoW/curlreq"
+	"github.com/k1LoW/runn"
+	"gopkg.in/yaml.v2"
+)
+
+const stepKey = "req"
+
+// FromCurl - convert curl command into the HTTP request of the step ( `{path: {method: {headers, body}}}` ).
+func FromCurl(cmd string) (map[string]interface{}, error) {
+	req, err := curlreq.NewRequest(cmd)
+	if err != nil {
+		return nil, err
+	}
+	if req.ContentLength > 0 && req.Header.Get("Content-Type") == "" {
+		// same as the default Content-Type of `curl -d`
+		req.Header.Set("Content-Type", runn.MediaTypeApplicationFormUrlencoded)
+	}
+	ms, err := runn.CreateHTTPStepMapSlice(stepKey, req)
+	if err != nil {
+		return nil, err
+	}
+	m, ok := normalize(ms).(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("invalid step: %v", ms)
+	}
+	hr, ok := m[stepKey].(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("invalid step: %v", ms)
+	}
+	return hr, nil
+}
+
+// Endpoint - return the endpoint ( scheme and host ) of the curl command for the HTTP runner.
+func Endpoint(cmd string) (string, error) {
+	req, err := curlreq.NewRequest(cmd)
+	if err != nil {
+		return "", err
+	}
+	splitted := strings.Split(req.URL.String(), req.URL.Host)
+	return fmt.Sprintf("%s%s", splitted[0], req.URL.Host), nil
+}
+
+func normalize(v interface{}) interface{} {
+	switch vv := v.(type) {
+	case yaml.MapSlice:
+		m := map[string]interface{}{}
+		for _, i := range vv {
+			m[fmt.Sprintf("%v", i.Key)] = normalize(i.Value)
+		}
+		return m
+	case map[string]string:
+		m := map[string]interface{}{}
+		for k, vvv := range vv {
+			m[k] = vvv
+		}
+		return m
+	case map[string]interface{}:
+		m := map[string]interface{}{}
+		for k, vvv := range vv {
+			m[k] = normalize(vvv)
+		}
+		return m
+	case []interface{}:
+		s := make([]interface{}, len(vv))
+		for i, vvv := range vv {
+			s[i] = normalize(vvv)
+		}
+		return s
+	default:
+		return v
+	}
+}
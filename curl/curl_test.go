@@ -0,0 +1,95 @@
+package curl
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestFromCurl(t *testing.T) {
+	tests := []struct {
+		cmd  string
+		want map[string]interface{}
+	}{
+		{
+			`curl https://example.com/users?page=1`,
+			map[string]interface{}{
+				"/users?page=1": map[string]interface{}{
+					"get": map[string]interface{}{
+						"body": nil,
+					},
+				},
+			},
+		},
+		{
+			`curl https://example.com/users -H 'Authorization: Bearer xxxxx'`,
+			map[string]interface{}{
+				"/users": map[string]interface{}{
+					"get": map[string]interface{}{
+						"headers": map[string]interface{}{
+							"Authorization": "Bearer xxxxx",
+						},
+						"body": nil,
+					},
+				},
+			},
+		},
+		{
+			`curl -X POST https://example.com/users -H 'Content-Type: application/json' -d '{"name":"alice"}'`,
+			map[string]interface{}{
+				"/users": map[string]interface{}{
+					"post": map[string]interface{}{
+						"body": map[string]interface{}{
+							"application/json": map[string]interface{}{
+								"name": "alice",
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			`curl -d 'name=alice&age=3' http://localhost:8080/form`,
+			map[string]interface{}{
+				"/form": map[string]interface{}{
+					"post": map[string]interface{}{
+						"body": map[string]interface{}{
+							"application/x-www-form-urlencoded": map[string]interface{}{
+								"name": "alice",
+								"age":  "3",
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		got, err := FromCurl(tt.cmd)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(got, tt.want, nil); diff != "" {
+			t.Errorf("%s", diff)
+		}
+	}
+}
+
+func TestEndpoint(t *testing.T) {
+	tests := []struct {
+		cmd  string
+		want string
+	}{
+		{`curl https://example.com/users?page=1`, "https://example.com"},
+		{`curl -d 'name=alice' http://localhost:8080/form`, "http://localhost:8080"},
+	}
+	for _, tt := range tests {
+		got, err := Endpoint(tt.cmd)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("got %v\nwant %v", got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package runn
+
+import (
+	"context"
+	"fmt"
+)
+
+// CustomRunner - Runner for protocols not built into runn ( e.g. MQTT, SMTP ).
+// Run receives the expanded value of the step section of the runner, and the returned values are recorded as the result of the step.
+type CustomRunner interface {
+	Run(ctx context.Context, req map[string]interface{}) (map[string]interface{}, error)
+}
+
+type customRunner struct {
+	name     string
+	runner   CustomRunner
+	operator *operator
+}
+
+func newCustomRunner(name string, r CustomRunner) (*customRunner, error) {
+	if r == nil {
+		return nil, fmt.Errorf("custom runner is nil: %s", name)
+	}
+	return &customRunner{
+		name:   name,
+		runner: r,
+	}, nil
+}
+
+func (rnr *customRunner) Run(ctx context.Context, req map[string]interface{}) error {
+	res, err := rnr.runner.Run(ctx, req)
+	if err != nil {
+		return err
+	}
+	rnr.operator.record(res)
+	return nil
+}
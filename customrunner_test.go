@@ -0,0 +1,69 @@
+package runn
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+)
+
+type echoRunner struct {
+	name string
+}
+
+func (r *echoRunner) Run(ctx context.Context, req map[string]interface{}) (map[string]interface{}, error) {
+	if _, ok := req["fail"]; ok {
+		return nil, errors.New("failed")
+	}
+	res := map[string]interface{}{
+		"runner": r.name,
+	}
+	for k, v := range req {
+		res[k] = v
+	}
+	return res, nil
+}
+
+func TestCustomRunner(t *testing.T) {
+	ctx := context.Background()
+	o, err := New(Book("testdata/custom_runner.yml"), RegisterRunner("echo", &echoRunner{name: "echo"}), Stdout(io.Discard))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := o.Run(ctx); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCustomRunnerError(t *testing.T) {
+	tests := []struct {
+		name    string
+		runner  CustomRunner
+		wantErr bool
+	}{
+		{"echo", &echoRunner{}, false},
+		{"echo", nil, true},
+		{"test", &echoRunner{}, true},
+		{"include", &echoRunner{}, true},
+	}
+	for _, tt := range tests {
+		_, err := New(RegisterRunner(tt.name, tt.runner))
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: got %v want error %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestCustomRunnerFailure(t *testing.T) {
+	ctx := context.Background()
+	o, err := New(RegisterRunner("echo", &echoRunner{}), Stdout(io.Discard))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := o.AppendStep("0", map[string]interface{}{"echo": map[string]interface{}{"fail": true}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := o.Run(ctx); err == nil {
+		t.Error("want error")
+	}
+}
@@ -0,0 +1,130 @@
+package runn
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/goccy/go-json"
+)
+
+// LoadWithDataset loads runbooks like Load, and runs each runbook once per record of the dataset ( CSV or JSON ).
+// Each record is merged into `vars:`, and the run of each record is a RunResult named by the column set by DatasetKey.
+func LoadWithDataset(pathp, datasetPath string, opts ...Option) (*operators, error) {
+	return Load(pathp, append(opts, Dataset(datasetPath))...)
+}
+
+// expandDataset returns the operators for each record of the dataset per operator.
+func expandDataset(ops []*operator, datasetPath, key string, opts []Option) ([]*operator, error) {
+	records, err := readDataset(datasetPath)
+	if err != nil {
+		return nil, err
+	}
+	names, err := datasetNames(records, key)
+	if err != nil {
+		return nil, err
+	}
+	var dops []*operator
+	for _, o := range ops {
+		for i, r := range records {
+			do, err := newDatasetOperator(o, r, names[i], opts)
+			if err != nil {
+				return nil, err
+			}
+			do.sw = o.sw
+			dops = append(dops, do)
+		}
+	}
+	return dops, nil
+}
+
+// newDatasetOperator returns a new operator for the record of the dataset.
+// The record is merged into `vars:` after the combination of matrix of the operator.
+func newDatasetOperator(o *operator, r map[string]interface{}, name string, opts []Option) (*operator, error) {
+	dopts := []Option{Book(o.bookPath)}
+	for _, vars := range []map[string]interface{}{o.matrixVars, r} {
+		keys := make([]string, 0, len(vars))
+		for k := range vars {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			dopts = append(dopts, Var(k, vars[k]))
+		}
+	}
+	do, err := New(append(dopts, opts...)...)
+	if err != nil {
+		return nil, err
+	}
+	if o.matrixVars != nil {
+		do.matrixVars = o.matrixVars
+		do.desc = fmt.Sprintf("%s (%s)", do.desc, matrixName(o.matrixVars))
+	}
+	do.datasetVars = r
+	do.datasetName = name
+	do.desc = fmt.Sprintf("%s (%s)", do.desc, name)
+	do.runResult = newRunResult(do.desc, do.bookPathOrID())
+	return do, nil
+}
+
+// readDataset reads the records of the dataset.
+// The CSV file has a header row of the column names, and the values are strings. The JSON file is an array of objects.
+func readDataset(p string) ([]map[string]interface{}, error) {
+	b, err := readFile(p)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read dataset: %w", err)
+	}
+	var records []map[string]interface{}
+	switch strings.ToLower(filepath.Ext(p)) {
+	case ".csv":
+		rows, err := csv.NewReader(bytes.NewReader(b)).ReadAll()
+		if err != nil {
+			return nil, fmt.Errorf("invalid dataset %s: %w", p, err)
+		}
+		if len(rows) == 0 {
+			return nil, fmt.Errorf("invalid dataset %s: no header row", p)
+		}
+		header := rows[0]
+		for _, row := range rows[1:] {
+			r := map[string]interface{}{}
+			for i, c := range header {
+				r[c] = row[i]
+			}
+			records = append(records, r)
+		}
+	case ".json":
+		if err := json.Unmarshal(b, &records); err != nil {
+			return nil, fmt.Errorf("invalid dataset %s: should be an array of objects: %w", p, err)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported dataset format: %s", p)
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("invalid dataset %s: no records", p)
+	}
+	return records, nil
+}
+
+// datasetNames returns the names of the records ( e.g. `user=alice` or `#0` ).
+func datasetNames(records []map[string]interface{}, key string) ([]string, error) {
+	names := make([]string, 0, len(records))
+	for i, r := range records {
+		if key == "" {
+			names = append(names, fmt.Sprintf("#%d", i))
+			continue
+		}
+		v, ok := r[key]
+		if !ok || v == nil {
+			return nil, fmt.Errorf("dataset key %s is not found in the record #%d", key, i)
+		}
+		name := fmt.Sprintf("%s=%v", key, v)
+		if contains(names, name) {
+			return nil, fmt.Errorf("duplicate dataset key: %s", name)
+		}
+		names = append(names, name)
+	}
+	return names, nil
+}
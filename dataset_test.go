@@ -0,0 +1,69 @@
+package runn
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestLoadWithDataset(t *testing.T) {
+	tests := []struct {
+		dataset   string
+		key       string
+		wantDescs []string
+		wantErr   string
+	}{
+		{
+			"testdata/dataset/users.csv",
+			"name",
+			[]string{"Run per record of dataset (name=alice)", "Run per record of dataset (name=bob)"},
+			"",
+		},
+		{
+			"testdata/dataset/users.json",
+			"id",
+			[]string{"Run per record of dataset (id=1)", "Run per record of dataset (id=2)", "Run per record of dataset (id=3)"},
+			"",
+		},
+		{
+			"testdata/dataset/users.json",
+			"",
+			[]string{"Run per record of dataset (#0)", "Run per record of dataset (#1)", "Run per record of dataset (#2)"},
+			"",
+		},
+		{"testdata/dataset/users.csv", "email", nil, "dataset key email is not found"},
+		{"testdata/dataset/duplicate.csv", "name", nil, "duplicate dataset key: name=alice"},
+		{"testdata/dataset.yml", "", nil, "unsupported dataset format"},
+	}
+	ctx := context.Background()
+	for _, tt := range tests {
+		t.Run(tt.dataset+" "+tt.key, func(t *testing.T) {
+			ops, err := LoadWithDataset("testdata/dataset.yml", tt.dataset, DatasetKey(tt.key))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("got %v\nwant %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := ops.RunN(ctx); err != nil {
+				t.Fatal(err)
+			}
+			r := ops.Result()
+			if r.HasFailure() {
+				t.Error("want no failure")
+			}
+			got := []string{}
+			for _, rr := range r.RunResults {
+				got = append(got, rr.Desc)
+			}
+			if diff := cmp.Diff(got, tt.wantDescs, nil); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
@@ -3,16 +3,23 @@ package runn
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"net/url"
 	"reflect"
+	"regexp"
 	"strconv"
 	"strings"
+	"sync/atomic"
+	"time"
 	"unsafe"
 
 	"github.com/araddon/dateparse"
 	"github.com/golang-sql/sqlexp"
 	"github.com/golang-sql/sqlexp/nest"
 	_ "github.com/googleapis/go-sql-spanner"
+	"github.com/k1LoW/runn/builtin"
+	"github.com/lib/pq"
 	"github.com/xo/dburl"
 	"modernc.org/sqlite"
 )
@@ -21,6 +28,31 @@ const (
 	dbStoreLastInsertIDKey = "last_insert_id"
 	dbStoreRowsAffectedKey = "rows_affected"
 	dbStoreRowsKey         = "rows"
+	dbStoreResultSetsKey   = "resultSets"
+	dbStoreNotificationKey = "notification"
+	dbStoreQueryCountKey   = "query_count"
+	dbStoreTruncatedKey    = "truncated"
+	dbStoreByKeyKey        = "byKey"
+	dbStoreColumnsKey      = "columns"
+)
+
+// sqlIdentFuncName - name of the built-in function to quote identifiers for the dialect of the DB runner.
+const sqlIdentFuncName = "sqlident"
+
+// sqlQuoteFuncName - name of the built-in function to quote values for the dialect of the DB runner.
+const sqlQuoteFuncName = "sqlquote"
+
+const (
+	defaultDBListenTimeout = 30 * time.Second
+	// default maximum number of rows to scan per result set to prevent accidental OOM
+	defaultDBMaxRows = 100000
+)
+
+const (
+	// dbNumericAsFloat - numeric columns are float64 regardless of the driver ( `numericAs: float` )
+	dbNumericAsFloat = "float"
+	// dbNumericAsString - numeric columns are strings regardless of the driver ( `numericAs: string` )
+	dbNumericAsString = "string"
 )
 
 type Querier interface {
@@ -33,13 +65,57 @@ type TxQuerier interface {
 }
 
 type dbRunner struct {
-	name     string
-	client   TxQuerier
-	operator *operator
+	name   string
+	client TxQuerier
+	pgDSN  string // DSN for LISTEN of Postgres
+	// dialect of the database to quote identifiers ( the driver name resolved from the DSN )
+	dialect string
+	// Go time layouts tried in order to parse DATE/TIMESTAMP/DATETIME columns before falling back to dateparse
+	timeLayouts []string
+	// representation of numeric columns ( `numericAs:` empty: as returned by the driver )
+	numericAs string
+	operator  *operator
+	// replicas to route the read-only transactions to in round-robin ( `replicas:` )
+	replicas []*dbReplica
+	// DSN of the primary ( password masked ) to show which DSN served the statements when the runner has replicas
+	primaryDSN string
+	// index of the next replica to route the read-only transaction to
+	nextReplica uint32
+	// DB runners to route the statements to by the statement type ( set only for the DB runner group )
+	group *dbRunnerGroup
+}
+
+// dbRunnerGroup - Names of the DB runners to route the read-only statements and the other statements to.
+type dbRunnerGroup struct {
+	read  string
+	write string
+}
+
+// dbReplica - Replica of the database of the DB runner.
+type dbReplica struct {
+	// DSN of the replica ( password masked )
+	dsn    string
+	client TxQuerier
 }
 
 type dbQuery struct {
-	stmt string
+	stmt   string
+	poll   *Loop
+	listen *dbListen
+	// maximum number of rows to scan per result set ( nil: follow DBMaxRows, <= 0: unlimited )
+	maxRows *int
+	// column to key the rows by ( recorded to `byKey` )
+	keyBy string
+	// table to introspect the columns of ( recorded to `columns` )
+	describe string
+	// mode of capturing the explain plans of the statements ( recorded to `plan` )
+	explain dbExplain
+}
+
+// dbListen - LISTEN on the channel and wait for the NOTIFY ( Postgres only ).
+type dbListen struct {
+	channel string
+	timeout time.Duration
 }
 
 type DBResponse struct {
@@ -47,16 +123,34 @@ type DBResponse struct {
 	RowsAffected int64
 	Columns      []string
 	Rows         []map[string]interface{}
+	// DSN ( password masked ) of the database that served the statement ( set only when the runner has replicas )
+	DSN string
+	// name of the DB runner that served the statement ( set only when the statement is routed by the DB runner group )
+	Runner string
 }
 
 func newDBRunner(name, dsn string) (*dbRunner, error) {
-	var db *sql.DB
-	var err error
+	var (
+		db      *sql.DB
+		pgDSN   string
+		dialect string
+		err     error
+	)
 	if strings.HasPrefix(dsn, "sp://") || strings.HasPrefix(dsn, "spanner://") {
 		d := strings.Split(strings.Split(dsn, "://")[1], "/")
+		dialect = "spanner"
 		db, err = sql.Open("spanner", fmt.Sprintf(`projects/%s/instances/%s/databases/%s`, d[0], d[1], d[2]))
 	} else {
-		db, err = dburl.Open(normalizeDSN(dsn))
+		var u *dburl.URL
+		u, err = dburl.Parse(normalizeDSN(dsn))
+		if err != nil {
+			return nil, err
+		}
+		if u.Driver == "postgres" {
+			pgDSN = u.DSN
+		}
+		dialect = u.Driver
+		db, err = sql.Open(u.Driver, u.DSN)
 	}
 	if err != nil {
 		return nil, err
@@ -66,11 +160,34 @@ func newDBRunner(name, dsn string) (*dbRunner, error) {
 		return nil, err
 	}
 	return &dbRunner{
-		name:   name,
-		client: nx,
+		name:    name,
+		client:  nx,
+		pgDSN:   pgDSN,
+		dialect: dialect,
 	}, nil
 }
 
+// newDBRunnerWithDriver returns a new DB runner that opens the DSN with the registered database/sql driver.
+func newDBRunnerWithDriver(name, driverName, dsn string) (*dbRunner, error) {
+	db, err := sql.Open(driverName, dsn)
+	if err != nil {
+		return nil, err
+	}
+	nx, err := nestTx(db)
+	if err != nil {
+		return nil, err
+	}
+	r := &dbRunner{
+		name:    name,
+		client:  nx,
+		dialect: driverName,
+	}
+	if driverName == "postgres" {
+		r.pgDSN = dsn
+	}
+	return r, nil
+}
+
 var dsnRep = strings.NewReplacer("sqlite://", "moderncsqlite://", "sqlite3://", "moderncsqlite://", "sq://", "moderncsqlite://")
 
 func normalizeDSN(dsn string) string {
@@ -81,16 +198,196 @@ func normalizeDSN(dsn string) string {
 }
 
 func (rnr *dbRunner) Run(ctx context.Context, q *dbQuery) error {
-	stmts := separateStmt(q.stmt)
-	out := map[string]interface{}{}
-	tx, err := rnr.client.BeginTx(ctx, &sql.TxOptions{})
+	if rnr.group != nil && (q.describe != "" || q.listen != nil) {
+		// describe reads the schema, and listen waits for the NOTIFY of the writes
+		target, err := rnr.route(q.describe != "")
+		if err != nil {
+			return err
+		}
+		return target.Run(ctx, q)
+	}
+	if q.describe != "" {
+		out, err := rnr.describe(ctx, q.describe)
+		if err != nil {
+			return err
+		}
+		rnr.operator.record(out)
+		return nil
+	}
+	if q.listen != nil {
+		out, err := rnr.runWithListen(ctx, q)
+		if err != nil {
+			return err
+		}
+		if err := keyRowsBy(out, q.keyBy); err != nil {
+			return err
+		}
+		rnr.operator.record(out)
+		return nil
+	}
+	if q.poll == nil {
+		out, err := rnr.run(ctx, q.stmt, rnr.maxRows(q), q.explain)
+		if err != nil {
+			return err
+		}
+		if err := keyRowsBy(out, q.keyBy); err != nil {
+			return err
+		}
+		rnr.operator.record(out)
+		return nil
+	}
+
+	// poll: re-run the query until the condition is satisfied
+	store := rnr.operator.store.toMap()
+	store[storeIncludedKey] = rnr.operator.included
+	store[storePreviousKey] = rnr.operator.store.latest()
+	c, err := EvalCount(q.poll.Count, store)
 	if err != nil {
 		return err
 	}
+	var (
+		out map[string]interface{}
+		bt  string
+		j   int
+		// number of queries executed in all attempts
+		qc int
+	)
+	for q.poll.Loop(ctx) {
+		if j >= c {
+			break
+		}
+		out, err = rnr.run(ctx, q.stmt, rnr.maxRows(q), q.explain)
+		if err != nil {
+			return err
+		}
+		if err := keyRowsBy(out, q.keyBy); err != nil {
+			return err
+		}
+		qc += out[dbStoreQueryCountKey].(int)
+		out[dbStoreQueryCountKey] = qc
+		store[storeCurrentKey] = out
+		bt, err = buildTree(q.poll.Until, store)
+		if err != nil {
+			return err
+		}
+		tf, err := EvalCond(q.poll.Until, store)
+		if err != nil {
+			return err
+		}
+		if tf {
+			rnr.operator.record(out)
+			return nil
+		}
+		j++
+	}
+	if out != nil {
+		// record the last result
+		rnr.operator.record(out)
+	}
+	if err := ctx.Err(); err != nil {
+		// the poll was stopped by the cancellation, not by the condition
+		return err
+	}
+	err = fmt.Errorf("(%s) is not true\n%s", q.poll.Until, bt)
+	if q.poll.interval != nil {
+		return fmt.Errorf("poll failed (maxAttempts: %d, interval: %v): %w", c, *q.poll.interval, err)
+	}
+	return fmt.Errorf("poll failed (maxAttempts: %d, minInterval: %v, maxInterval: %v): %w", c, *q.poll.minInterval, *q.poll.maxInterval, err)
+}
+
+// runWithListen LISTENs on the channel, runs the statement ( if any ) and waits for the NOTIFY until the timeout.
+func (rnr *dbRunner) runWithListen(ctx context.Context, q *dbQuery) (map[string]interface{}, error) {
+	l := q.listen
+	if rnr.pgDSN == "" {
+		return nil, fmt.Errorf("listen is supported only by the DB runner using Postgres DSN: %s", rnr.name)
+	}
+	ctx, cancel := context.WithTimeout(ctx, l.timeout)
+	defer cancel()
+	listener := pq.NewListener(rnr.pgDSN, 10*time.Millisecond, time.Second, nil)
+	defer listener.Close()
+
+	// (*pq.Listener).Listen blocks until the connection is established
+	lerr := make(chan error, 1)
+	go func() {
+		lerr <- listener.Listen(l.channel)
+	}()
+	select {
+	case err := <-lerr:
+		if err != nil {
+			return nil, err
+		}
+	case <-ctx.Done():
+		return nil, fmt.Errorf("listen failed (channel: %s, timeout: %v): %w", l.channel, l.timeout, ctx.Err())
+	}
+
+	out := map[string]interface{}{
+		dbStoreQueryCountKey: 0,
+	}
+	if q.stmt != "" {
+		var err error
+		out, err = rnr.run(ctx, q.stmt, rnr.maxRows(q), q.explain)
+		if err != nil {
+			return nil, err
+		}
+	}
+	for {
+		select {
+		case n := <-listener.Notify:
+			if n == nil {
+				// reconnected
+				continue
+			}
+			out[dbStoreNotificationKey] = map[string]interface{}{
+				"channel": n.Channel,
+				"payload": n.Extra,
+			}
+			return out, nil
+		case <-ctx.Done():
+			return nil, fmt.Errorf("no notification received (channel: %s, timeout: %v): %w", l.channel, l.timeout, ctx.Err())
+		}
+	}
+}
+
+// maxRows returns the maximum number of rows to scan per result set ( <= 0: unlimited ).
+func (rnr *dbRunner) maxRows(q *dbQuery) int {
+	if q.maxRows != nil {
+		return *q.maxRows
+	}
+	return rnr.operator.dbMaxRows
+}
+
+func (rnr *dbRunner) run(ctx context.Context, stmt string, maxRows int, explain dbExplain) (map[string]interface{}, error) {
+	stmts := separateStmt(stmt)
+	out := map[string]interface{}{}
+	plans := []interface{}{}
+	readOnly := isReadOnlyStmts(stmts)
+	target, err := rnr.route(readOnly)
+	if err != nil {
+		return nil, err
+	}
+	var servedBy string
+	if target != rnr {
+		servedBy = target.name
+	}
+	tx, dsn, err := target.beginTx(ctx, readOnly)
+	if err != nil {
+		return nil, err
+	}
 	for _, stmt := range stmts {
 		rnr.operator.capturers.captureDBStatement(rnr.name, stmt)
 		err := func() error {
-			if !strings.HasPrefix(strings.ToUpper(stmt), "SELECT") {
+			// abort the remaining statements when the run is cancelled
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if explain != dbExplainNone && isExplainableStmt(stmt) {
+				plan, err := target.explain(ctx, tx, stmt, explain)
+				if err != nil {
+					return err
+				}
+				plans = append(plans, plan)
+			}
+			if !isQueryStmt(stmt) {
 				// exec
 				r, err := tx.ExecContext(ctx, stmt)
 				if err != nil {
@@ -106,99 +403,528 @@ func (rnr *dbRunner) Run(ctx context.Context, q *dbQuery) error {
 				rnr.operator.capturers.captureDBResponse(rnr.name, &DBResponse{
 					LastInsertID: id,
 					RowsAffected: a,
+					DSN:          dsn,
+					Runner:       servedBy,
 				})
 
 				return nil
 			}
 
 			// query
-			rows := []map[string]interface{}{}
 			r, err := tx.QueryContext(ctx, stmt)
 			if err != nil {
 				return err
 			}
 			defer r.Close()
 
-			columns, err := r.Columns()
-			if err != nil {
-				return err
-			}
-			types, err := r.ColumnTypes()
-			if err != nil {
-				return err
-			}
-			for r.Next() {
-				row := map[string]interface{}{}
-				vals := make([]interface{}, len(columns))
-				valsp := make([]interface{}, len(columns))
-				for i := range columns {
-					valsp[i] = &vals[i]
-				}
-				if err := r.Scan(valsp...); err != nil {
+			// read all result sets ( e.g. CALL of stored procedures )
+			resultSets := [][]map[string]interface{}{}
+			truncated := false
+			for {
+				columns, rows, t, err := scanRows(r, maxRows, target.timeLayouts, target.numericAs)
+				if err != nil {
 					return err
 				}
-				for i, c := range columns {
-					switch v := vals[i].(type) {
-					case []byte:
-						s := string(v)
-						t := strings.ToUpper(types[i].DatabaseTypeName())
-						switch {
-						case strings.Contains(t, "TEXT") || strings.Contains(t, "CHAR") || t == "TIME": // MySQL8: ENUM = CHAR
-							row[c] = s
-						case t == "DECIMAL" || t == "FLOAT" || t == "DOUBLE": // MySQL: NUMERIC = DECIMAL
-							num, err := strconv.ParseFloat(s, 64)
-							if err != nil {
-								return fmt.Errorf("invalid column: evaluated %s, but got %s(%v): %w", c, t, s, err)
-							}
-							row[c] = num
-						case t == "DATE" || t == "TIMESTAMP" || t == "DATETIME": // MySQL(SSH port fowarding)
-							d, err := dateparse.ParseStrict(s)
-							if err != nil {
-								return fmt.Errorf("invalid column: evaluated %s, but got %s(%v): %w", c, t, s, err)
-							}
-							row[c] = d
-						default: // MySQL: BOOLEAN = TINYINT
-							num, err := strconv.Atoi(s)
-							if err != nil {
-								return fmt.Errorf("invalid column: evaluated %s, but got %s(%v): %w", c, t, s, err)
-							}
-							row[c] = num
-						}
-					default:
-						// MySQL8: DATE, TIMESTAMP, DATETIME
-						row[c] = v
-					}
+				truncated = truncated || t
+				rnr.operator.capturers.captureDBResponse(rnr.name, &DBResponse{
+					Columns: columns,
+					Rows:    rows,
+					DSN:     dsn,
+					Runner:  servedBy,
+				})
+				resultSets = append(resultSets, rows)
+				if !r.NextResultSet() {
+					break
 				}
-				rows = append(rows, row)
 			}
 			if err := r.Err(); err != nil {
 				return err
 			}
 
-			rnr.operator.capturers.captureDBResponse(rnr.name, &DBResponse{
-				Columns: columns,
-				Rows:    rows,
-			})
-
 			out = map[string]interface{}{
-				string(dbStoreRowsKey): rows,
+				string(dbStoreRowsKey): resultSets[0],
+			}
+			if len(resultSets) > 1 {
+				out[string(dbStoreResultSetsKey)] = resultSets
+			}
+			if truncated {
+				out[string(dbStoreTruncatedKey)] = true
 			}
 			return nil
 		}()
 		if err != nil {
-			if err := tx.Rollback(); err != nil {
-				return err
+			// the transaction is already rolled back by database/sql when the context is cancelled
+			if rerr := tx.Rollback(); rerr != nil && !errors.Is(rerr, sql.ErrTxDone) {
+				return nil, rerr
 			}
-			return err
+			return nil, withContextErr(ctx, err)
 		}
 	}
 	if err := tx.Commit(); err != nil {
+		return nil, withContextErr(ctx, err)
+	}
+	out[dbStoreQueryCountKey] = len(stmts)
+	if explain != dbExplainNone {
+		out[dbStorePlanKey] = plans
+	}
+	return out, nil
+}
+
+// route returns the DB runner to serve the statements.
+// The DB runner group routes the read-only statements to the runner of `read:` and the other statements to the runner of `write:`, and the other DB runners serve the statements by themselves.
+func (rnr *dbRunner) route(readOnly bool) (*dbRunner, error) {
+	if rnr.group == nil {
+		return rnr, nil
+	}
+	name := rnr.group.write
+	if readOnly {
+		name = rnr.group.read
+	}
+	target, ok := rnr.operator.dbRunners[name]
+	if !ok {
+		return nil, fmt.Errorf("DB runner not found: %s (routed by the DB runner group %s)", name, rnr.name)
+	}
+	return target, nil
+}
+
+// validateGroup validates that the DB runners of the DB runner group exist and are not DB runner groups.
+func (rnr *dbRunner) validateGroup(runners map[string]*dbRunner) error {
+	if rnr.group == nil {
+		return nil
+	}
+	for _, name := range []string{rnr.group.read, rnr.group.write} {
+		r, ok := runners[name]
+		if !ok {
+			return fmt.Errorf("DB runner not found: %s", name)
+		}
+		if r.group != nil {
+			return fmt.Errorf("cannot route to the DB runner group: %s", name)
+		}
+	}
+	return nil
+}
+
+// beginTx begins the transaction on the primary, or on the replica in round-robin if the transaction is read-only.
+// If the connection to the database fails, it fails over to the other replicas and then the primary for reads.
+// Writes never fail over to the replicas, so the transaction fails when the connection to the primary fails.
+// It also returns the DSN that served the transaction when the runner has replicas.
+func (rnr *dbRunner) beginTx(ctx context.Context, readOnly bool) (*nest.Tx, string, error) {
+	if len(rnr.replicas) == 0 {
+		tx, err := rnr.client.BeginTx(ctx, &sql.TxOptions{})
+		return tx, "", err
+	}
+	primary := &dbReplica{dsn: rnr.primaryDSN, client: rnr.client}
+	if !readOnly {
+		tx, err := primary.client.BeginTx(ctx, &sql.TxOptions{})
+		if err != nil {
+			return nil, "", withContextErr(ctx, fmt.Errorf("failed to connect to the primary of the DB runner %s: %w", rnr.name, err))
+		}
+		return tx, primary.dsn, nil
+	}
+	var candidates []*dbReplica
+	n := len(rnr.replicas)
+	start := int(atomic.AddUint32(&rnr.nextReplica, 1)-1) % n
+	for i := 0; i < n; i++ {
+		candidates = append(candidates, rnr.replicas[(start+i)%n])
+	}
+	candidates = append(candidates, primary)
+	var errs error
+	for _, c := range candidates {
+		tx, err := c.client.BeginTx(ctx, &sql.TxOptions{})
+		if err == nil {
+			return tx, c.dsn, nil
+		}
+		if ctx.Err() != nil {
+			return nil, "", withContextErr(ctx, err)
+		}
+		rnr.operator.Debugf("failed to connect to %s ( failover to the next DSN ): %v\n", c.dsn, err)
+		errs = errors.Join(errs, fmt.Errorf("%s: %w", c.dsn, err))
+	}
+	return nil, "", fmt.Errorf("failed to connect to all DSNs of the DB runner %s: %w", rnr.name, errs)
+}
+
+// withContextErr wraps the error with the error of the context when the context is cancelled ( or timed out ), because drivers may return their own errors for the interrupted statements.
+func withContextErr(ctx context.Context, err error) error {
+	cerr := ctx.Err()
+	if cerr == nil || errors.Is(err, cerr) {
 		return err
 	}
-	rnr.operator.record(out)
+	return fmt.Errorf("%w: %w", cerr, err)
+}
+
+// quoteIdent returns the identifier quoted for the dialect of the database.
+func (rnr *dbRunner) quoteIdent(ident string) string {
+	if rnr.group != nil {
+		if target, err := rnr.route(false); err == nil {
+			return target.quoteIdent(ident)
+		}
+	}
+	return builtin.SQLQuoteIdent(ident, rnr.dialect)
+}
+
+// sqlQuoteIdent returns the identifier quoted for the dialect of the DB runner named runnerOrDialect ( or the dialect ).
+func (o *operator) sqlQuoteIdent(ident, runnerOrDialect string) string {
+	if r, ok := o.dbRunners[runnerOrDialect]; ok {
+		return r.quoteIdent(ident)
+	}
+	return builtin.SQLQuoteIdent(ident, runnerOrDialect)
+}
+
+// quoteValue returns the value quoted as a SQL literal for the dialect of the database.
+func (rnr *dbRunner) quoteValue(v interface{}) string {
+	if rnr.group != nil {
+		if target, err := rnr.route(false); err == nil {
+			return target.quoteValue(v)
+		}
+	}
+	return builtin.SQLQuote(v, rnr.dialect)
+}
+
+// sqlQuote returns the value quoted as a SQL literal for the dialect of the DB runner named runnerOrDialect ( or the dialect ).
+func (o *operator) sqlQuote(v interface{}, runnerOrDialect ...string) string {
+	if len(runnerOrDialect) == 1 {
+		if r, ok := o.dbRunners[runnerOrDialect[0]]; ok {
+			return r.quoteValue(v)
+		}
+	}
+	return builtin.SQLQuote(v, runnerOrDialect...)
+}
+
+// keyRowsBy records the rows keyed by the value of the column to `byKey` ( the rows are kept ).
+func keyRowsBy(out map[string]interface{}, column string) error {
+	if column == "" {
+		return nil
+	}
+	rows, ok := out[dbStoreRowsKey].([]map[string]interface{})
+	if !ok {
+		return fmt.Errorf("keyBy requires the statement that returns rows: %s", column)
+	}
+	byKey := map[string]interface{}{}
+	for i, row := range rows {
+		v, ok := row[column]
+		if !ok {
+			return fmt.Errorf("keyBy column '%s' does not exist in rows[%d]", column, i)
+		}
+		if v == nil {
+			return fmt.Errorf("keyBy column '%s' is NULL in rows[%d]", column, i)
+		}
+		k := fmt.Sprintf("%v", v)
+		if _, ok := byKey[k]; ok {
+			return fmt.Errorf("keyBy column '%s' has the duplicate value '%s' in rows[%d]", column, k, i)
+		}
+		byKey[k] = row
+	}
+	out[dbStoreByKeyKey] = byKey
 	return nil
 }
 
+// describe introspects the columns of the table ( `schema.table` is also accepted ) and records their names, types and nullability to `columns`.
+func (rnr *dbRunner) describe(ctx context.Context, table string) (map[string]interface{}, error) {
+	var schema string
+	if i := strings.LastIndex(table, "."); i >= 0 {
+		schema, table = table[:i], table[i+1:]
+	}
+	var (
+		stmt string
+		args = []interface{}{table}
+	)
+	switch rnr.dialect {
+	case "sqlite", "sqlite3", "sq", "moderncsqlite", "file":
+		if schema == "" {
+			schema = "main"
+		}
+		stmt = `SELECT name, type, "notnull" = 0 FROM pragma_table_info(?, ?) ORDER BY cid`
+		args = append(args, schema)
+	case "mysql", "my", "mariadb", "maria", "tidb":
+		stmt = "SELECT COLUMN_NAME, COLUMN_TYPE, IS_NULLABLE = 'YES' FROM information_schema.COLUMNS WHERE TABLE_NAME = ? AND TABLE_SCHEMA = COALESCE(NULLIF(?, ''), DATABASE()) ORDER BY ORDINAL_POSITION"
+		args = append(args, schema)
+	case "postgres", "postgresql", "pg", "pgsql", "pgx":
+		stmt = "SELECT column_name, data_type, is_nullable = 'YES' FROM information_schema.columns WHERE table_name = $1 AND table_schema = COALESCE(NULLIF($2, ''), current_schema()) ORDER BY ordinal_position"
+		args = append(args, schema)
+	case "sqlserver", "mssql", "ms", "azuresql":
+		stmt = "SELECT COLUMN_NAME, DATA_TYPE, CASE WHEN IS_NULLABLE = 'YES' THEN 1 ELSE 0 END FROM INFORMATION_SCHEMA.COLUMNS WHERE TABLE_NAME = @p1 AND TABLE_SCHEMA = COALESCE(NULLIF(@p2, ''), SCHEMA_NAME()) ORDER BY ORDINAL_POSITION"
+		args = append(args, schema)
+	case "spanner":
+		stmt = "SELECT COLUMN_NAME, SPANNER_TYPE, IS_NULLABLE = 'YES' FROM INFORMATION_SCHEMA.COLUMNS WHERE TABLE_NAME = @p1 AND TABLE_SCHEMA = @p2 ORDER BY ORDINAL_POSITION"
+		args = append(args, schema)
+	default:
+		return nil, fmt.Errorf("describe is not supported by the dialect of the DB runner: %s (%s)", rnr.name, rnr.dialect)
+	}
+	rnr.operator.capturers.captureDBStatement(rnr.name, stmt)
+	r, err := rnr.client.QueryContext(ctx, stmt, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	columns := []interface{}{}
+	rows := []map[string]interface{}{}
+	for r.Next() {
+		var (
+			name, typ string
+			nullable  bool
+		)
+		if err := r.Scan(&name, &typ, &nullable); err != nil {
+			return nil, err
+		}
+		c := map[string]interface{}{
+			"name":     name,
+			"type":     typ,
+			"nullable": nullable,
+		}
+		columns = append(columns, c)
+		rows = append(rows, c)
+	}
+	if err := r.Err(); err != nil {
+		return nil, err
+	}
+	rnr.operator.capturers.captureDBResponse(rnr.name, &DBResponse{
+		Columns: []string{"name", "type", "nullable"},
+		Rows:    rows,
+	})
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("table '%s' does not exist or has no columns", table)
+	}
+	return map[string]interface{}{
+		string(dbStoreColumnsKey):    columns,
+		string(dbStoreQueryCountKey): 1,
+	}, nil
+}
+
+// queryStmtPrefixes - keywords of the statements that return rows.
+var queryStmtPrefixes = []string{"SELECT", "CALL", "WITH", "SHOW", "PRAGMA", "EXPLAIN", "VALUES", "DESCRIBE"}
+
+var returningRe = regexp.MustCompile(`(?i)\bRETURNING\b`)
+
+var readStmtPrefixes = []string{"SELECT", "WITH", "SHOW", "EXPLAIN", "VALUES", "DESCRIBE"}
+
+// writeInReadStmtRe matches the keywords that make the read statement write ( e.g. `SELECT ... FOR UPDATE`, `WITH ... INSERT` ).
+var writeInReadStmtRe = regexp.MustCompile(`(?i)\b(INSERT|UPDATE|DELETE|MERGE|INTO|RETURNING)\b`)
+
+// isReadOnlyStmts returns whether all statements only read the database and can be routed to the replicas.
+func isReadOnlyStmts(stmts []string) bool {
+	for _, stmt := range stmts {
+		u := strings.ToUpper(strings.TrimLeft(trimSQLComments(stmt), " \t\r\n("))
+		read := false
+		for _, p := range readStmtPrefixes {
+			if strings.HasPrefix(u, p) {
+				read = true
+				break
+			}
+		}
+		if !read || writeInReadStmtRe.MatchString(trimSQLLiterals(u)) {
+			return false
+		}
+	}
+	return len(stmts) > 0
+}
+
+// redactDSN returns the DSN with the password masked.
+func redactDSN(dsn string) string {
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return dsn
+	}
+	return u.Redacted()
+}
+
+// isQueryStmt returns whether the statement returns rows.
+func isQueryStmt(stmt string) bool {
+	u := strings.ToUpper(strings.TrimLeft(trimSQLComments(stmt), " \t\r\n("))
+	for _, p := range queryStmtPrefixes {
+		if strings.HasPrefix(u, p) {
+			return true
+		}
+	}
+	// e.g. INSERT ... RETURNING id
+	return returningRe.MatchString(trimSQLLiterals(u))
+}
+
+// trimSQLComments removes the leading comments ( `-- ...` and `/* ... */` ) of the statement.
+func trimSQLComments(stmt string) string {
+	for {
+		stmt = strings.TrimLeft(stmt, " \t\r\n")
+		switch {
+		case strings.HasPrefix(stmt, "--"):
+			i := strings.Index(stmt, "\n")
+			if i < 0 {
+				return ""
+			}
+			stmt = stmt[i+1:]
+		case strings.HasPrefix(stmt, "/*"):
+			i := strings.Index(stmt, "*/")
+			if i < 0 {
+				return ""
+			}
+			stmt = stmt[i+2:]
+		default:
+			return stmt
+		}
+	}
+}
+
+// trimSQLLiterals removes the string literals and the quoted identifiers of the statement.
+func trimSQLLiterals(stmt string) string {
+	var (
+		b     strings.Builder
+		quote rune
+	)
+	for _, c := range stmt {
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+		default:
+			b.WriteRune(c)
+		}
+	}
+	return b.String()
+}
+
+// scanRows scans the rows of the current result set.
+// If the number of rows exceeds maxRows ( > 0 ), it stops scanning and returns the partial rows with truncated = true.
+// If numericAs is set, the numeric columns are normalized to float64 or string.
+func scanRows(r *sql.Rows, maxRows int, timeLayouts []string, numericAs string) ([]string, []map[string]interface{}, bool, error) {
+	rows := []map[string]interface{}{}
+	columns, err := r.Columns()
+	if err != nil {
+		return nil, nil, false, err
+	}
+	types, err := r.ColumnTypes()
+	if err != nil {
+		return nil, nil, false, err
+	}
+	for r.Next() {
+		if maxRows > 0 && len(rows) >= maxRows {
+			return columns, rows, true, nil
+		}
+		row := map[string]interface{}{}
+		vals := make([]interface{}, len(columns))
+		valsp := make([]interface{}, len(columns))
+		for i := range columns {
+			valsp[i] = &vals[i]
+		}
+		if err := r.Scan(valsp...); err != nil {
+			return nil, nil, false, err
+		}
+		for i, c := range columns {
+			switch v := vals[i].(type) {
+			case []byte:
+				s := string(v)
+				t := strings.ToUpper(types[i].DatabaseTypeName())
+				switch {
+				case strings.Contains(t, "TEXT") || strings.Contains(t, "CHAR") || t == "TIME": // MySQL8: ENUM = CHAR
+					row[c] = s
+				case t == "DECIMAL" || t == "FLOAT" || t == "DOUBLE": // MySQL: NUMERIC = DECIMAL
+					if numericAs == dbNumericAsString {
+						// keep the precision of DECIMAL
+						row[c] = s
+						break
+					}
+					num, err := strconv.ParseFloat(s, 64)
+					if err != nil {
+						return nil, nil, false, fmt.Errorf("invalid column: evaluated %s, but got %s(%v): %w", c, t, s, err)
+					}
+					row[c] = num
+				case t == "DATE" || t == "TIMESTAMP" || t == "DATETIME": // MySQL(SSH port fowarding)
+					d, err := parseDBTime(s, timeLayouts)
+					if err != nil {
+						return nil, nil, false, fmt.Errorf("invalid column: evaluated %s, but got %s(%v): %w", c, t, s, err)
+					}
+					row[c] = d
+				default: // MySQL: BOOLEAN = TINYINT
+					num, err := strconv.Atoi(s)
+					if err != nil {
+						return nil, nil, false, fmt.Errorf("invalid column: evaluated %s, but got %s(%v): %w", c, t, s, err)
+					}
+					row[c] = num
+				}
+			default:
+				// MySQL8: DATE, TIMESTAMP, DATETIME
+				row[c] = v
+			}
+			if numericAs != "" {
+				row[c] = normalizeDBNumeric(row[c], numericAs)
+			}
+		}
+		rows = append(rows, row)
+	}
+	if err := r.Err(); err != nil {
+		return nil, nil, false, err
+	}
+	return columns, rows, false, nil
+}
+
+// validateDBNumericAs validates the value of `numericAs:`.
+func validateDBNumericAs(numericAs string) error {
+	switch numericAs {
+	case "", dbNumericAsFloat, dbNumericAsString:
+		return nil
+	default:
+		return fmt.Errorf("invalid numericAs: %q (want %q or %q)", numericAs, dbNumericAsFloat, dbNumericAsString)
+	}
+}
+
+// normalizeDBNumeric converts the value of the numeric column to float64 or string. The values of the other types are returned as is.
+func normalizeDBNumeric(v interface{}, numericAs string) interface{} {
+	var (
+		i     int64
+		f     float64
+		isInt bool
+	)
+	switch vv := v.(type) {
+	case int:
+		i, isInt = int64(vv), true
+	case int8:
+		i, isInt = int64(vv), true
+	case int16:
+		i, isInt = int64(vv), true
+	case int32:
+		i, isInt = int64(vv), true
+	case int64:
+		i, isInt = vv, true
+	case uint8:
+		i, isInt = int64(vv), true
+	case uint16:
+		i, isInt = int64(vv), true
+	case uint32:
+		i, isInt = int64(vv), true
+	case uint64:
+		if numericAs == dbNumericAsString {
+			return strconv.FormatUint(vv, 10)
+		}
+		return float64(vv)
+	case float32:
+		f = float64(vv)
+	case float64:
+		f = vv
+	default:
+		return v
+	}
+	switch {
+	case numericAs == dbNumericAsString && isInt:
+		return strconv.FormatInt(i, 10)
+	case numericAs == dbNumericAsString:
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	case isInt:
+		return float64(i)
+	default:
+		return f
+	}
+}
+
+// parseDBTime parses the value of DATE/TIMESTAMP/DATETIME columns with the layouts in order, and falls back to dateparse.
+func parseDBTime(s string, layouts []string) (time.Time, error) {
+	for _, l := range layouts {
+		if d, err := time.Parse(l, s); err == nil {
+			return d, nil
+		}
+	}
+	return dateparse.ParseStrict(s)
+}
+
 func nestTx(client Querier) (TxQuerier, error) {
 	switch c := client.(type) {
 	case *sql.DB:
@@ -0,0 +1,207 @@
+package runn
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/golang-sql/sqlexp/nest"
+)
+
+const (
+	dbStorePlanKey     = "plan"
+	dbStoreFullScanKey = "fullScan"
+)
+
+// dbExplain - Mode of capturing the explain plans of the statements ( `explain:` ).
+type dbExplain string
+
+const (
+	dbExplainNone    dbExplain = ""
+	dbExplainPlan    dbExplain = "plan"
+	dbExplainAnalyze dbExplain = "analyze"
+)
+
+// explainableStmtPrefixes - keywords of the statements whose plans can be explained.
+var explainableStmtPrefixes = []string{"SELECT", "WITH", "INSERT", "UPDATE", "DELETE", "REPLACE"}
+
+func isExplainableStmt(stmt string) bool {
+	s := strings.ToUpper(strings.TrimLeft(stmt, " \n\t("))
+	for _, p := range explainableStmtPrefixes {
+		if strings.HasPrefix(s, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// explainStmt returns the EXPLAIN statement of the statement for the dialect of the DB runner.
+// EXPLAIN ANALYZE actually executes the statement, so the write statements are explained without ANALYZE to avoid executing them twice.
+func (rnr *dbRunner) explainStmt(stmt string, mode dbExplain) (string, error) {
+	analyze := mode == dbExplainAnalyze && isReadOnlyStmts([]string{stmt})
+	switch rnr.dialect {
+	case "sqlite", "sqlite3", "sq", "moderncsqlite", "file":
+		// SQLite does not have EXPLAIN ANALYZE
+		return fmt.Sprintf("EXPLAIN QUERY PLAN %s", stmt), nil
+	case "mysql", "my", "mariadb", "maria", "tidb":
+		if analyze {
+			return fmt.Sprintf("EXPLAIN ANALYZE %s", stmt), nil
+		}
+		return fmt.Sprintf("EXPLAIN %s", stmt), nil
+	case "postgres", "postgresql", "pg", "pgsql", "pgx":
+		if analyze {
+			return fmt.Sprintf("EXPLAIN (ANALYZE, FORMAT JSON) %s", stmt), nil
+		}
+		return fmt.Sprintf("EXPLAIN (FORMAT JSON) %s", stmt), nil
+	default:
+		return "", fmt.Errorf("explain is not supported by the dialect of the DB runner: %s (%s)", rnr.name, rnr.dialect)
+	}
+}
+
+// explain runs EXPLAIN of the statement in the transaction and returns the plan.
+// The plan has the statement ( `query` ), the nodes of the plan ( `nodes` ) and whether any node scans the whole table ( `fullScan` ).
+func (rnr *dbRunner) explain(ctx context.Context, tx *nest.Tx, stmt string, mode dbExplain) (map[string]interface{}, error) {
+	es, err := rnr.explainStmt(stmt, mode)
+	if err != nil {
+		return nil, err
+	}
+	rnr.operator.capturers.captureDBStatement(rnr.name, es)
+	r, err := tx.QueryContext(ctx, es)
+	if err != nil {
+		return nil, fmt.Errorf("failed to explain the statement: %w", err)
+	}
+	defer r.Close()
+	columns, err := r.Columns()
+	if err != nil {
+		return nil, err
+	}
+	// the plans are scanned as strings, because scanRows does not support the JSON type of the plan of Postgres
+	rows := []map[string]interface{}{}
+	for r.Next() {
+		vals := make([]interface{}, len(columns))
+		valsp := make([]interface{}, len(columns))
+		for i := range columns {
+			valsp[i] = &vals[i]
+		}
+		if err := r.Scan(valsp...); err != nil {
+			return nil, err
+		}
+		row := map[string]interface{}{}
+		for i, c := range columns {
+			if b, ok := vals[i].([]byte); ok {
+				row[c] = string(b)
+				continue
+			}
+			row[c] = vals[i]
+		}
+		rows = append(rows, row)
+	}
+	if err := r.Err(); err != nil {
+		return nil, err
+	}
+	rnr.operator.capturers.captureDBResponse(rnr.name, &DBResponse{
+		Columns: columns,
+		Rows:    rows,
+	})
+	plan := map[string]interface{}{
+		"query": stmt,
+	}
+	var nodes []interface{}
+	switch {
+	case contains(columns, "detail"):
+		// SQLite: id, parent, notused, detail
+		for _, row := range rows {
+			d := fmt.Sprintf("%v", row["detail"])
+			nodes = append(nodes, map[string]interface{}{
+				"detail":           d,
+				dbStoreFullScanKey: isSQLiteFullScan(d),
+			})
+		}
+	case contains(columns, "QUERY PLAN"):
+		// Postgres: a JSON array of the plan
+		for _, row := range rows {
+			var v []map[string]interface{}
+			if err := json.Unmarshal([]byte(fmt.Sprintf("%v", row["QUERY PLAN"])), &v); err != nil {
+				return nil, fmt.Errorf("failed to parse the plan: %w", err)
+			}
+			for _, p := range v {
+				for k, vv := range p {
+					if k == "Plan" {
+						continue
+					}
+					// e.g. Planning Time, Execution Time
+					plan[k] = vv
+				}
+				if pn, ok := p["Plan"].(map[string]interface{}); ok {
+					nodes = append(nodes, flattenPgPlan(pn)...)
+				}
+			}
+		}
+	case len(columns) == 1:
+		// MySQL: EXPLAIN ANALYZE returns the plan as the text of the tree
+		for _, row := range rows {
+			for _, l := range strings.Split(fmt.Sprintf("%v", row[columns[0]]), "\n") {
+				if strings.TrimSpace(l) == "" {
+					continue
+				}
+				d := strings.TrimLeft(strings.TrimSpace(l), "-> ")
+				nodes = append(nodes, map[string]interface{}{
+					"detail":           d,
+					dbStoreFullScanKey: strings.HasPrefix(d, "Table scan on "),
+				})
+			}
+		}
+	default:
+		// MySQL: id, select_type, table, type, possible_keys, key, ...
+		for _, row := range rows {
+			n := map[string]interface{}{}
+			for k, v := range row {
+				n[k] = v
+			}
+			n[dbStoreFullScanKey] = fmt.Sprintf("%v", row["type"]) == "ALL"
+			nodes = append(nodes, n)
+		}
+	}
+	fullScan := false
+	for _, n := range nodes {
+		if n.(map[string]interface{})[dbStoreFullScanKey] == true {
+			fullScan = true
+		}
+	}
+	if nodes == nil {
+		nodes = []interface{}{}
+	}
+	plan["nodes"] = nodes
+	plan[dbStoreFullScanKey] = fullScan
+	return plan, nil
+}
+
+// isSQLiteFullScan returns whether the detail of the plan of SQLite scans the whole table ( e.g. `SCAN users` ), not using the index.
+func isSQLiteFullScan(detail string) bool {
+	if !strings.HasPrefix(detail, "SCAN ") || strings.HasPrefix(detail, "SCAN CONSTANT ROW") {
+		return false
+	}
+	return !strings.Contains(detail, " INDEX ")
+}
+
+// flattenPgPlan returns the nodes of the plan tree of Postgres in depth-first order ( the child nodes are not nested ).
+func flattenPgPlan(p map[string]interface{}) []interface{} {
+	n := map[string]interface{}{}
+	for k, v := range p {
+		if k == "Plans" {
+			continue
+		}
+		n[k] = v
+	}
+	n[dbStoreFullScanKey] = p["Node Type"] == "Seq Scan"
+	nodes := []interface{}{n}
+	if children, ok := p["Plans"].([]interface{}); ok {
+		for _, c := range children {
+			if cm, ok := c.(map[string]interface{}); ok {
+				nodes = append(nodes, flattenPgPlan(cm)...)
+			}
+		}
+	}
+	return nodes
+}
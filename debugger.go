@@ -109,6 +109,14 @@ func (d *debugger) CaptureDBStatement(name string, stmt string) {
 func (d *debugger) CaptureDBResponse(name string, res *DBResponse) {
 	_, _ = fmt.Fprint(d.out, "-----START QUERY RESULT-----\n")
 	defer fmt.Fprint(d.out, "-----END QUERY RESULT-----\n")
+	switch {
+	case res.Runner != "" && res.DSN != "":
+		_, _ = fmt.Fprintf(d.out, "served by: %s (%s)\n", res.Runner, res.DSN)
+	case res.Runner != "":
+		_, _ = fmt.Fprintf(d.out, "served by: %s\n", res.Runner)
+	case res.DSN != "":
+		_, _ = fmt.Fprintf(d.out, "served by: %s\n", res.DSN)
+	}
 	if len(res.Rows) == 0 {
 		_, _ = fmt.Fprintf(d.out, "rows affected: %d\n", res.RowsAffected)
 		if res.LastInsertID > 0 {
@@ -140,6 +148,20 @@ func (d *debugger) CaptureExecCommand(command string) {
 	_, _ = fmt.Fprintf(d.out, "-----START COMMAND-----\n%s\n-----END COMMAND-----\n", command)
 }
 
+func (d *debugger) CaptureExecEnv(env map[string]string) {
+	keys := make([]string, 0, len(env))
+	for k := range env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	masked := make([]string, 0, len(keys))
+	for _, k := range keys {
+		// the values of env are always masked because they often contain credentials
+		masked = append(masked, fmt.Sprintf("%s=%s", k, maskedValue))
+	}
+	_, _ = fmt.Fprintf(d.out, "-----START ENV-----\n%s\n-----END ENV-----\n", strings.Join(masked, "\n"))
+}
+
 func (d *debugger) CaptureExecStdin(stdin string) {
 	_, _ = fmt.Fprintf(d.out, "-----START STDIN-----\n%s\n-----END STDIN-----\n", stdin)
 }
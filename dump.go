@@ -7,19 +7,28 @@ import (
 	"os"
 	"path/filepath"
 	"reflect"
+	"strings"
 
 	"github.com/goccy/go-json"
+	"github.com/goccy/go-yaml"
 )
 
 const dumpRunnerKey = "dump"
 
+const (
+	dumpFormatJSON = "json"
+	dumpFormatYAML = "yaml"
+	dumpFormatRaw  = "raw"
+)
+
 type dumpRunner struct {
 	operator *operator
 }
 
 type dumpRequest struct {
-	expr string
-	out  string
+	expr   string
+	out    string
+	format string
 }
 
 func newDumpRunner(o *operator) (*dumpRunner, error) {
@@ -59,10 +68,55 @@ func (rnr *dumpRunner) Run(ctx context.Context, r *dumpRequest, first bool) erro
 			return fmt.Errorf("invalid dump out: %v", pp)
 		}
 	}
-	v, err := Eval(r.expr, store)
+	v, err := Eval(rnr.operator.store.resolveRelativeIndex(r.expr).(string), store)
 	if err != nil {
 		return err
 	}
+	if r.out == "" {
+		v = rnr.operator.secretMasker.maskValue(v)
+	}
+	if err := dumpValue(out, v, r.format); err != nil {
+		return err
+	}
+	if r.out == "" {
+		if _, err := fmt.Fprint(out, "\n"); err != nil {
+			return err
+		}
+	}
+	if first {
+		rnr.operator.record(nil)
+	}
+	return nil
+}
+
+func dumpValue(out io.Writer, v interface{}, format string) error {
+	if reflect.ValueOf(v).Kind() == reflect.Func {
+		_, err := fmt.Fprint(out, storeFuncValue)
+		return err
+	}
+	switch format {
+	case dumpFormatJSON:
+		b, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = out.Write(b)
+		return err
+	case dumpFormatYAML:
+		b, err := yaml.Marshal(v)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprint(out, strings.TrimSuffix(string(b), "\n"))
+		return err
+	case dumpFormatRaw:
+		if vv, ok := v.([]byte); ok {
+			_, err := out.Write(vv)
+			return err
+		}
+		_, err := fmt.Fprint(out, v)
+		return err
+	}
 	switch vv := v.(type) {
 	case string:
 		if _, err := fmt.Fprint(out, vv); err != nil {
@@ -74,27 +128,13 @@ func (rnr *dumpRunner) Run(ctx context.Context, r *dumpRequest, first bool) erro
 			return err
 		}
 	default:
-		if reflect.ValueOf(v).Kind() == reflect.Func {
-			if _, err := fmt.Fprint(out, storeFuncValue); err != nil {
-				return err
-			}
-		} else {
-			b, err := json.MarshalIndent(v, "", "  ")
-			if err != nil {
-				return err
-			}
-			if _, err := fmt.Fprint(out, string(b)); err != nil {
-				return err
-			}
+		b, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return err
 		}
-	}
-	if r.out == "" {
-		if _, err := fmt.Fprint(out, "\n"); err != nil {
+		if _, err := fmt.Fprint(out, string(b)); err != nil {
 			return err
 		}
 	}
-	if first {
-		rnr.operator.record(nil)
-	}
 	return nil
 }
@@ -152,6 +152,100 @@ func TestDumpRunnerRun(t *testing.T) {
 	}
 }
 
+func TestDumpRunnerRunWithFormat(t *testing.T) {
+	tests := []struct {
+		store  store
+		expr   string
+		format string
+		want   string
+	}{
+		{
+			store{
+				vars: map[string]interface{}{
+					"key": "value",
+				},
+			},
+			"vars.key",
+			dumpFormatJSON,
+			`"value"
+`,
+		},
+		{
+			store{
+				vars: map[string]interface{}{
+					"key": "value",
+				},
+			},
+			"vars.key",
+			dumpFormatRaw,
+			`value
+`,
+		},
+		{
+			store{
+				vars: map[string]interface{}{
+					"z": "value",
+					"a": map[string]interface{}{
+						"y": 1,
+						"b": []interface{}{"one", "two"},
+					},
+				},
+			},
+			"vars",
+			dumpFormatYAML,
+			`a:
+  b:
+  - one
+  - two
+  "y": 1
+z: value
+`,
+		},
+		{
+			store{
+				vars: map[string]interface{}{
+					"z": "value",
+					"a": "value",
+				},
+			},
+			"vars",
+			dumpFormatJSON,
+			`{
+  "a": "value",
+  "z": "value"
+}
+`,
+		},
+	}
+	ctx := context.Background()
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf("%d.%s.%s", i, tt.expr, tt.format), func(t *testing.T) {
+			o, err := New()
+			if err != nil {
+				t.Fatal(err)
+			}
+			buf := new(bytes.Buffer)
+			o.store = tt.store
+			o.stdout = buf
+			d, err := newDumpRunner(o)
+			if err != nil {
+				t.Fatal(err)
+			}
+			req := &dumpRequest{
+				expr:   tt.expr,
+				format: tt.format,
+			}
+			if err := d.Run(ctx, req, true); err != nil {
+				t.Fatal(err)
+			}
+			got := buf.String()
+			if got != tt.want {
+				t.Errorf("got\n%#v\nwant\n%#v", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestDumpRunnerRunWithOut(t *testing.T) {
 	tests := []struct {
 		store store
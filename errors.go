@@ -1,6 +1,11 @@
 package runn
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+
+	"github.com/antonmedv/expr/file"
+)
 
 type BeforeFuncError struct{ err error }
 
@@ -21,3 +26,37 @@ func (e AfterFuncError) Unwrap() error { return e.err }
 func newAfterFuncError(err error) *AfterFuncError {
 	return &AfterFuncError{err: err}
 }
+
+// StepExprError - Error of the evaluation of expr in the step collected by CollectExprErrors.
+type StepExprError struct {
+	// StepName is the name of the step ( e.g. `'desc'.steps[2]` )
+	StepName string
+	// Path and Line are the location of the step in the runbook file
+	Path string
+	Line int
+	err  error
+}
+
+func (e StepExprError) Error() string {
+	if e.Path != "" {
+		return fmt.Errorf("expr error on %s (%s:%d): %w", e.StepName, e.Path, e.Line, e.err).Error()
+	}
+	return fmt.Errorf("expr error on %s: %w", e.StepName, e.err).Error()
+}
+
+func (e StepExprError) Unwrap() error { return e.err }
+
+func newStepExprError(stepName string, src *stepSource, err error) *StepExprError {
+	e := &StepExprError{StepName: stepName, err: err}
+	if src != nil {
+		e.Path = src.path
+		e.Line = src.line
+	}
+	return e
+}
+
+// isExprError returns whether the error is caused by expr ( syntax errors, undefined functions, runtime errors and so on ).
+func isExprError(err error) bool {
+	var fe *file.Error
+	return errors.As(err, &fe)
+}
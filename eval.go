@@ -22,10 +22,29 @@ const (
 	delimEnd   = "}}"
 )
 
+// containsFuncName - name of the registered function for `contains(...)`.
+// `contains` is an operator of expr, so function calls of `contains(...)` are replaced with it before evaluation.
+const containsFuncName = "__contains"
+
+// compareFuncName - name of the built-in function `compare`, whose diff is shown when it is false in `test:`.
+const compareFuncName = "compare"
+
+// matchesAnyFuncName - name of the built-in function `matchesAny`, whose diff is shown when it is false in `test:`.
+const matchesAnyFuncName = "matchesAny"
+
+// matchesAnyDiffFuncName - name of the registered function to show the diff of `matchesAny(...)`.
+const matchesAnyDiffFuncName = "__matchesAnyDiff"
+
+// diffFuncNames - names of the functions returning the diff of the built-in functions that are false in `test:`.
+var diffFuncNames = map[string]string{
+	compareFuncName:    "diff",
+	matchesAnyFuncName: matchesAnyDiffFuncName,
+}
+
 var alphaRe = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9]*$`)
 
 func Eval(e string, store interface{}) (interface{}, error) {
-	v, err := expr.Eval(trimComment(e), store)
+	v, err := expr.Eval(replaceContainsFuncCall(trimComment(e)), store)
 	if err != nil {
 		return nil, fmt.Errorf("eval error: %w", err)
 	}
@@ -103,7 +122,7 @@ func buildTree(cond string, store interface{}) (string, error) {
 	cond = trimComment(cond)
 	tree := treeprint.New()
 	tree.SetValue(cond)
-	vs, err := values(cond)
+	vs, err := values(replaceContainsFuncCall(cond))
 	if err != nil {
 		return "", err
 	}
@@ -132,6 +151,49 @@ func buildTree(cond string, store interface{}) (string, error) {
 	return tree.String(), nil
 }
 
+// compareDiffs returns the diffs of the `compare` and `matchesAny` calls that are false in the condition, to show why the values do not match.
+func compareDiffs(cond string, store interface{}) string {
+	t, err := parser.Parse(replaceContainsFuncCall(trimComment(cond)))
+	if err != nil {
+		return ""
+	}
+	v := &compareCallsVisitor{}
+	ast.Walk(&t.Node, v)
+	var b strings.Builder
+	for _, c := range v.calls {
+		call := callNode(c)[0]
+		tf, err := EvalCond(call, store)
+		if err != nil || tf {
+			continue
+		}
+		callee := nodeValue(c.Callee)
+		d, err := Eval(fmt.Sprintf("%s%s", diffFuncNames[callee], strings.TrimPrefix(call, callee)), store)
+		if err != nil {
+			continue
+		}
+		ds, ok := d.(string)
+		if !ok || ds == "" {
+			continue
+		}
+		b.WriteString(fmt.Sprintf("%s diff:\n%s\n", call, strings.TrimRight(ds, "\n")))
+	}
+	return b.String()
+}
+
+type compareCallsVisitor struct {
+	calls []*ast.CallNode
+}
+
+func (v *compareCallsVisitor) Visit(node *ast.Node) {
+	c, ok := (*node).(*ast.CallNode)
+	if !ok {
+		return
+	}
+	if _, ok := diffFuncNames[nodeValue(c.Callee)]; ok {
+		v.calls = append(v.calls, c)
+	}
+}
+
 func trimComment(cond string) string {
 	const commentToken = "#"
 	trimed := []string{}
@@ -170,6 +232,118 @@ func trimComment(cond string) string {
 	return strings.Join(trimed, "\n")
 }
 
+// replaceContainsFuncCall - replace function calls of `contains(...)` with the registered function name.
+// `contains` used as an operator ( e.g. `'abc' contains 'b'` ) is not replaced.
+func replaceContainsFuncCall(e string) string {
+	const op = "contains"
+	if !strings.Contains(e, op) {
+		return e
+	}
+	tokens, err := lexer.Lex(file.NewSource(e))
+	if err != nil {
+		return e
+	}
+	lines := strings.Split(e, "\n")
+	rlines := make([][]rune, len(lines))
+	for i, l := range lines {
+		rlines[i] = []rune(l)
+	}
+	// replace from the end so that the columns of the remaining tokens are not shifted
+	for i := len(tokens) - 1; i >= 0; i-- {
+		t := tokens[i]
+		if !t.Is(lexer.Operator, op) {
+			continue
+		}
+		if i+1 >= len(tokens) || !tokens[i+1].Is(lexer.Bracket, "(") {
+			continue
+		}
+		if i > 0 {
+			p := tokens[i-1]
+			if p.Kind != lexer.Operator && !p.Is(lexer.Bracket, "(", "[", "{") {
+				// operand before `contains`, so it is the operator
+				continue
+			}
+		}
+		li := t.Line - 1
+		if li < 0 || li >= len(rlines) || t.Column+len(op) > len(rlines[li]) || string(rlines[li][t.Column:t.Column+len(op)]) != op {
+			return e
+		}
+		rlines[li] = append(append(append([]rune{}, rlines[li][:t.Column]...), []rune(containsFuncName)...), rlines[li][t.Column+len(op):]...)
+	}
+	replaced := make([]string, len(rlines))
+	for i, l := range rlines {
+		replaced[i] = string(l)
+	}
+	return strings.Join(replaced, "\n")
+}
+
+// splitCond splits the condition into the operands of the top-level `&&` ( `and` ).
+// If the condition has the top-level operators with lower precedence such as `||`, it is not split.
+func splitCond(cond string) []string {
+	cond = strings.TrimSpace(trimComment(cond))
+	tokens, err := lexer.Lex(file.NewSource(cond))
+	if err != nil {
+		return []string{cond}
+	}
+	lines := strings.Split(cond, "\n")
+	// rune offset of the head of each line
+	heads := make([]int, len(lines))
+	offset := 0
+	for i, l := range lines {
+		heads[i] = offset
+		offset += len([]rune(l)) + 1
+	}
+	type span struct{ start, end int }
+	seps := []span{}
+	depth := 0
+	for _, t := range tokens {
+		switch {
+		case t.Is(lexer.Bracket, "(", "[", "{"):
+			depth++
+		case t.Is(lexer.Bracket, ")", "]", "}"):
+			depth--
+		case depth > 0 || t.Kind != lexer.Operator:
+		case t.Is(lexer.Operator, "||", "or", "?", "??"):
+			return []string{cond}
+		case t.Is(lexer.Operator, "&&", "and"):
+			if t.Line-1 >= len(heads) {
+				return []string{cond}
+			}
+			start := heads[t.Line-1] + t.Column
+			seps = append(seps, span{start, start + len(t.Value)})
+		}
+	}
+	rc := []rune(cond)
+	conds := []string{}
+	prev := 0
+	for _, sep := range append(seps, span{len(rc), len(rc)}) {
+		if sep.end > len(rc) {
+			return []string{cond}
+		}
+		c := strings.TrimSpace(string(rc[prev:sep.start]))
+		if c == "" {
+			return []string{cond}
+		}
+		conds = append(conds, c)
+		prev = sep.end
+	}
+	return conds
+}
+
+// isLiteral returns true if the expression is a literal such as `"alice"` or `200`.
+func isLiteral(e string) bool {
+	t, err := parser.Parse(e)
+	if err != nil {
+		return false
+	}
+	switch t.Node.(type) {
+	case *ast.BoolNode, *ast.StringNode, *ast.IntegerNode, *ast.FloatNode, *ast.NilNode:
+		return true
+	default:
+		return false
+	}
+}
+
 func values(cond string) ([]string, error) {
 	t, err := parser.Parse(cond)
 	if err != nil {
@@ -273,7 +447,11 @@ func callNode(c *ast.CallNode) []string {
 		args = append(args, vs[0])
 		argValues = append(argValues, vs[1:]...)
 	}
-	values := []string{fmt.Sprintf("%s(%s)", nodeValue(c.Callee), strings.Join(args, ", "))}
+	callee := nodeValue(c.Callee)
+	if callee == containsFuncName {
+		callee = "contains"
+	}
+	values := []string{fmt.Sprintf("%s(%s)", callee, strings.Join(args, ", "))}
 	return append(append(values, args...), argValues...)
 }
 
@@ -295,3 +473,39 @@ func builtinNode(b *ast.BuiltinNode) []string {
 func closureNode(c *ast.ClosureNode) string {
 	return fmt.Sprintf("{ %s }", nodeValue(c.Node))
 }
+
+// normalizeJSONNumber converts json.Number in the decoded JSON value into int64 ( or float64 if it is not an integer ) so that expr can compare it as a number.
+// The maps and slices are converted in place.
+func normalizeJSONNumber(v interface{}) (interface{}, error) {
+	switch vv := v.(type) {
+	case json.Number:
+		if i, err := vv.Int64(); err == nil {
+			return i, nil
+		}
+		f, err := vv.Float64()
+		if err != nil {
+			return nil, fmt.Errorf("invalid number in JSON: %s: %w", vv, err)
+		}
+		return f, nil
+	case map[string]interface{}:
+		for k, vvv := range vv {
+			nv, err := normalizeJSONNumber(vvv)
+			if err != nil {
+				return nil, err
+			}
+			vv[k] = nv
+		}
+		return vv, nil
+	case []interface{}:
+		for i, vvv := range vv {
+			nv, err := normalizeJSONNumber(vvv)
+			if err != nil {
+				return nil, err
+			}
+			vv[i] = nv
+		}
+		return vv, nil
+	default:
+		return v, nil
+	}
+}
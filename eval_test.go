@@ -1,6 +1,7 @@
 package runn
 
 import (
+	"encoding/json"
 	"fmt"
 	"testing"
 
@@ -204,6 +205,26 @@ func TestEvalCond(t *testing.T) {
 	}
 }
 
+func TestReplaceContainsFuncCall(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"contains(current.res.body, {status: 'ok'})", "__contains(current.res.body, {status: 'ok'})"},
+		{"current.res.body.name contains 'ali'", "current.res.body.name contains 'ali'"},
+		{"'contains' contains ('con')", "'contains' contains ('con')"},
+		{"!contains(a, {b: 'contains'}) && x", "!__contains(a, {b: 'contains'}) && x"},
+		{"a == 1\n&& contains(a, [1])\n&& 'ab' contains 'a'", "a == 1\n&& __contains(a, [1])\n&& 'ab' contains 'a'"},
+		{"len('こんにちは') == 5 && contains(a, b)", "len('こんにちは') == 5 && __contains(a, b)"},
+	}
+	for _, tt := range tests {
+		got := replaceContainsFuncCall(tt.in)
+		if got != tt.want {
+			t.Errorf("got %v\nwant %v", got, tt.want)
+		}
+	}
+}
+
 func TestTrimComment(t *testing.T) {
 	tests := []struct {
 		in   string
@@ -263,3 +284,70 @@ func TestTrimComment(t *testing.T) {
 		})
 	}
 }
+
+func TestSplitCond(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{"current.res.status == 200", []string{"current.res.status == 200"}},
+		{
+			`current.res.status == 200
+# This is comment
+&& current.res.body.foo == 'a && b'
+and len(filter(current.res.body.items, {.id > 0 && .id < 3})) == 2`,
+			[]string{"current.res.status == 200", "current.res.body.foo == 'a && b'", "len(filter(current.res.body.items, {.id > 0 && .id < 3})) == 2"},
+		},
+		{"(vars.a == 1 || vars.b == 2) && vars.c == 3", []string{"(vars.a == 1 || vars.b == 2)", "vars.c == 3"}},
+		{"vars.a == 1 && vars.b == 2 || vars.c == 3", []string{"vars.a == 1 && vars.b == 2 || vars.c == 3"}},
+		{"vars.a ? vars.b == 1 && vars.c : false", []string{"vars.a ? vars.b == 1 && vars.c : false"}},
+		{"vars.name == 'ああ' && vars.b == 'いい'", []string{"vars.name == 'ああ'", "vars.b == 'いい'"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got := splitCond(tt.in)
+			if diff := cmp.Diff(got, tt.want, nil); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestNormalizeJSONNumber(t *testing.T) {
+	tests := []struct {
+		in      interface{}
+		want    interface{}
+		wantErr bool
+	}{
+		{json.Number("9007199254740993"), int64(9007199254740993), false},
+		{json.Number("1.5"), float64(1.5), false},
+		{"9007199254740993", "9007199254740993", false},
+		{
+			map[string]interface{}{"id": json.Number("1"), "name": "alice"},
+			map[string]interface{}{"id": int64(1), "name": "alice"},
+			false,
+		},
+		{
+			[]interface{}{map[string]interface{}{"tags": []interface{}{json.Number("2")}}},
+			[]interface{}{map[string]interface{}{"tags": []interface{}{int64(2)}}},
+			false,
+		},
+		{
+			map[string]interface{}{"id": 1},
+			map[string]interface{}{"id": 1},
+			false,
+		},
+		{json.Number("1e400"), nil, true},
+		{[]interface{}{json.Number("x")}, nil, true},
+	}
+	for _, tt := range tests {
+		got, err := normalizeJSONNumber(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("got error %v", err)
+			continue
+		}
+		if diff := cmp.Diff(got, tt.want, nil); diff != "" {
+			t.Errorf("%s", diff)
+		}
+	}
+}
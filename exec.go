@@ -3,14 +3,23 @@ package runn
 import (
 	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
 	"strings"
 
 	"github.com/cli/safeexec"
 	"github.com/k1LoW/exec"
+	"golang.org/x/crypto/ssh"
 )
 
 const execRunnerKey = "exec"
 
+// execOutputAsJSON - decode stdout as JSON ( `outputAs: json` ).
+const execOutputAsJSON = "json"
+
 const (
 	execStoreStdoutKey   = "stdout"
 	execStoreStderrKey   = "stderr"
@@ -24,6 +33,12 @@ type execRunner struct {
 type execCommand struct {
 	command string
 	stdin   string
+	// format to decode stdout into structured data ( `json` )
+	outputAs string
+	// environment variables merged onto the current environment for the command only
+	env map[string]string
+	// key of the SSH runner to run the command on the remote host
+	remote string
 }
 
 func newExecRunner(o *operator) (*execRunner, error) {
@@ -33,16 +48,28 @@ func newExecRunner(o *operator) (*execRunner, error) {
 }
 
 func (rnr *execRunner) Run(ctx context.Context, c *execCommand) error {
+	if c.remote != "" {
+		return rnr.runRemote(ctx, c)
+	}
 	stdout := new(bytes.Buffer)
 	stderr := new(bytes.Buffer)
 
 	rnr.operator.capturers.captureExecCommand(c.command)
+	if len(c.env) > 0 {
+		rnr.operator.capturers.captureExecEnv(c.env)
+	}
 
 	sh, err := safeexec.LookPath("sh")
 	if err != nil {
 		return err
 	}
 	cmd := exec.CommandContext(ctx, sh, "-c", c.command)
+	if len(c.env) > 0 {
+		cmd.Env = os.Environ()
+		for _, k := range rnr.envKeys(c) {
+			cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%s", k, c.env[k]))
+		}
+	}
 	if strings.Trim(c.stdin, " \n") != "" {
 		cmd.Stdin = strings.NewReader(c.stdin)
 
@@ -52,13 +79,104 @@ func (rnr *execRunner) Run(ctx context.Context, c *execCommand) error {
 	cmd.Stderr = stderr
 	_ = cmd.Run()
 
+	rnr.record(c, stdout, stderr, cmd.ProcessState.ExitCode())
+	return nil
+}
+
+// runRemote runs the command on the remote host connected by the SSH runner in the new session.
+func (rnr *execRunner) runRemote(ctx context.Context, c *execCommand) error {
+	sr, ok := rnr.operator.sshRunners[c.remote]
+	if !ok {
+		return fmt.Errorf("invalid remote: %s is not SSH Runner", c.remote)
+	}
+	stdout := new(bytes.Buffer)
+	stderr := new(bytes.Buffer)
+
+	rnr.operator.capturers.captureExecCommand(c.command)
+	if len(c.env) > 0 {
+		rnr.operator.capturers.captureExecEnv(c.env)
+	}
+
+	sess, err := sr.client.NewSession()
+	if err != nil {
+		return err
+	}
+	defer sess.Close()
+	command := c.command
+	if len(c.env) > 0 {
+		// set by the shell because sshd often refuses the environment variables of the session ( AcceptEnv )
+		exports := []string{}
+		for _, k := range rnr.envKeys(c) {
+			exports = append(exports, fmt.Sprintf("export %s=%s", k, shellQuote(c.env[k])))
+		}
+		command = fmt.Sprintf("%s\n%s", strings.Join(exports, "\n"), command)
+	}
+	if strings.Trim(c.stdin, " \n") != "" {
+		sess.Stdin = strings.NewReader(c.stdin)
+
+		rnr.operator.capturers.captureExecStdin(c.stdin)
+	}
+	sess.Stdout = stdout
+	sess.Stderr = stderr
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			_ = sess.Close()
+		case <-done:
+		}
+	}()
+	exitCode := 0
+	if err := sess.Run(command); err != nil {
+		var ee *ssh.ExitError
+		switch {
+		case errors.As(err, &ee):
+			exitCode = ee.ExitStatus()
+		case ctx.Err() != nil:
+			return ctx.Err()
+		default:
+			return fmt.Errorf("failed to run the command on %s: %w", c.remote, err)
+		}
+	}
+
+	rnr.record(c, stdout, stderr, exitCode)
+	return nil
+}
+
+// envKeys returns the sorted keys of the environment variables of the command.
+func (rnr *execRunner) envKeys(c *execCommand) []string {
+	keys := make([]string, 0, len(c.env))
+	for k := range c.env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (rnr *execRunner) record(c *execCommand, stdout, stderr *bytes.Buffer, exitCode int) {
 	rnr.operator.capturers.captureExecStdout(stdout.String())
 	rnr.operator.capturers.captureExecStderr(stderr.String())
 
+	var out interface{} = stdout.String()
+	if c.outputAs == execOutputAsJSON {
+		var v interface{}
+		if err := json.Unmarshal(stdout.Bytes(), &v); err != nil {
+			rnr.operator.Warnf("Failed to decode stdout as JSON, so it is recorded as a string: %v\n", err)
+		} else {
+			out = v
+		}
+	}
+
 	rnr.operator.record(map[string]interface{}{
-		string(execStoreStdoutKey):   stdout.String(),
+		string(execStoreStdoutKey):   out,
 		string(execStoreStderrKey):   stderr.String(),
-		string(execStoreExitCodeKey): cmd.ProcessState.ExitCode(),
+		string(execStoreExitCodeKey): exitCode,
 	})
-	return nil
+}
+
+// shellQuote quotes the string for POSIX shells.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
 }
@@ -1,34 +1,65 @@
 package runn
 
 import (
+	"bytes"
 	"context"
+	"io"
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/k1LoW/runn/testutil"
 )
 
 func TestExecRun(t *testing.T) {
 	tests := []struct {
-		command string
-		stdin   string
-		want    map[string]interface{}
+		command  string
+		stdin    string
+		outputAs string
+		env      map[string]string
+		want     map[string]interface{}
 	}{
-		{"echo hello!!", "", map[string]interface{}{
+		{"echo hello!!", "", "", nil, map[string]interface{}{
 			"stdout":    "hello!!\n",
 			"stderr":    "",
 			"exit_code": 0,
 			"run":       true,
 		}},
-		{"cat", "hello!!", map[string]interface{}{
+		{"cat", "hello!!", "", nil, map[string]interface{}{
 			"stdout":    "hello!!",
 			"stderr":    "",
 			"exit_code": 0,
 			"run":       true,
 		}},
+		{`echo '{"name": "alice", "ids": [1, 2]}'`, "", "json", nil, map[string]interface{}{
+			"stdout":    map[string]interface{}{"name": "alice", "ids": []interface{}{float64(1), float64(2)}},
+			"stderr":    "",
+			"exit_code": 0,
+			"run":       true,
+		}},
+		{"echo hello!!", "", "json", nil, map[string]interface{}{
+			"stdout":    "hello!!\n",
+			"stderr":    "",
+			"exit_code": 0,
+			"run":       true,
+		}},
+		{"echo $RUNN_TEST_TOKEN $RUNN_TEST_HOME", "", "", map[string]string{"RUNN_TEST_TOKEN": "abc"}, map[string]interface{}{
+			"stdout":    "abc home\n",
+			"stderr":    "",
+			"exit_code": 0,
+			"run":       true,
+		}},
+		{"echo $RUNN_TEST_HOME", "", "", map[string]string{"RUNN_TEST_HOME": "overridden"}, map[string]interface{}{
+			"stdout":    "overridden\n",
+			"stderr":    "",
+			"exit_code": 0,
+			"run":       true,
+		}},
 	}
+	t.Setenv("RUNN_TEST_HOME", "home")
 	ctx := context.Background()
 	for _, tt := range tests {
-		o, err := New()
+		o, err := New(Stderr(io.Discard))
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -36,7 +67,7 @@ func TestExecRun(t *testing.T) {
 		if err != nil {
 			t.Fatal(err)
 		}
-		c := &execCommand{command: tt.command, stdin: tt.stdin}
+		c := &execCommand{command: tt.command, stdin: tt.stdin, outputAs: tt.outputAs, env: tt.env}
 		if err := r.Run(ctx, c); err != nil {
 			t.Error(err)
 			return
@@ -47,3 +78,103 @@ func TestExecRun(t *testing.T) {
 		}
 	}
 }
+
+func TestExecRunRemote(t *testing.T) {
+	tests := []struct {
+		command  string
+		stdin    string
+		outputAs string
+		env      map[string]string
+		want     map[string]interface{}
+	}{
+		{"echo hello!!", "", "", nil, map[string]interface{}{
+			"stdout":    "hello!!\n",
+			"stderr":    "",
+			"exit_code": 0,
+			"run":       true,
+		}},
+		{"cat", "hello!!", "", nil, map[string]interface{}{
+			"stdout":    "hello!!",
+			"stderr":    "",
+			"exit_code": 0,
+			"run":       true,
+		}},
+		{"echo error >&2; exit 3", "", "", nil, map[string]interface{}{
+			"stdout":    "",
+			"stderr":    "error\n",
+			"exit_code": 3,
+			"run":       true,
+		}},
+		{`echo '{"name": "alice"}'`, "", "json", nil, map[string]interface{}{
+			"stdout":    map[string]interface{}{"name": "alice"},
+			"stderr":    "",
+			"exit_code": 0,
+			"run":       true,
+		}},
+		{"echo $RUNN_TEST_TOKEN", "", "", map[string]string{"RUNN_TEST_TOKEN": "it's a token"}, map[string]interface{}{
+			"stdout":    "it's a token\n",
+			"stderr":    "",
+			"exit_code": 0,
+			"run":       true,
+		}},
+	}
+	ctx := context.Background()
+	client := testutil.SSHServer(t)
+	for _, tt := range tests {
+		t.Run(tt.command, func(t *testing.T) {
+			o, err := New(SSHRunner("sc", client), Stderr(io.Discard))
+			if err != nil {
+				t.Fatal(err)
+			}
+			r, err := newExecRunner(o)
+			if err != nil {
+				t.Fatal(err)
+			}
+			c := &execCommand{command: tt.command, stdin: tt.stdin, outputAs: tt.outputAs, env: tt.env, remote: "sc"}
+			if err := r.Run(ctx, c); err != nil {
+				t.Fatal(err)
+			}
+			got := o.store.steps[0]
+			if diff := cmp.Diff(got, tt.want, nil); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+
+	t.Run("remote is not SSH Runner", func(t *testing.T) {
+		o, err := New()
+		if err != nil {
+			t.Fatal(err)
+		}
+		r, err := newExecRunner(o)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := r.Run(ctx, &execCommand{command: "hostname", remote: "unknown"}); err == nil {
+			t.Error("want error")
+		}
+	})
+}
+
+func TestExecRunEnvDebug(t *testing.T) {
+	stderr := new(bytes.Buffer)
+	o, err := New(Debug(true), Stderr(stderr))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := newExecRunner(o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &execCommand{command: "echo $RUNN_TEST_TOKEN", env: map[string]string{"RUNN_TEST_TOKEN": "s3cr3t"}}
+	if err := r.Run(context.Background(), c); err != nil {
+		t.Fatal(err)
+	}
+	got := stderr.String()
+	if !strings.Contains(got, "RUNN_TEST_TOKEN="+maskedValue) {
+		t.Errorf("the env should be printed: %s", got)
+	}
+	if strings.Contains(got, "RUNN_TEST_TOKEN=s3cr3t") {
+		t.Errorf("the value of env should be masked: %s", got)
+	}
+}
@@ -0,0 +1,122 @@
+package runn
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+)
+
+// PrintExpandedBook - Print the steps of the runbook expanded with the initial store ( vars, secrets and so on ) as YAML without running them.
+// Steps that depend on the results of prior steps or fail to be expanded are printed as they are with the annotation.
+func (o *operator) PrintExpandedBook(w io.Writer) error {
+	b, err := yaml.Marshal(yaml.MapSlice{{Key: descSectionKey, Value: o.desc}})
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "%ssteps:\n", string(b)); err != nil {
+		return err
+	}
+	for i, s := range o.steps {
+		if s.teardown != "" {
+			continue
+		}
+		o.stepIdx = i
+		note := ""
+		ms := yaml.MapSlice{}
+		if s.desc != "" {
+			ms = append(ms, yaml.MapItem{Key: descSectionKey, Value: s.desc})
+		}
+		if s.ifCond != "" {
+			ms = append(ms, yaml.MapItem{Key: ifSectionKey, Value: s.ifCond})
+		}
+		if k, req := s.runnerRequest(); k != "" {
+			switch {
+			case s.dependent:
+				note = "not expanded because it depends on the results of prior steps"
+			case s.loop != nil:
+				note = "not expanded because it is a loop step"
+			default:
+				// undefined variables are expanded to empty silently without StrictVars
+				err := o.findUndefinedVarBeforeRecord(req)
+				if err == nil {
+					req, err = o.expandBeforeRecord(req)
+				}
+				if err != nil {
+					note = fmt.Sprintf("not expanded: %s", strings.ReplaceAll(err.Error(), "\n", " "))
+				}
+			}
+			ms = append(ms, yaml.MapItem{Key: k, Value: req})
+		}
+		if s.testCond != "" {
+			ms = append(ms, yaml.MapItem{Key: testRunnerKey, Value: s.testCond})
+		}
+		b, err := yaml.Marshal(ms)
+		if err != nil {
+			return err
+		}
+		lines := []string{}
+		if note != "" {
+			lines = append(lines, fmt.Sprintf("  # %s", note))
+		}
+		if o.useMap {
+			lines = append(lines, fmt.Sprintf("  %s:", s.key))
+		} else {
+			lines = append(lines, "  -")
+		}
+		for _, l := range strings.Split(strings.TrimSuffix(string(b), "\n"), "\n") {
+			lines = append(lines, "    "+l)
+		}
+		if _, err := fmt.Fprintln(w, strings.Join(lines, "\n")); err != nil {
+			return err
+		}
+	}
+	o.stepIdx = 0
+	return nil
+}
+
+// findUndefinedVarBeforeRecord returns the error if undefined variables are referenced in "{{ }}" of in.
+func (o *operator) findUndefinedVarBeforeRecord(in interface{}) error {
+	store := o.store.toMap()
+	store[storeIncludedKey] = o.included
+	store[storePreviousKey] = o.store.latest()
+	return findUndefinedVar(o.store.resolveRelativeIndex(in), store)
+}
+
+// runnerRequest returns the key of the runner and the request of the step.
+func (s *step) runnerRequest() (string, interface{}) {
+	switch {
+	case s.httpRunner != nil && s.httpRequest != nil:
+		return s.runnerKey, s.httpRequest
+	case s.dbRunner != nil && s.dbQuery != nil:
+		return s.runnerKey, s.dbQuery
+	case s.grpcRunner != nil && s.grpcRequest != nil:
+		return s.runnerKey, s.grpcRequest
+	case s.cdpRunner != nil && s.cdpActions != nil:
+		return s.runnerKey, s.cdpActions
+	case s.sshRunner != nil && s.sshCommand != nil:
+		return s.runnerKey, s.sshCommand
+	case s.execRunner != nil && s.execCommand != nil:
+		return s.runnerKey, s.execCommand
+	case s.pingRunner != nil && s.pingTargets != nil:
+		return s.runnerKey, s.pingTargets
+	case s.waitRunner != nil && s.waitConfig != nil:
+		return s.runnerKey, s.waitConfig
+	case s.customRunner != nil && s.customRequest != nil:
+		return s.runnerKey, s.customRequest
+	case s.includeRunner != nil && s.includeConfig != nil:
+		c := map[string]interface{}{"path": s.includeConfig.path}
+		if len(s.includeConfig.vars) > 0 {
+			c["vars"] = s.includeConfig.vars
+		}
+		return s.runnerKey, c
+	case s.callRunner != nil && s.callConfig != nil:
+		c := map[string]interface{}{"name": s.callConfig.name}
+		if len(s.callConfig.args) > 0 {
+			c["args"] = s.callConfig.args
+		}
+		return s.runnerKey, c
+	}
+	return "", nil
+}
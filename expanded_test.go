@@ -0,0 +1,46 @@
+package runn
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestPrintExpandedBook(t *testing.T) {
+	o, err := New(Book("testdata/expanded.yml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf := new(bytes.Buffer)
+	if err := o.PrintExpandedBook(buf); err != nil {
+		t.Fatal(err)
+	}
+	want := `desc: Expanded book
+steps:
+  -
+    desc: Login
+    req:
+      /login:
+        post:
+          body:
+            application/json:
+              username: alice
+    test: current.res.status == 200
+  # not expanded because it depends on the results of prior steps
+  -
+    req:
+      /users/{{ vars.id }}:
+        get:
+          body: null
+          headers:
+            Authorization: Bearer {{ steps[0].res.body.token }}
+  # not expanded: undefined variable 'unknown' in '{{unknown}}'
+  -
+    exec:
+      command: echo {{ unknown }}
+`
+	if diff := cmp.Diff(buf.String(), want); diff != "" {
+		t.Error(diff)
+	}
+}
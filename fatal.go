@@ -0,0 +1,3 @@
+package runn
+
+const fatalSectionKey = "fatal"
@@ -21,38 +21,57 @@ var intRe = regexp.MustCompile(`^\-?[0-9]+$`)
 var floatRe = regexp.MustCompile(`^\-?[0-9.]+$`)
 
 type Flags struct {
-	Debug           bool     `usage:"debug"`
-	FailFast        bool     `usage:"fail fast"`
-	SkipTest        bool     `usage:"skip \"test:\" section"`
-	SkipIncluded    bool     `usage:"skip running the included runbook by itself"`
-	GRPCNoTLS       bool     `usage:"disable TLS use in all gRPC runners"`
-	CaptureDir      string   `usage:"destination of runbook run capture results"`
-	Vars            []string `usage:"set var to runbook (\"key:value\")"`
-	Runners         []string `usage:"set runner to runbook (\"key:dsn\")"`
-	Overlays        []string `usage:"overlay values on the runbook"`
-	Underlays       []string `usage:"lay values under the runbook"`
-	Sample          int      `usage:"sample the specified number of runbooks"`
-	Shuffle         string   `usage:"randomize the order of running runbooks (\"on\",\"off\",N)"`
-	Concurrent      string   `usage:"run runbooks concurrently (\"on\",\"off\",N)"`
-	ShardIndex      int      `usage:"index of distributed runbooks"`
-	ShardN          int      `usage:"number of shards for distributing runbooks"`
-	Random          int      `usage:"run the specified number of runbooks at random"`
-	Desc            string   `usage:"description of runbook"`
-	Out             string   `usage:"target path of runbook"`
-	Format          string   `usage:"format of result output"`
-	AndRun          bool     `usage:"run created runbook and capture the response for test"`
-	LoadTConcurrent int      `usage:"number of concurrent load test runs"`
-	LoadTDuration   string   `usage:"load test running duration"`
-	LoadTWarmUp     string   `usage:"warn-up time for load test"`
-	LoadTThreshold  string   `usage:"if this threshold condition is not met, loadt command returns exit status 1 (EXIT_FAILURE)"`
-	Profile         bool     `usage:"profile runs of runbooks"`
-	ProfileOut      string   `usage:"profile output path"`
-	ProfileDepth    int      `usage:"depth of profile"`
-	ProfileUnit     string   `usage:"-"`
-	ProfileSort     string   `usage:"-"`
-	CacheDir        string   `usage:"specify cache directory for remote runbooks"`
-	RetainCacheDir  bool     `usage:"retain cache directory for remote runbooks"`
-	Verbose         bool     `usage:"verbose"`
+	Debug             bool     `usage:"debug"`
+	Trace             bool     `usage:"trace requests and responses of runners"`
+	FailFast          bool     `usage:"fail fast"`
+	SkipTest          bool     `usage:"skip \"test:\" section"`
+	SkipIncluded      bool     `usage:"skip running the included runbook by itself"`
+	GRPCNoTLS         bool     `usage:"disable TLS use in all gRPC runners"`
+	StrictVars        bool     `usage:"fail when undefined variables are referenced in \"{{ }}\""`
+	StrictKeys        bool     `usage:"fail when steps have unknown keys"`
+	CollectExprErrors bool     `usage:"continue past expr evaluation errors of steps and report all of them"`
+	NamespaceBinds    bool     `usage:"namespace the values bound by \"bind:\" under the step key ( bound.<step key>.<name> )"`
+	UseJSONNumber     bool     `usage:"decode numbers in JSON response bodies as json.Number to keep the precision of large integers"`
+	CaptureDir        string   `usage:"destination of runbook run capture results"`
+	Checkpoint        string   `usage:"write the progress of runbooks that passed to the checkpoint file"`
+	ResumeFrom        string   `usage:"skip runbooks that passed in the checkpoint file"`
+	Baseline          string   `usage:"compare the elapsed time of each step with the baseline file"`
+	UpdateBaseline    bool     `usage:"write the elapsed time of each step to the baseline file"`
+	BaselineTolerance float64  `usage:"allowed ratio of slowdown from the baseline ( 0.2: fail if more than 20% slower )"`
+	TraceFile         string   `usage:"record the run to the trace file ( JSON Lines )"`
+	CorrelationID     string   `usage:"header to set the correlation ID generated per runbook in every HTTP request"`
+	Dataset           string   `usage:"run runbooks once per record of the dataset ( CSV or JSON )"`
+	DatasetKey        string   `usage:"column of the dataset naming each record"`
+	Vars              []string `usage:"set var to runbook (\"key:value\")"`
+	Runners           []string `usage:"set runner to runbook (\"key:dsn\")"`
+	Overlays          []string `usage:"overlay values on the runbook"`
+	Underlays         []string `usage:"lay values under the runbook"`
+	Sample            int      `usage:"sample the specified number of runbooks"`
+	SampleSeed        string   `usage:"seed for sampling runbooks ( default: random )"`
+	Shuffle           string   `usage:"randomize the order of running runbooks (\"on\",\"off\",N)"`
+	ShuffleSteps      string   `usage:"randomize the order of running independent steps (\"on\",\"off\",N)"`
+	Concurrent        string   `usage:"run runbooks concurrently (\"on\",\"off\",N)"`
+	ShardIndex        int      `usage:"index of distributed runbooks"`
+	ShardN            int      `usage:"number of shards for distributing runbooks"`
+	Random            int      `usage:"run the specified number of runbooks at random"`
+	Desc              string   `usage:"description of runbook"`
+	Out               string   `usage:"target path of runbook"`
+	Format            string   `usage:"format of result output"`
+	IncludeStore      bool     `usage:"include the store of runbooks in the result output ( --format json )"`
+	Template          string   `usage:"output the result using the Go text/template ( e.g. \"{{ .Success }}/{{ .Total }} passed\" )"`
+	AndRun            bool     `usage:"run created runbook and capture the response for test"`
+	LoadTConcurrent   int      `usage:"number of concurrent load test runs"`
+	LoadTDuration     string   `usage:"load test running duration"`
+	LoadTWarmUp       string   `usage:"warn-up time for load test"`
+	LoadTThreshold    string   `usage:"if this threshold condition is not met, loadt command returns exit status 1 (EXIT_FAILURE)"`
+	Profile           bool     `usage:"profile runs of runbooks"`
+	ProfileOut        string   `usage:"profile output path"`
+	ProfileDepth      int      `usage:"depth of profile"`
+	ProfileUnit       string   `usage:"-"`
+	ProfileSort       string   `usage:"-"`
+	CacheDir          string   `usage:"specify cache directory for remote runbooks"`
+	RetainCacheDir    bool     `usage:"retain cache directory for remote runbooks"`
+	Verbose           bool     `usage:"verbose"`
 }
 
 func (f *Flags) ToOpts() ([]runn.Option, error) {
@@ -64,13 +83,28 @@ func (f *Flags) ToOpts() ([]runn.Option, error) {
 	)
 	opts := []runn.Option{
 		runn.Debug(f.Debug),
+		runn.Trace(f.Trace),
 		runn.SkipTest(f.SkipTest),
 		runn.SkipIncluded(f.SkipIncluded),
 		runn.GRPCNoTLS(f.GRPCNoTLS),
+		runn.StrictVars(f.StrictVars),
+		runn.StrictKeys(f.StrictKeys),
+		runn.CollectExprErrors(f.CollectExprErrors),
+		runn.NamespaceBinds(f.NamespaceBinds),
+		runn.UseJSONNumber(f.UseJSONNumber),
 		runn.Profile(f.Profile),
+		runn.IncludeStoreInJSON(f.IncludeStore),
 	}
 	if f.Sample > 0 {
-		opts = append(opts, runn.RunSample(f.Sample))
+		seed := time.Now().UnixNano()
+		if f.SampleSeed != "" {
+			s, err := strconv.ParseInt(f.SampleSeed, 10, 64)
+			if err != nil {
+				return nil, errors.New(`should be number for seed: --sample-seed`)
+			}
+			seed = s
+		}
+		opts = append(opts, runn.RunSample(f.Sample, seed))
 	}
 	if f.Shuffle != "" {
 		switch {
@@ -85,6 +119,19 @@ func (f *Flags) ToOpts() ([]runn.Option, error) {
 			opts = append(opts, runn.RunShuffle(true, seed))
 		}
 	}
+	if f.ShuffleSteps != "" {
+		switch {
+		case f.ShuffleSteps == on:
+			opts = append(opts, runn.ShuffleSteps(time.Now().UnixNano()))
+		case f.ShuffleSteps == off:
+		default:
+			seed, err := strconv.ParseInt(f.ShuffleSteps, 10, 64)
+			if err != nil {
+				return nil, errors.New(`should be "on", "off" or number for seed: --shuffle-steps`)
+			}
+			opts = append(opts, runn.ShuffleSteps(seed))
+		}
+	}
 	if f.Concurrent != "" {
 		switch {
 		case f.Concurrent == on:
@@ -146,6 +193,27 @@ func (f *Flags) ToOpts() ([]runn.Option, error) {
 	for _, u := range f.Underlays {
 		opts = append(opts, runn.Underlay(u))
 	}
+	if f.Checkpoint != "" {
+		opts = append(opts, runn.Checkpoint(f.Checkpoint))
+	}
+	if f.Dataset != "" {
+		opts = append(opts, runn.Dataset(f.Dataset))
+	}
+	if f.DatasetKey != "" {
+		opts = append(opts, runn.DatasetKey(f.DatasetKey))
+	}
+	if f.ResumeFrom != "" {
+		opts = append(opts, runn.ResumeFrom(f.ResumeFrom))
+	}
+	if f.Baseline != "" {
+		opts = append(opts, runn.Baseline(f.Baseline), runn.UpdateBaseline(f.UpdateBaseline), runn.BaselineTolerance(f.BaselineTolerance))
+	}
+	if f.TraceFile != "" {
+		opts = append(opts, runn.TraceFile(f.TraceFile))
+	}
+	if f.CorrelationID != "" {
+		opts = append(opts, runn.CorrelationID(f.CorrelationID))
+	}
 	if f.CaptureDir != "" {
 		fi, err := os.Stat(f.CaptureDir)
 		if err != nil {
@@ -41,6 +41,7 @@ require (
 	github.com/lib/pq v1.10.7
 	github.com/mattn/go-isatty v0.0.17
 	github.com/mitchellh/copystructure v1.2.0
+	github.com/ohler55/ojg v1.18.1
 	github.com/olekukonko/tablewriter v0.0.5
 	github.com/ory/dockertest/v3 v3.9.1
 	github.com/rs/xid v1.4.0
@@ -53,6 +54,7 @@ require (
 	go.uber.org/multierr v1.9.0
 	golang.org/x/crypto v0.7.0
 	golang.org/x/sync v0.1.0
+	golang.org/x/text v0.8.0
 	google.golang.org/grpc v1.53.0
 	gopkg.in/yaml.v2 v2.4.0
 	modernc.org/sqlite v1.20.4
@@ -139,7 +141,6 @@ require (
 	golang.org/x/oauth2 v0.5.0 // indirect
 	golang.org/x/sys v0.6.0 // indirect
 	golang.org/x/term v0.6.0 // indirect
-	golang.org/x/text v0.8.0 // indirect
 	golang.org/x/tools v0.6.0 // indirect
 	golang.org/x/xerrors v0.0.0-20220907171357-04be3eba64a2 // indirect
 	google.golang.org/api v0.111.0 // indirect
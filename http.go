@@ -2,15 +2,22 @@ package runn
 
 import (
 	"bytes"
+	"compress/gzip"
+	"compress/zlib"
 	"context"
+	"crypto/sha256"
 	"crypto/tls"
 	"crypto/x509"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"hash"
 	"io"
+	"mime"
 	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
+	"net/http/httptrace"
 	"net/textproto"
 	"net/url"
 	"os"
@@ -21,6 +28,8 @@ import (
 
 	"github.com/ajg/form"
 	"github.com/goccy/go-json"
+	"github.com/jhump/protoreflect/desc"
+	"golang.org/x/text/encoding/htmlindex"
 )
 
 const (
@@ -36,8 +45,31 @@ const (
 	httpStoreRawBodyKey  = "rawBody"
 	httpStoreHeaderKey   = "headers"
 	httpStoreResponseKey = "res"
+	httpStoreCookiesKey  = "cookies"
+	// for recordRedirects
+	httpStoreRedirectsKey = "redirects"
+	httpStoreURLKey       = "url"
+	// for saveBody and checksum
+	httpStoreContentLengthKey = "contentLength"
+	httpStoreContentTypeKey   = "contentType"
+	// only for TLS connections
+	httpStoreTLSKey = "tls"
+	// time to first byte and total time of the response ( milliseconds )
+	httpStoreTTFBKey  = "ttfb"
+	httpStoreTotalKey = "total"
+	// size of the response body counted in `maxResponseBytesTotal:`
+	httpStoreBytesKey = "bytes"
+	// for pre
+	httpPreRequestKey = "request"
+	httpPreHeadersKey = "headers"
 )
 
+// httpChecksumSHA256 - algorithm of `checksum:` that records the SHA-256 of the response body to `res.sha256`.
+const httpChecksumSHA256 = "sha256"
+
+// defaultMaxRedirects - maximum number of redirects to follow when the redirects are checked by runn ( the same as net/http ).
+const defaultMaxRedirects = 10
+
 var notFollowRedirectFn = func(req *http.Request, via []*http.Request) error {
 	return http.ErrUseLastResponse
 }
@@ -50,9 +82,17 @@ type httpRunner struct {
 	operator          *operator
 	validator         httpValidator
 	multipartBoundary string
-	cacert            []byte
-	cert              []byte
-	key               []byte
+	// content type to encode the body declared without the content type key
+	defaultContentType string
+	// base path prepended to the path of every request ( `basePath:` )
+	basePath string
+	// headers of the runner set in every request ( overridden by the headers of the step )
+	headers map[string]string
+	cacert  []byte
+	cert    []byte
+	key     []byte
+	// parsed .proto files to encode and decode the bodies in Protocol Buffers
+	protos []*desc.FileDescriptor
 }
 
 type httpRequest struct {
@@ -61,6 +101,26 @@ type httpRequest struct {
 	headers   map[string]string
 	mediaType string
 	body      interface{}
+	// body is declared without the media type key ( e.g. `body: {name: alice}` )
+	bareBody bool
+	// path to save the raw response body to
+	saveBody string
+	// algorithm to calculate the checksum of the raw response body streamed without recording it
+	checksum string
+	// do not fail on HTTP error status even if FailOnHTTPError is enabled
+	allowError bool
+	// expression evaluated before sending the request
+	pre string
+	// do not decompress and transcode the response body to UTF-8
+	skipDecodeBody bool
+	// follow redirects or not ( nil: follow the setting of the runner )
+	followRedirects *bool
+	// maximum number of redirects to follow ( 0: default of net/http )
+	maxRedirects int
+	// record the redirect chain to `res.redirects`
+	recordRedirects bool
+	// message types of the bodies encoded in Protocol Buffers
+	protobuf *httpProtobuf
 
 	multipartWriter   *multipart.Writer
 	multipartBoundary string
@@ -95,24 +155,42 @@ func newHTTPRunnerWithHandler(name string, h http.Handler) (*httpRunner, error)
 func (r *httpRequest) validate() error {
 	switch r.method {
 	case http.MethodPost, http.MethodPatch:
-		if r.mediaType == "" {
+		// the body without mediaType is encoded with defaultContentType of the runner
+		if r.mediaType == "" && !r.bareBody {
 			return fmt.Errorf("%s method requires mediaType", r.method)
 		}
 		if r.body == nil {
 			return fmt.Errorf("%s method requires body", r.method)
 		}
 	}
-	if r.isMultipartFormDataMediaType() {
-		return nil
-	}
-	switch r.mediaType {
-	case MediaTypeApplicationJSON, MediaTypeTextPlain, MediaTypeApplicationFormUrlencoded, "":
-	default:
+	if r.mediaType != "" && !isSupportedMediaType(r.mediaType) {
 		return fmt.Errorf("unsupported mediaType: %s", r.mediaType)
 	}
 	return nil
 }
 
+// isSupportedMediaType returns whether the request body can be encoded with the media type.
+func isSupportedMediaType(mt string) bool {
+	if mt == MediaTypeMultipartFormData || strings.HasPrefix(mt, MediaTypeMultipartFormData+"; boundary=") {
+		return true
+	}
+	switch mt {
+	case MediaTypeApplicationJSON, MediaTypeTextPlain, MediaTypeApplicationFormUrlencoded, MediaTypeApplicationProtobuf:
+		return true
+	}
+	return false
+}
+
+// looksLikeMediaType returns whether the key of the body is a media type ( type/subtype ) rather than a field of the body.
+func looksLikeMediaType(k string) bool {
+	mt, _, err := mime.ParseMediaType(k)
+	if err != nil {
+		return false
+	}
+	t, st, ok := strings.Cut(mt, "/")
+	return ok && t != "" && st != "" && !strings.Contains(st, "/")
+}
+
 func (r *httpRequest) encodeBody() (io.Reader, error) {
 	if r.body == nil {
 		return nil, nil
@@ -143,6 +221,15 @@ func (r *httpRequest) encodeBody() (io.Reader, error) {
 			return nil, fmt.Errorf("invalid body: %v", r.body)
 		}
 		return strings.NewReader(s), nil
+	case MediaTypeApplicationProtobuf:
+		if r.protobuf == nil || r.protobuf.requestDesc == nil {
+			return nil, fmt.Errorf("%s requires the message type of the request ( protobuf.request )", MediaTypeApplicationProtobuf)
+		}
+		b, err := encodeProtobuf(r.protobuf.requestDesc, r.body)
+		if err != nil {
+			return nil, err
+		}
+		return bytes.NewBuffer(b), nil
 	default:
 		return nil, fmt.Errorf("unsupported mediaType: %s", r.mediaType)
 	}
@@ -237,14 +324,37 @@ func (r *httpRequest) setContentTypeHeader(req *http.Request) {
 func (rnr *httpRunner) Run(ctx context.Context, r *httpRequest) error {
 	r.multipartBoundary = rnr.multipartBoundary
 	r.root = rnr.operator.root
+	if r.bareBody && r.body != nil {
+		if rnr.defaultContentType == "" {
+			return fmt.Errorf("the body is declared without the content type key, but defaultContentType of the runner is not set: %s", rnr.name)
+		}
+		r.mediaType = rnr.defaultContentType
+	}
+	if r.protobuf != nil {
+		if err := rnr.resolveProtobuf(r.protobuf); err != nil {
+			return err
+		}
+	}
 	reqBody, err := r.encodeBody()
 	if err != nil {
 		return err
 	}
+	if r.pre != "" {
+		reqBody, err = rnr.runPre(r, reqBody)
+		if err != nil {
+			return err
+		}
+	}
 
 	var (
 		req *http.Request
 		res *http.Response
+		// redirect chain for `recordRedirects: true`
+		redirects = []interface{}{}
+		start     time.Time
+		ttfb      time.Duration
+		// size of the request body sent
+		sent int64
 	)
 	switch {
 	case rnr.client != nil:
@@ -287,7 +397,7 @@ func (rnr *httpRunner) Run(ctx context.Context, r *httpRequest) error {
 			ts.TLSClientConfig.Certificates = []tls.Certificate{cert}
 		}
 
-		u, err := mergeURL(rnr.endpoint, r.path)
+		u, err := mergeURL(rnr.endpoint, joinBasePath(rnr.basePath, r.path))
 		if err != nil {
 			return err
 		}
@@ -309,13 +419,30 @@ func (rnr *httpRunner) Run(ctx context.Context, r *httpRequest) error {
 			return err
 		}
 
-		res, err = rnr.client.Do(req)
+		sent = req.ContentLength
+		client := rnr.clientFor(r, &redirects)
+		start = time.Now()
+		req = req.WithContext(httptrace.WithClientTrace(req.Context(), &httptrace.ClientTrace{
+			GotFirstResponseByte: func() {
+				// the response of the last request when following redirects
+				ttfb = time.Since(start)
+			},
+		}))
+		res, err = client.Do(req)
 		if err != nil {
+			if r.recordRedirects && len(redirects) > 0 {
+				// record the redirect chain even if following the redirects failed ( e.g. redirect loops )
+				rnr.operator.record(map[string]interface{}{
+					string(httpStoreResponseKey): map[string]interface{}{
+						httpStoreRedirectsKey: redirects,
+					},
+				})
+			}
 			return err
 		}
 		defer res.Body.Close()
 	case rnr.handler != nil:
-		req = httptest.NewRequest(r.method, r.path, reqBody)
+		req = httptest.NewRequest(r.method, joinBasePath(rnr.basePath, r.path), reqBody)
 		if r.mediaType != "" {
 			req.Header.Set("Content-Type", r.mediaType)
 		}
@@ -328,8 +455,12 @@ func (rnr *httpRunner) Run(ctx context.Context, r *httpRequest) error {
 		if err := rnr.validator.ValidateRequest(ctx, req); err != nil {
 			return err
 		}
+		sent = req.ContentLength
 		w := httptest.NewRecorder()
+		start = time.Now()
 		rnr.handler.ServeHTTP(w, req)
+		// the response of the handler is received at once
+		ttfb = time.Since(start)
 		res = w.Result()
 		defer res.Body.Close()
 	default:
@@ -337,6 +468,9 @@ func (rnr *httpRunner) Run(ctx context.Context, r *httpRequest) error {
 	}
 
 	rnr.operator.capturers.captureHTTPResponse(rnr.name, res)
+	if o := rnr.operator; o.stepIdx < len(o.steps) {
+		o.steps[o.stepIdx].httpReq = req
+	}
 
 	if err := rnr.validator.ValidateResponse(ctx, req, res); err != nil {
 		var target *UnsupportedError
@@ -347,33 +481,397 @@ func (rnr *httpRunner) Run(ctx context.Context, r *httpRequest) error {
 		}
 	}
 
+	d := map[string]interface{}{}
+	d[httpStoreStatusKey] = res.StatusCode
+	d[httpStoreCookiesKey] = parseResponseCookies(res)
+	if r.recordRedirects {
+		d[httpStoreRedirectsKey] = redirects
+		// the final URL resolved by following the redirects
+		d[httpStoreURLKey] = res.Request.URL.String()
+	}
+	if res.TLS != nil {
+		d[httpStoreTLSKey] = tlsConnectionStateToMap(res.TLS)
+	}
+
+	if r.saveBody != "" || r.checksum != "" {
+		var (
+			body io.Reader = res.Body
+			h    hash.Hash
+			n    int64
+		)
+		if r.checksum != "" {
+			h = sha256.New()
+		}
+		if r.saveBody != "" {
+			if h != nil {
+				body = io.TeeReader(body, h)
+			}
+			n, err = r.saveResponseBody(body)
+		} else {
+			n, err = io.Copy(h, body)
+		}
+		if err != nil {
+			return err
+		}
+		if h != nil {
+			d[r.checksum] = hex.EncodeToString(h.Sum(nil))
+		}
+		d[httpStoreContentLengthKey] = n
+		d[httpStoreContentTypeKey] = res.Header.Get("Content-Type")
+		d[httpStoreHeaderKey] = res.Header
+		rnr.recordTiming(d, ttfb, time.Since(start))
+		rnr.recordBytes(d, sent, n)
+
+		rnr.operator.record(map[string]interface{}{
+			string(httpStoreResponseKey): d,
+		})
+
+		return rnr.checkHTTPError(r, res.StatusCode, nil)
+	}
+
 	resBody, err := io.ReadAll(res.Body)
 	if err != nil {
 		return err
 	}
+	// size and type of the response body as received ( before decoding )
+	received := int64(len(resBody))
+	d[httpStoreContentLengthKey] = received
+	d[httpStoreContentTypeKey] = res.Header.Get("Content-Type")
+	if !r.skipDecodeBody {
+		resBody, err = rnr.decodeResponseBody(res, resBody)
+		if err != nil {
+			return err
+		}
+	}
 
-	d := map[string]interface{}{}
-	d[httpStoreStatusKey] = res.StatusCode
-	if strings.Contains(res.Header.Get("Content-Type"), "json") && len(resBody) > 0 {
+	switch {
+	case r.protobuf != nil && r.protobuf.responseDesc != nil && isProtobufMediaType(res.Header.Get("Content-Type")) && len(resBody) > 0:
+		b, err := decodeProtobuf(r.protobuf.responseDesc, resBody)
+		if err != nil {
+			return err
+		}
+		d[httpStoreBodyKey] = b
+	case strings.Contains(res.Header.Get("Content-Type"), "json") && len(resBody) > 0:
 		var b interface{}
-		if err := json.Unmarshal(resBody, &b); err != nil {
+		dec := json.NewDecoder(bytes.NewReader(resBody))
+		if rnr.operator.useJSONNumber {
+			dec.UseNumber()
+		}
+		if err := dec.Decode(&b); err != nil {
 			return err
 		}
+		if rnr.operator.useJSONNumber {
+			b, err = normalizeJSONNumber(b)
+			if err != nil {
+				return err
+			}
+		}
 		d[httpStoreBodyKey] = b
-	} else {
+	default:
 		d[httpStoreBodyKey] = nil
 	}
 	d[httpStoreRawBodyKey] = string(resBody)
 	d[httpStoreHeaderKey] = res.Header
+	rnr.recordTiming(d, ttfb, time.Since(start))
+	rnr.recordBytes(d, sent, received)
 
 	rnr.operator.record(map[string]interface{}{
 		string(httpStoreResponseKey): d,
 	})
 
-	return nil
+	return rnr.checkHTTPError(r, res.StatusCode, resBody)
+}
+
+// recordTiming records the time to first byte and the total time of the response ( milliseconds ) and sets the time to first byte to the current step.
+func (rnr *httpRunner) recordTiming(d map[string]interface{}, ttfb, total time.Duration) {
+	d[httpStoreTTFBKey] = milliseconds(ttfb)
+	d[httpStoreTotalKey] = milliseconds(total)
+	o := rnr.operator
+	if o.stepIdx < len(o.steps) {
+		o.steps[o.stepIdx].ttfb = ttfb
+	}
+}
+
+// recordBytes records the size of the response body as received ( before decoding ) and adds the sizes of the request and response bodies to the totals of the runbook.
+func (rnr *httpRunner) recordBytes(d map[string]interface{}, sent, received int64) {
+	if sent < 0 {
+		// unknown size of the request body
+		sent = 0
+	}
+	d[httpStoreBytesKey] = received
+	rnr.operator.addBytes(sent, received)
+}
+
+// clientFor returns the client applying the redirect settings of the request.
+// When `recordRedirects: true`, the redirect chain is appended to redirects while following redirects ( including the redirect exceeding the limit ).
+func (rnr *httpRunner) clientFor(r *httpRequest, redirects *[]interface{}) *http.Client {
+	if r.followRedirects == nil && r.maxRedirects == 0 && !r.recordRedirects {
+		return rnr.client
+	}
+	c := *rnr.client
+	base := rnr.client.CheckRedirect
+	c.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if r.followRedirects != nil && !*r.followRedirects {
+			return http.ErrUseLastResponse
+		}
+		if r.followRedirects == nil && base != nil {
+			if err := base(req, via); err != nil {
+				return err
+			}
+		}
+		if r.recordRedirects && req.Response != nil {
+			*redirects = append(*redirects, map[string]interface{}{
+				"url":      via[len(via)-1].URL.String(),
+				"status":   req.Response.StatusCode,
+				"location": req.Response.Header.Get("Location"),
+			})
+		}
+		max := r.maxRedirects
+		if max == 0 {
+			max = defaultMaxRedirects
+		}
+		if len(via) > max {
+			return fmt.Errorf("stopped after %d redirects", max)
+		}
+		return nil
+	}
+	return &c
+}
+
+// parseResponseCookies parses the Set-Cookie headers of the response into structured entries.
+func parseResponseCookies(res *http.Response) []interface{} {
+	cookies := []interface{}{}
+	for _, c := range res.Cookies() {
+		expires := ""
+		if !c.Expires.IsZero() {
+			expires = c.Expires.UTC().Format(time.RFC3339)
+		}
+		sameSite := ""
+		switch c.SameSite {
+		case http.SameSiteLaxMode:
+			sameSite = "Lax"
+		case http.SameSiteStrictMode:
+			sameSite = "Strict"
+		case http.SameSiteNoneMode:
+			sameSite = "None"
+		}
+		cookies = append(cookies, map[string]interface{}{
+			"name":     c.Name,
+			"value":    c.Value,
+			"path":     c.Path,
+			"domain":   c.Domain,
+			"expires":  expires,
+			"maxAge":   c.MaxAge,
+			"httpOnly": c.HttpOnly,
+			"secure":   c.Secure,
+			"sameSite": sameSite,
+		})
+	}
+	return cookies
+}
+
+// runPre evaluates `pre:` with the request and merges the returned values into the request.
+func (rnr *httpRunner) runPre(r *httpRequest, reqBody io.Reader) (io.Reader, error) {
+	var b []byte
+	if reqBody != nil {
+		var err error
+		b, err = io.ReadAll(reqBody)
+		if err != nil {
+			return nil, err
+		}
+		reqBody = bytes.NewReader(b)
+	}
+	headers := map[string]interface{}{}
+	for k, v := range r.headers {
+		headers[k] = v
+	}
+	store := rnr.operator.store.toMap()
+	store[httpPreRequestKey] = map[string]interface{}{
+		"method":  r.method,
+		"path":    r.path,
+		"headers": headers,
+		"body":    string(b),
+	}
+	v, err := Eval(r.pre, store)
+	if err != nil {
+		return nil, fmt.Errorf("failed to evaluate pre: %w", err)
+	}
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("invalid pre result: should be a map: %v", v)
+	}
+	for k, vv := range m {
+		switch k {
+		case httpPreHeadersKey:
+			hm, ok := vv.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("invalid pre result: invalid headers: %v", vv)
+			}
+			if r.headers == nil {
+				r.headers = map[string]string{}
+			}
+			for hk, hv := range hm {
+				r.headers[hk] = fmt.Sprintf("%v", hv)
+			}
+		default:
+			return nil, fmt.Errorf("invalid pre result: unsupported key: %s", k)
+		}
+	}
+	return reqBody, nil
+}
+
+// checkHTTPError returns error if the status is an error status ( >= 400 ) and FailOnHTTPError is enabled.
+func (rnr *httpRunner) checkHTTPError(r *httpRequest, status int, body []byte) error {
+	if !rnr.operator.failOnHTTPError || r.allowError || status < http.StatusBadRequest {
+		return nil
+	}
+	if body == nil {
+		return fmt.Errorf("%s %s returned error status %d", r.method, r.path, status)
+	}
+	return fmt.Errorf("%s %s returned error status %d: %s", r.method, r.path, status, string(body))
+}
+
+// decodeResponseBody decompresses the response body according to `Content-Encoding`
+// and transcodes it to UTF-8 according to the charset of `Content-Type` when the content type is text.
+// The body of an unsupported charset is returned as it is.
+func (rnr *httpRunner) decodeResponseBody(res *http.Response, body []byte) ([]byte, error) {
+	if len(body) == 0 {
+		return body, nil
+	}
+	if !res.Uncompressed {
+		var (
+			rc  io.ReadCloser
+			err error
+		)
+		switch strings.ToLower(res.Header.Get("Content-Encoding")) {
+		case "gzip":
+			rc, err = gzip.NewReader(bytes.NewReader(body))
+		case "deflate":
+			rc, err = zlib.NewReader(bytes.NewReader(body))
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress response body: %w", err)
+		}
+		if rc != nil {
+			defer rc.Close()
+			body, err = io.ReadAll(rc)
+			if err != nil {
+				return nil, fmt.Errorf("failed to decompress response body: %w", err)
+			}
+		}
+	}
+	ct := res.Header.Get("Content-Type")
+	if ct == "" {
+		return body, nil
+	}
+	mt, params, err := mime.ParseMediaType(ct)
+	if err != nil {
+		return body, nil
+	}
+	if !isTextMediaType(mt) {
+		return body, nil
+	}
+	cs, ok := params["charset"]
+	if !ok {
+		return body, nil
+	}
+	enc, err := htmlindex.Get(cs)
+	if err != nil {
+		rnr.operator.Warnf("Unsupported charset of response body: %s, so it is recorded as it is\n", cs)
+		return body, nil
+	}
+	if name, _ := htmlindex.Name(enc); name == "utf-8" {
+		return body, nil
+	}
+	decoded, err := enc.NewDecoder().Bytes(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode response body as %s: %w", cs, err)
+	}
+	return decoded, nil
+}
+
+func isTextMediaType(mt string) bool {
+	if strings.HasPrefix(mt, "text/") {
+		return true
+	}
+	for _, t := range []string{"json", "xml", "javascript", MediaTypeApplicationFormUrlencoded} {
+		if strings.Contains(mt, t) {
+			return true
+		}
+	}
+	return false
+}
+
+// saveResponseBody writes the raw response body to the path of `saveBody:` (relative to the root of the runbook).
+func (r *httpRequest) saveResponseBody(body io.Reader) (int64, error) {
+	p := fp(r.saveBody, r.root)
+	if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		return 0, fmt.Errorf("failed to save response body to %s: %w", p, err)
+	}
+	f, err := os.Create(p)
+	if err != nil {
+		return 0, fmt.Errorf("failed to save response body to %s: %w", p, err)
+	}
+	n, err := io.Copy(f, body)
+	if err != nil {
+		_ = f.Close()
+		return 0, fmt.Errorf("failed to save response body to %s: %w", p, err)
+	}
+	if err := f.Close(); err != nil {
+		return 0, fmt.Errorf("failed to save response body to %s: %w", p, err)
+	}
+	return n, nil
+}
+
+// tlsConnectionStateToMap returns the details of the TLS connection and the certificate presented by the server.
+func tlsConnectionStateToMap(cs *tls.ConnectionState) map[string]interface{} {
+	m := map[string]interface{}{
+		"version":     tlsVersionName(cs.Version),
+		"cipherSuite": tls.CipherSuiteName(cs.CipherSuite),
+		"serverName":  cs.ServerName,
+	}
+	if len(cs.PeerCertificates) == 0 {
+		return m
+	}
+	cert := cs.PeerCertificates[0]
+	dnsNames := []interface{}{}
+	for _, n := range cert.DNSNames {
+		dnsNames = append(dnsNames, n)
+	}
+	ipAddresses := []interface{}{}
+	for _, ip := range cert.IPAddresses {
+		ipAddresses = append(ipAddresses, ip.String())
+	}
+	m["subject"] = cert.Subject.String()
+	m["issuer"] = cert.Issuer.String()
+	m["serialNumber"] = cert.SerialNumber.String()
+	m["notBefore"] = cert.NotBefore
+	m["notAfter"] = cert.NotAfter
+	m["dnsNames"] = dnsNames
+	m["ipAddresses"] = ipAddresses
+	return m
+}
+
+func tlsVersionName(v uint16) string {
+	switch v {
+	case tls.VersionTLS10:
+		return "TLS 1.0"
+	case tls.VersionTLS11:
+		return "TLS 1.1"
+	case tls.VersionTLS12:
+		return "TLS 1.2"
+	case tls.VersionTLS13:
+		return "TLS 1.3"
+	default:
+		return fmt.Sprintf("0x%04X", v)
+	}
 }
 
 func mergeURL(u *url.URL, p string) (*url.URL, error) {
+	if strings.HasPrefix(p, "https://") || strings.HasPrefix(p, "http://") {
+		// the full URL is requested as it is
+		return url.Parse(p)
+	}
 	if !strings.HasPrefix(p, "/") {
 		return nil, fmt.Errorf("invalid path: %s", p)
 	}
@@ -385,7 +883,10 @@ func mergeURL(u *url.URL, p string) (*url.URL, error) {
 	if err != nil {
 		return nil, err
 	}
+	rawPath := path.Join(m.EscapedPath(), a.EscapedPath())
 	m.Path = path.Join(m.Path, a.Path)
+	// keep the escaped path ( e.g. `%2F` in path parameters )
+	m.RawPath = rawPath
 	q := u.Query()
 	for k, vs := range a.Query() {
 		for _, v := range vs {
@@ -396,3 +897,68 @@ func mergeURL(u *url.URL, p string) (*url.URL, error) {
 
 	return m, nil
 }
+
+// joinBasePath prepends the base path to the path of the request without doubling the slashes.
+// The path starting with `//` ( requested as the path without the leading slash ) and the full URL bypass the base path.
+func joinBasePath(basePath, p string) string {
+	switch {
+	case basePath == "":
+		return p
+	case strings.HasPrefix(p, "//"):
+		return strings.TrimPrefix(p, "/")
+	case strings.HasPrefix(p, "https://") || strings.HasPrefix(p, "http://"):
+		return p
+	}
+	bp := strings.Trim(basePath, "/")
+	if bp == "" {
+		return p
+	}
+	return "/" + bp + "/" + strings.TrimPrefix(p, "/")
+}
+
+// setDefaultHTTPHeaders - set the headers of the runner, the headers of HTTPHeaders and the correlation ID that are not set in the step.
+func (o *operator) setDefaultHTTPHeaders(req *httpRequest, rnr *httpRunner) error {
+	for _, h := range []map[string]string{rnr.headers, o.httpHeaders} {
+		if len(h) == 0 {
+			continue
+		}
+		hi := map[string]interface{}{}
+		for k, v := range h {
+			hi[k] = v
+		}
+		e, err := o.expandBeforeRecord(hi)
+		if err != nil {
+			return err
+		}
+		eh, ok := e.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("invalid headers: %v", e)
+		}
+		if req.headers == nil {
+			req.headers = map[string]string{}
+		}
+		for k, v := range eh {
+			if hasHeader(req.headers, k) {
+				continue
+			}
+			req.headers[k] = fmt.Sprintf("%v", v)
+		}
+	}
+	if o.correlationIDHeader != "" && !hasHeader(req.headers, o.correlationIDHeader) {
+		if req.headers == nil {
+			req.headers = map[string]string{}
+		}
+		req.headers[o.correlationIDHeader] = o.correlationID
+	}
+	return nil
+}
+
+// hasHeader - whether the header is set ( case-insensitive ).
+func hasHeader(h map[string]string, key string) bool {
+	for k := range h {
+		if http.CanonicalHeaderKey(k) == http.CanonicalHeaderKey(key) {
+			return true
+		}
+	}
+	return false
+}
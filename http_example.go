@@ -0,0 +1,149 @@
+package runn
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"sort"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/google/go-cmp/cmp"
+)
+
+// AssertResponseExample - Assert that the response body of the step recorded in the last run matches the example declared in the OpenAPI3 document of the HTTP Runner.
+// The example is looked up by the operation and the status of the response ( or `default` ), and is one of `example:` or `examples:` of the media type,
+// or `example:` or `default:` of the schema. It fails with the diff if the response body matches none of the examples.
+// It is available only for the HTTP Runner with the OpenAPI3 document ( `openapi3:` ).
+func (o *operator) AssertResponseExample(stepKey string) error {
+	var s *step
+	for _, ss := range o.steps {
+		if ss.key == stepKey {
+			s = ss
+			break
+		}
+	}
+	if s == nil {
+		return fmt.Errorf("step not found: %s", stepKey)
+	}
+	if s.httpRunner == nil {
+		return fmt.Errorf("steps.%s is not a step of HTTP Runner", stepKey)
+	}
+	v, ok := s.httpRunner.validator.(*openApi3Validator)
+	if !ok {
+		return fmt.Errorf("the HTTP Runner '%s' of steps.%s has no OpenAPI3 document", s.httpRunner.name, stepKey)
+	}
+	if s.httpReq == nil {
+		return fmt.Errorf("the request of steps.%s is not recorded", stepKey)
+	}
+	res, err := o.recordedRes(stepKey)
+	if err != nil {
+		return err
+	}
+	status, _ := res[httpStoreStatusKey].(int)
+	header, _ := res[httpStoreHeaderKey].(http.Header)
+	examples, err := v.responseExamples(s.httpReq, status, header.Get("Content-Type"))
+	if err != nil {
+		return fmt.Errorf("failed to find the example for steps.%s: %w", stepKey, err)
+	}
+	if len(examples) == 0 {
+		return fmt.Errorf("no example is declared for the response of steps.%s (%s %s %d)", stepKey, s.httpReq.Method, s.httpReq.URL.Path, status)
+	}
+	got, err := normalizeExample(res[httpStoreBodyKey])
+	if err != nil {
+		return err
+	}
+	var diff string
+	for _, e := range examples {
+		want, err := normalizeExample(e)
+		if err != nil {
+			return err
+		}
+		d := cmp.Diff(want, got)
+		if d == "" {
+			return nil
+		}
+		if diff == "" {
+			diff = d
+		}
+	}
+	return fmt.Errorf("the response of steps.%s does not match the example of %s %s (%d) (-example +response):\n%s", stepKey, s.httpReq.Method, s.httpReq.URL.Path, status, diff)
+}
+
+// responseExamples returns the examples of the response declared for the operation of the request and the status.
+func (v *openApi3Validator) responseExamples(req *http.Request, status int, contentType string) ([]interface{}, error) {
+	input, err := v.requestInput(req)
+	if err != nil {
+		return nil, err
+	}
+	op := input.Route.Operation
+	ref := op.Responses.Get(status)
+	if ref == nil {
+		ref = op.Responses.Default()
+	}
+	if ref == nil || ref.Value == nil {
+		return nil, fmt.Errorf("no response is declared for the status %d", status)
+	}
+	mt := ref.Value.Content.Get(contentType)
+	if mt == nil && contentType != "" {
+		if m, _, err := mime.ParseMediaType(contentType); err == nil {
+			mt = ref.Value.Content.Get(m)
+		}
+	}
+	if mt == nil {
+		if len(ref.Value.Content) != 1 {
+			return nil, fmt.Errorf("no content is declared for %q of the status %d", contentType, status)
+		}
+		for _, c := range ref.Value.Content {
+			mt = c
+		}
+	}
+	return mediaTypeExamples(mt), nil
+}
+
+// mediaTypeExamples returns `example:` or the values of `examples:` of the media type, or `example:` or `default:` of the schema.
+func mediaTypeExamples(mt *openapi3.MediaType) []interface{} {
+	if mt.Example != nil {
+		return []interface{}{mt.Example}
+	}
+	if len(mt.Examples) > 0 {
+		names := make([]string, 0, len(mt.Examples))
+		for k := range mt.Examples {
+			names = append(names, k)
+		}
+		sort.Strings(names)
+		var examples []interface{}
+		for _, k := range names {
+			e := mt.Examples[k]
+			if e == nil || e.Value == nil || e.Value.Value == nil {
+				continue
+			}
+			examples = append(examples, e.Value.Value)
+		}
+		if len(examples) > 0 {
+			return examples
+		}
+	}
+	if mt.Schema != nil && mt.Schema.Value != nil {
+		if mt.Schema.Value.Example != nil {
+			return []interface{}{mt.Schema.Value.Example}
+		}
+		if mt.Schema.Value.Default != nil {
+			return []interface{}{mt.Schema.Value.Default}
+		}
+	}
+	return nil
+}
+
+// normalizeExample normalizes the value to compare as JSON ( e.g. numbers are float64 ).
+func normalizeExample(v interface{}) (interface{}, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var n interface{}
+	if err := json.Unmarshal(b, &n); err != nil {
+		return nil, err
+	}
+	return n, nil
+}
@@ -0,0 +1,59 @@
+package runn
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/k1LoW/runn/testutil"
+)
+
+func TestAssertResponseExample(t *testing.T) {
+	tests := []struct {
+		path    string
+		openapi bool
+		wantErr string
+	}{
+		{"/users", true, ""},
+		{"/users/1", true, "does not match the example of GET /users/1 (200)"},
+		{"/private", true, ""},
+		{"/users", false, "has no OpenAPI3 document"},
+	}
+	ts := testutil.HTTPServer(t)
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			opts := []httpRunnerOption{}
+			if tt.openapi {
+				opts = append(opts, OpenApi3("testdata/openapi3_examples.yml"))
+			}
+			o, err := New(HTTPRunner("req", ts.URL, ts.Client(), opts...))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := o.AppendStep("0", map[string]interface{}{
+				"req": map[string]interface{}{
+					tt.path: map[string]interface{}{
+						"get": map[string]interface{}{
+							"body": nil,
+						},
+					},
+				},
+			}); err != nil {
+				t.Fatal(err)
+			}
+			if err := o.Run(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+			err = o.AssertResponseExample("0")
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Error(err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("got %v\nwant %v", err, tt.wantErr)
+			}
+		})
+	}
+}
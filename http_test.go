@@ -2,19 +2,29 @@ package runn
 
 import (
 	"bytes"
+	"compress/gzip"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/goccy/go-yaml"
 	"github.com/google/go-cmp/cmp"
 	"github.com/k1LoW/runn/testutil"
+	"golang.org/x/text/encoding/japanese"
 )
 
 func TestHTTPRunnerRunUsingGitHubAPI(t *testing.T) {
@@ -351,6 +361,9 @@ func TestMergeURL(t *testing.T) {
 	}{
 		{"https://git.example.com/api/v3", "/orgs/octokit/repos", "https://git.example.com/api/v3/orgs/octokit/repos"},
 		{"https://git.example.com/api/v3", "/repos/vmg/redcarpet/issues?state=closed", "https://git.example.com/api/v3/repos/vmg/redcarpet/issues?state=closed"},
+		{"https://git.example.com/api/v3", "/orgs/k1LoW%2Frunn/users/a%20b", "https://git.example.com/api/v3/orgs/k1LoW%2Frunn/users/a%20b"},
+		{"https://git.example.com/api/v3/", "/users/1/", "https://git.example.com/api/v3/users/1"},
+		{"https://git.example.com/api/v3", "https://other.example.com/users?page=2", "https://other.example.com/users?page=2"},
 	}
 	for _, tt := range tests {
 		u, err := url.Parse(tt.endpoint)
@@ -368,6 +381,67 @@ func TestMergeURL(t *testing.T) {
 	}
 }
 
+func TestJoinBasePath(t *testing.T) {
+	tests := []struct {
+		basePath string
+		path     string
+		want     string
+	}{
+		{"", "/users", "/users"},
+		{"/api/v2", "/users", "/api/v2/users"},
+		{"/api/v2/", "/users", "/api/v2/users"},
+		{"api/v2", "/users", "/api/v2/users"},
+		{"/api/v2", "/", "/api/v2/"},
+		{"/api/v2", "/users?page=2", "/api/v2/users?page=2"},
+		{"/", "/users", "/users"},
+		{"/api/v2", "//users", "/users"},
+		{"/api/v2", "https://other.example.com/users", "https://other.example.com/users"},
+	}
+	for _, tt := range tests {
+		got := joinBasePath(tt.basePath, tt.path)
+		if got != tt.want {
+			t.Errorf("joinBasePath(%q, %q) = %q, want %q", tt.basePath, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestHTTPRunnerBasePath(t *testing.T) {
+	tests := []struct {
+		path string
+		want int
+	}{
+		{"/1", http.StatusOK},
+		{"//private", http.StatusForbidden},
+	}
+	ts := testutil.HTTPServer(t)
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			o, err := New(HTTPRunner("req", ts.URL, ts.Client(), BasePath("/users")))
+			if err != nil {
+				t.Fatal(err)
+			}
+			r, ok := o.httpRunners["req"]
+			if !ok {
+				t.Fatal("runner not found")
+			}
+			req := &httpRequest{
+				path:   tt.path,
+				method: http.MethodGet,
+			}
+			if err := r.Run(context.Background(), req); err != nil {
+				t.Fatal(err)
+			}
+			res, ok := o.store.latest()[httpStoreResponseKey].(map[string]interface{})
+			if !ok {
+				t.Fatalf("invalid res: %#v", o.store.latest())
+			}
+			if got := res[httpStoreStatusKey].(int); got != tt.want {
+				t.Errorf("got %v\nwant %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestHTTPRunnerWithHandler(t *testing.T) {
 	tests := []struct {
 		req         *httpRequest
@@ -594,3 +668,1085 @@ func TestHTTPRunnerInitializeWithCerts(t *testing.T) {
 		})
 	}
 }
+
+func TestHTTPRunnerSaveBody(t *testing.T) {
+	pdf := []byte("%PDF-1.4\x00\x01\x02\xff")
+	s := http.NewServeMux()
+	s.HandleFunc("/report.pdf", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/pdf")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(pdf)
+	})
+	ctx := context.Background()
+	o, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	o.root = t.TempDir()
+	r, err := newHTTPRunnerWithHandler("req", s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.operator = o
+	req := &httpRequest{
+		path:     "/report.pdf",
+		method:   http.MethodGet,
+		headers:  map[string]string{},
+		saveBody: "out/report.pdf",
+	}
+	if err := r.Run(ctx, req); err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile(filepath.Join(o.root, "out", "report.pdf"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(got, pdf, nil); diff != "" {
+		t.Errorf("%s", diff)
+	}
+	res, ok := o.store.latest()["res"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("invalid res: %#v", o.store.latest()["res"])
+	}
+	if got, want := res["contentLength"], int64(len(pdf)); got != want {
+		t.Errorf("got %v\nwant %v", got, want)
+	}
+	if got, want := res["contentType"], "application/pdf"; got != want {
+		t.Errorf("got %v\nwant %v", got, want)
+	}
+	if _, ok := res["rawBody"]; ok {
+		t.Error("rawBody should not be recorded")
+	}
+}
+
+func TestHTTPRunnerChecksum(t *testing.T) {
+	archive := bytes.Repeat([]byte("runn\x00\xff"), 1024)
+	sum := sha256.Sum256(archive)
+	want := hex.EncodeToString(sum[:])
+	s := http.NewServeMux()
+	s.HandleFunc("/archive.tar.gz", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/gzip")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(archive)
+	})
+	tests := []struct {
+		saveBody string
+	}{
+		{""},
+		{"out/archive.tar.gz"},
+	}
+	ctx := context.Background()
+	for _, tt := range tests {
+		t.Run(tt.saveBody, func(t *testing.T) {
+			o, err := New()
+			if err != nil {
+				t.Fatal(err)
+			}
+			o.root = t.TempDir()
+			r, err := newHTTPRunnerWithHandler("req", s)
+			if err != nil {
+				t.Fatal(err)
+			}
+			r.operator = o
+			req := &httpRequest{
+				path:     "/archive.tar.gz",
+				method:   http.MethodGet,
+				headers:  map[string]string{},
+				saveBody: tt.saveBody,
+				checksum: "sha256",
+			}
+			if err := r.Run(ctx, req); err != nil {
+				t.Fatal(err)
+			}
+			res, ok := o.store.latest()["res"].(map[string]interface{})
+			if !ok {
+				t.Fatalf("invalid res: %#v", o.store.latest()["res"])
+			}
+			if got := res["sha256"]; got != want {
+				t.Errorf("got %v\nwant %v", got, want)
+			}
+			if got, want := res["contentLength"], int64(len(archive)); got != want {
+				t.Errorf("got %v\nwant %v", got, want)
+			}
+			if _, ok := res["rawBody"]; ok {
+				t.Error("rawBody should not be recorded")
+			}
+			if tt.saveBody != "" {
+				got, err := os.ReadFile(filepath.Join(o.root, tt.saveBody))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !bytes.Equal(got, archive) {
+					t.Error("the saved body is different from the response body")
+				}
+			}
+		})
+	}
+}
+
+func TestHTTPRunnerContentLengthAndType(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00")
+	s := http.NewServeMux()
+	s.HandleFunc("/image.png", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(png)
+	})
+	s.HandleFunc("/empty", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	tests := []struct {
+		path              string
+		wantContentLength int64
+		wantContentType   string
+		cond              string
+	}{
+		{"/image.png", int64(len(png)), "image/png", `current.res.contentLength >= 8 && current.res.contentType == "image/png"`},
+		{"/empty", 0, "", `current.res.contentLength == 0 && current.res.contentType == ""`},
+	}
+	ctx := context.Background()
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			o, err := New(Stderr(io.Discard))
+			if err != nil {
+				t.Fatal(err)
+			}
+			r, err := newHTTPRunnerWithHandler("req", s)
+			if err != nil {
+				t.Fatal(err)
+			}
+			r.operator = o
+			req := &httpRequest{
+				path:    tt.path,
+				method:  http.MethodGet,
+				headers: map[string]string{},
+			}
+			if err := r.Run(ctx, req); err != nil {
+				t.Fatal(err)
+			}
+			res, ok := o.store.latest()["res"].(map[string]interface{})
+			if !ok {
+				t.Fatalf("invalid res: %#v", o.store.latest()["res"])
+			}
+			if got := res["contentLength"]; got != tt.wantContentLength {
+				t.Errorf("got %v\nwant %v", got, tt.wantContentLength)
+			}
+			if got := res["contentType"]; got != tt.wantContentType {
+				t.Errorf("got %v\nwant %v", got, tt.wantContentType)
+			}
+			store := o.store.toMap()
+			store["current"] = o.store.latest()
+			tf, err := EvalCond(tt.cond, store)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !tf {
+				t.Errorf("(%s) is not true", tt.cond)
+			}
+		})
+	}
+}
+
+func TestHTTPRunnerFailOnHTTPError(t *testing.T) {
+	s := http.NewServeMux()
+	s.HandleFunc("/ok", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("ok"))
+	})
+	s.HandleFunc("/notfound", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte("page not found"))
+	})
+	tests := []struct {
+		failOnHTTPError bool
+		path            string
+		allowError      bool
+		wantErr         bool
+	}{
+		{false, "/ok", false, false},
+		{false, "/notfound", false, false},
+		{true, "/ok", false, false},
+		{true, "/notfound", false, true},
+		{true, "/notfound", true, false},
+	}
+	ctx := context.Background()
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%v %s %v", tt.failOnHTTPError, tt.path, tt.allowError), func(t *testing.T) {
+			o, err := New(FailOnHTTPError(tt.failOnHTTPError))
+			if err != nil {
+				t.Fatal(err)
+			}
+			r, err := newHTTPRunnerWithHandler("req", s)
+			if err != nil {
+				t.Fatal(err)
+			}
+			r.operator = o
+			req := &httpRequest{
+				path:       tt.path,
+				method:     http.MethodGet,
+				headers:    map[string]string{},
+				allowError: tt.allowError,
+			}
+			err = r.Run(ctx, req)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("got %v\nwantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !strings.Contains(err.Error(), "page not found") {
+				t.Errorf("error should contain the response body: %v", err)
+			}
+			if len(o.store.steps) != 1 {
+				t.Error("response should be recorded")
+			}
+		})
+	}
+}
+
+func TestHTTPRunnerDecodeBody(t *testing.T) {
+	const want = "こんにちは"
+	sjis, err := japanese.ShiftJIS.NewEncoder().String(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gz := new(bytes.Buffer)
+	zw := gzip.NewWriter(gz)
+	if _, err := zw.Write([]byte(`{"message":"` + want + `"}`)); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	s := http.NewServeMux()
+	s.HandleFunc("/sjis", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=Shift_JIS")
+		_, _ = w.Write([]byte(sjis))
+	})
+	s.HandleFunc("/utf8", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		_, _ = w.Write([]byte(want))
+	})
+	s.HandleFunc("/unknown", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=x-unknown")
+		_, _ = w.Write([]byte(sjis))
+	})
+	s.HandleFunc("/binary", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/octet-stream; charset=Shift_JIS")
+		_, _ = w.Write([]byte(sjis))
+	})
+	s.HandleFunc("/gzip", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Encoding", "gzip")
+		_, _ = w.Write(gz.Bytes())
+	})
+	tests := []struct {
+		path           string
+		skipDecodeBody bool
+		want           string
+		wantBody       interface{}
+	}{
+		{"/sjis", false, want, nil},
+		{"/sjis", true, sjis, nil},
+		{"/utf8", false, want, nil},
+		{"/unknown", false, sjis, nil},
+		{"/binary", false, sjis, nil},
+		{"/gzip", false, `{"message":"` + want + `"}`, map[string]interface{}{"message": want}},
+	}
+	ctx := context.Background()
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s %v", tt.path, tt.skipDecodeBody), func(t *testing.T) {
+			o, err := New()
+			if err != nil {
+				t.Fatal(err)
+			}
+			r, err := newHTTPRunnerWithHandler("req", s)
+			if err != nil {
+				t.Fatal(err)
+			}
+			r.operator = o
+			req := &httpRequest{
+				path:           tt.path,
+				method:         http.MethodGet,
+				headers:        map[string]string{},
+				skipDecodeBody: tt.skipDecodeBody,
+			}
+			if err := r.Run(ctx, req); err != nil {
+				t.Fatal(err)
+			}
+			res, ok := o.store.steps[0][httpStoreResponseKey].(map[string]interface{})
+			if !ok {
+				t.Fatalf("invalid res: %#v", o.store.steps[0])
+			}
+			if got := res[httpStoreRawBodyKey]; got != tt.want {
+				t.Errorf("got %v\nwant %v", got, tt.want)
+			}
+			if diff := cmp.Diff(res[httpStoreBodyKey], tt.wantBody, nil); diff != "" {
+				t.Errorf("%s", diff)
+			}
+		})
+	}
+}
+
+func TestHTTPRunnerOmitBody(t *testing.T) {
+	var (
+		gotBody        string
+		gotContentType string
+	)
+	s := http.NewServeMux()
+	s.HandleFunc("/users", func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		gotBody = string(b)
+		gotContentType = r.Header.Get("Content-Type")
+		w.WriteHeader(http.StatusOK)
+	})
+	tests := []struct {
+		in              string
+		wantBody        string
+		wantContentType string
+	}{
+		{
+			`
+/users:
+  get:
+    body:
+      application/json:
+        key: value
+`,
+			"",
+			"",
+		},
+		{
+			`
+/users:
+  get:
+    forceBody: true
+    body:
+      application/json:
+        key: value
+`,
+			`{"key":"value"}`,
+			MediaTypeApplicationJSON,
+		},
+	}
+	ctx := context.Background()
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			var v map[string]interface{}
+			if err := yaml.Unmarshal([]byte(tt.in), &v); err != nil {
+				t.Fatal(err)
+			}
+			req, err := parseHTTPRequest(v)
+			if err != nil {
+				t.Fatal(err)
+			}
+			o, err := New()
+			if err != nil {
+				t.Fatal(err)
+			}
+			r, err := newHTTPRunnerWithHandler("req", s)
+			if err != nil {
+				t.Fatal(err)
+			}
+			r.operator = o
+			if err := r.Run(ctx, req); err != nil {
+				t.Fatal(err)
+			}
+			if gotBody != tt.wantBody {
+				t.Errorf("got %v\nwant %v", gotBody, tt.wantBody)
+			}
+			if gotContentType != tt.wantContentType {
+				t.Errorf("got %v\nwant %v", gotContentType, tt.wantContentType)
+			}
+		})
+	}
+}
+
+func TestHTTPRunnerDefaultContentType(t *testing.T) {
+	var (
+		gotBody        string
+		gotContentType string
+	)
+	s := http.NewServeMux()
+	s.HandleFunc("/users", func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		gotBody = string(b)
+		gotContentType = r.Header.Get("Content-Type")
+		w.WriteHeader(http.StatusOK)
+	})
+	tests := []struct {
+		in                 string
+		defaultContentType string
+		wantBody           string
+		wantContentType    string
+		wantErr            bool
+	}{
+		{
+			`
+/users:
+  post:
+    body:
+      key: value
+`,
+			MediaTypeApplicationJSON,
+			`{"key":"value"}`,
+			MediaTypeApplicationJSON,
+			false,
+		},
+		{
+			`
+/users:
+  post:
+    body:
+      key: value
+`,
+			MediaTypeApplicationFormUrlencoded,
+			`key=value`,
+			MediaTypeApplicationFormUrlencoded,
+			false,
+		},
+		{
+			`
+/users:
+  post:
+    body:
+      application/x-www-form-urlencoded:
+        key: value
+`,
+			MediaTypeApplicationJSON,
+			`key=value`,
+			MediaTypeApplicationFormUrlencoded,
+			false,
+		},
+		{
+			`
+/users:
+  post:
+    body:
+      key: value
+`,
+			"",
+			"",
+			"",
+			true,
+		},
+	}
+	ctx := context.Background()
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			gotBody = ""
+			gotContentType = ""
+			var v map[string]interface{}
+			if err := yaml.Unmarshal([]byte(tt.in), &v); err != nil {
+				t.Fatal(err)
+			}
+			req, err := parseHTTPRequest(v)
+			if err != nil {
+				t.Fatal(err)
+			}
+			o, err := New()
+			if err != nil {
+				t.Fatal(err)
+			}
+			r, err := newHTTPRunnerWithHandler("req", s)
+			if err != nil {
+				t.Fatal(err)
+			}
+			r.operator = o
+			r.defaultContentType = tt.defaultContentType
+			if err := r.Run(ctx, req); err != nil {
+				if !tt.wantErr {
+					t.Error(err)
+				}
+				return
+			}
+			if tt.wantErr {
+				t.Error("want error")
+			}
+			if gotBody != tt.wantBody {
+				t.Errorf("got %v\nwant %v", gotBody, tt.wantBody)
+			}
+			if gotContentType != tt.wantContentType {
+				t.Errorf("got %v\nwant %v", gotContentType, tt.wantContentType)
+			}
+		})
+	}
+}
+
+func TestHTTPRunnerPre(t *testing.T) {
+	sign := func(body string) string {
+		return fmt.Sprintf("signed:%s", body)
+	}
+	s := http.NewServeMux()
+	s.HandleFunc("/echo", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Signature", r.Header.Get("X-Signature"))
+		w.Header().Set("X-Method", r.Header.Get("X-Method"))
+		w.WriteHeader(http.StatusOK)
+	})
+	tests := []struct {
+		pre     string
+		want    string
+		wantErr bool
+	}{
+		{
+			"{'headers': {'X-Signature': sign(request.body), 'X-Method': request.method}}",
+			`signed:{"key":"value"}`,
+			false,
+		},
+		{"{}", "", false},
+		{"'invalid'", "", true},
+		{"{'path': '/other'}", "", true},
+	}
+	ctx := context.Background()
+	for _, tt := range tests {
+		t.Run(tt.pre, func(t *testing.T) {
+			o, err := New(Func("sign", sign))
+			if err != nil {
+				t.Fatal(err)
+			}
+			r, err := newHTTPRunnerWithHandler("req", s)
+			if err != nil {
+				t.Fatal(err)
+			}
+			r.operator = o
+			req := &httpRequest{
+				path:      "/echo",
+				method:    http.MethodPost,
+				headers:   map[string]string{},
+				mediaType: MediaTypeApplicationJSON,
+				body:      map[string]interface{}{"key": "value"},
+				pre:       tt.pre,
+			}
+			if err := r.Run(ctx, req); err != nil {
+				if !tt.wantErr {
+					t.Error(err)
+				}
+				return
+			}
+			if tt.wantErr {
+				t.Error("want error")
+			}
+			res, ok := o.store.latest()["res"].(map[string]interface{})
+			if !ok {
+				t.Fatalf("invalid res: %#v", o.store.latest()["res"])
+			}
+			h, ok := res["headers"].(http.Header)
+			if !ok {
+				t.Fatalf("invalid headers: %#v", res["headers"])
+			}
+			if got := h.Get("X-Signature"); got != tt.want {
+				t.Errorf("got %v\nwant %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHTTPRunnerRedirect(t *testing.T) {
+	s := http.NewServeMux()
+	s.HandleFunc("/a", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/b", http.StatusFound)
+	})
+	s.HandleFunc("/b", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/c", http.StatusMovedPermanently)
+	})
+	s.HandleFunc("/c", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	s.HandleFunc("/loop", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/loop", http.StatusFound)
+	})
+	ts := httptest.NewServer(s)
+	t.Cleanup(ts.Close)
+	loop := map[string]interface{}{"url": ts.URL + "/loop", "status": http.StatusFound, "location": "/loop"}
+	tests := []struct {
+		in            string
+		wantStatus    int
+		wantLocation  string
+		wantRedirects []interface{}
+		wantURL       string
+		wantErr       bool
+	}{
+		{
+			`
+/a:
+  get:
+    body: null
+`,
+			http.StatusOK,
+			"",
+			nil,
+			"",
+			false,
+		},
+		{
+			`
+/a:
+  get:
+    followRedirects: false
+    body: null
+`,
+			http.StatusFound,
+			"/b",
+			nil,
+			"",
+			false,
+		},
+		{
+			`
+/a:
+  get:
+    maxRedirects: 1
+    body: null
+`,
+			0,
+			"",
+			nil,
+			"",
+			true,
+		},
+		{
+			`
+/a:
+  get:
+    maxRedirects: 2
+    recordRedirects: true
+    body: null
+`,
+			http.StatusOK,
+			"",
+			[]interface{}{
+				map[string]interface{}{"url": ts.URL + "/a", "status": http.StatusFound, "location": "/b"},
+				map[string]interface{}{"url": ts.URL + "/b", "status": http.StatusMovedPermanently, "location": "/c"},
+			},
+			ts.URL + "/c",
+			false,
+		},
+		{
+			`
+/loop:
+  get:
+    maxRedirects: 2
+    recordRedirects: true
+    body: null
+`,
+			0,
+			"",
+			[]interface{}{loop, loop, loop},
+			"",
+			true,
+		},
+		{
+			`
+/loop:
+  get:
+    recordRedirects: true
+    body: null
+`,
+			0,
+			"",
+			[]interface{}{loop, loop, loop, loop, loop, loop, loop, loop, loop, loop, loop},
+			"",
+			true,
+		},
+	}
+	ctx := context.Background()
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			var v map[string]interface{}
+			if err := yaml.Unmarshal([]byte(tt.in), &v); err != nil {
+				t.Fatal(err)
+			}
+			req, err := parseHTTPRequest(v)
+			if err != nil {
+				t.Fatal(err)
+			}
+			o, err := New()
+			if err != nil {
+				t.Fatal(err)
+			}
+			r, err := newHTTPRunner("req", ts.URL)
+			if err != nil {
+				t.Fatal(err)
+			}
+			r.operator = o
+			if err := r.Run(ctx, req); err != nil {
+				if !tt.wantErr {
+					t.Fatal(err)
+				}
+				if tt.wantRedirects == nil {
+					return
+				}
+				// the redirect chain is recorded even on error
+				res, ok := o.store.steps[0][httpStoreResponseKey].(map[string]interface{})
+				if !ok {
+					t.Fatalf("invalid res: %#v", o.store.steps[0])
+				}
+				if diff := cmp.Diff(res[httpStoreRedirectsKey], tt.wantRedirects, nil); diff != "" {
+					t.Errorf("%s", diff)
+				}
+				return
+			}
+			if tt.wantErr {
+				t.Fatal("want error")
+			}
+			res, ok := o.store.steps[0][httpStoreResponseKey].(map[string]interface{})
+			if !ok {
+				t.Fatalf("invalid res: %#v", o.store.steps[0])
+			}
+			if got := res[httpStoreStatusKey]; got != tt.wantStatus {
+				t.Errorf("got %v\nwant %v", got, tt.wantStatus)
+			}
+			if got := res[httpStoreHeaderKey].(http.Header).Get("Location"); got != tt.wantLocation {
+				t.Errorf("got %v\nwant %v", got, tt.wantLocation)
+			}
+			got, ok := res[httpStoreRedirectsKey]
+			if tt.wantRedirects == nil {
+				if ok {
+					t.Errorf("got %v\nwant no redirects", got)
+				}
+				return
+			}
+			if diff := cmp.Diff(got, tt.wantRedirects, nil); diff != "" {
+				t.Errorf("%s", diff)
+			}
+			if got := res[httpStoreURLKey]; got != tt.wantURL {
+				t.Errorf("got %v\nwant %v", got, tt.wantURL)
+			}
+		})
+	}
+}
+
+func TestHTTPRunnerCookies(t *testing.T) {
+	s := http.NewServeMux()
+	s.HandleFunc("/login", func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{
+			Name:     "session",
+			Value:    "xxxxx",
+			Path:     "/",
+			Domain:   "example.com",
+			Expires:  time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC),
+			MaxAge:   3600,
+			HttpOnly: true,
+			Secure:   true,
+			SameSite: http.SameSiteStrictMode,
+		})
+		http.SetCookie(w, &http.Cookie{
+			Name:  "theme",
+			Value: "dark",
+		})
+		w.WriteHeader(http.StatusOK)
+	})
+	ctx := context.Background()
+	o, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := newHTTPRunnerWithHandler("req", s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.operator = o
+	req := &httpRequest{
+		path:    "/login",
+		method:  http.MethodGet,
+		headers: map[string]string{},
+	}
+	if err := r.Run(ctx, req); err != nil {
+		t.Fatal(err)
+	}
+	res, ok := o.store.steps[0][httpStoreResponseKey].(map[string]interface{})
+	if !ok {
+		t.Fatalf("invalid res: %#v", o.store.steps[0])
+	}
+	want := []interface{}{
+		map[string]interface{}{
+			"name":     "session",
+			"value":    "xxxxx",
+			"path":     "/",
+			"domain":   "example.com",
+			"expires":  "2030-01-02T03:04:05Z",
+			"maxAge":   3600,
+			"httpOnly": true,
+			"secure":   true,
+			"sameSite": "Strict",
+		},
+		map[string]interface{}{
+			"name":     "theme",
+			"value":    "dark",
+			"path":     "",
+			"domain":   "",
+			"expires":  "",
+			"maxAge":   0,
+			"httpOnly": false,
+			"secure":   false,
+			"sameSite": "",
+		},
+	}
+	if diff := cmp.Diff(res[httpStoreCookiesKey], want, nil); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestHTTPHeaders(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h := map[string]string{}
+		for k := range r.Header {
+			h[k] = r.Header.Get(k)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(h)
+	}))
+	t.Cleanup(ts.Close)
+	o, err := New(
+		Book("testdata/http_headers.yml"),
+		HTTPRunner("req", ts.URL, ts.Client()),
+		HTTPRunner("req2", ts.URL, ts.Client(), DefaultHeaders(map[string]string{"X-Override": "runner", "X-Runner": "req2"})),
+		HTTPHeaders(map[string]string{"X-Test-Run": "{{ vars.runId }}", "X-Override": "global"}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := o.Run(context.Background()); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestCorrelationID(t *testing.T) {
+	tests := []struct {
+		name   string
+		opts   []Option
+		wantID string
+	}{
+		{"default", []Option{CorrelationID("X-Correlation-Id")}, ""},
+		{"custom generator", []Option{CorrelationID("x-correlation-id"), CorrelationIDFunc(func() (string, error) { return "test-id", nil })}, "test-id"},
+		{"vars of the user", []Option{CorrelationID("X-Correlation-Id"), Var(correlationIDVarKey, "user-id"), CorrelationIDFunc(func() (string, error) { return "test-id", nil })}, "user-id"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				got []string
+				mu  sync.Mutex
+			)
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				id := r.Header.Get("X-Correlation-Id")
+				mu.Lock()
+				got = append(got, id)
+				mu.Unlock()
+				// echo back the correlation ID
+				w.Header().Set("X-Correlation-Id", id)
+				w.WriteHeader(http.StatusOK)
+			}))
+			t.Cleanup(ts.Close)
+			t.Setenv("TEST_HTTP_END_POINT", ts.URL)
+			o, err := New(append([]Option{Book("testdata/correlation_id.yml")}, tt.opts...)...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := o.Run(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+			id, ok := o.store.vars[correlationIDVarKey].(string)
+			if !ok || id == "" {
+				t.Fatalf("the correlation ID is not stored: %v", o.store.vars)
+			}
+			if tt.wantID != "" && id != tt.wantID {
+				t.Errorf("got %v\nwant %v", id, tt.wantID)
+			}
+			want := []string{id, "overridden", id}
+			if diff := cmp.Diff(got, want); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+
+	t.Run("generator error", func(t *testing.T) {
+		_, err := New(CorrelationID("X-Correlation-Id"), CorrelationIDFunc(func() (string, error) { return "", errors.New("exhausted") }))
+		if err == nil {
+			t.Error("want error")
+		}
+	})
+
+	t.Run("empty header", func(t *testing.T) {
+		if _, err := New(CorrelationID("")); err == nil {
+			t.Error("want error")
+		}
+	})
+
+	t.Run("invalid vars of the user", func(t *testing.T) {
+		if _, err := New(CorrelationID("X-Correlation-Id"), Var(correlationIDVarKey, 1)); err == nil {
+			t.Error("want error")
+		}
+	})
+}
+
+func TestHTTPRunnerProtobuf(t *testing.T) {
+	fds, err := parseProtos([]string{"testdata/grpctest.proto"}, nil, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	reqDesc := fds[0].FindMessage("grpctest.HelloRequest")
+	resDesc := fds[0].FindMessage("grpctest.HelloResponse")
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, err := io.ReadAll(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		req, err := decodeProtobuf(reqDesc, b)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		res, err := encodeProtobuf(resDesc, map[string]interface{}{
+			"message": fmt.Sprintf("hello %s", req["name"]),
+			"num":     req["num"].(float64) * 2,
+		})
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", MediaTypeApplicationProtobuf)
+		_, _ = w.Write(res)
+	}))
+	t.Cleanup(ts.Close)
+	t.Setenv("TEST_HTTP_END_POINT", ts.URL)
+	o, err := New(Book("testdata/http_protobuf.yml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := o.Run(context.Background()); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestUseJSONNumber(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id": 9007199254740993, "price": 1.5, "tags": [{"count": 2}]}`))
+	}))
+	t.Cleanup(ts.Close)
+	t.Setenv("TEST_HTTP_END_POINT", ts.URL)
+	tests := []struct {
+		useJSONNumber bool
+		wantErr       bool
+	}{
+		{true, false},
+		{false, true},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%v", tt.useJSONNumber), func(t *testing.T) {
+			o, err := New(Book("testdata/json_number.yml"), UseJSONNumber(tt.useJSONNumber))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := o.Run(context.Background()); (err != nil) != tt.wantErr {
+				t.Errorf("got %v\nwantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestHTTPRunnerTLS(t *testing.T) {
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	ts := httptest.NewTLSServer(h)
+	t.Cleanup(ts.Close)
+	plain := httptest.NewServer(h)
+	t.Cleanup(plain.Close)
+	t.Setenv("TEST_HTTP_PLAIN_END_POINT", plain.URL)
+	o, err := New(Book("testdata/http_tls.yml"), HTTPRunner("req", ts.URL, ts.Client()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := o.Run(context.Background()); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestHTTPRunnerTiming(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(20 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("first"))
+		w.(http.Flusher).Flush()
+		time.Sleep(20 * time.Millisecond)
+		_, _ = w.Write([]byte("last"))
+	}))
+	t.Cleanup(ts.Close)
+	t.Setenv("TEST_HTTP_END_POINT", ts.URL)
+	o, err := New(Book("testdata/http_timing.yml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := o.Run(context.Background()); err != nil {
+		t.Error(err)
+	}
+	sr := o.Result().StepResults[0]
+	if sr.TTFB < 20*time.Millisecond || sr.TTFB > sr.Elapsed {
+		t.Errorf("invalid TTFB: %v ( elapsed: %v )", sr.TTFB, sr.Elapsed)
+	}
+}
+
+type headerRoundTripper struct {
+	value    string
+	requests int
+}
+
+func (rt *headerRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	rt.requests++
+	req = req.Clone(req.Context())
+	req.Header.Set("X-Round-Tripper", rt.value)
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestHTTPRoundTripper(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(fmt.Sprintf(`{"value": %q}`, r.Header.Get("X-Round-Tripper"))))
+	}))
+	t.Cleanup(ts.Close)
+	named := &headerRoundTripper{value: "named"}
+	all := &headerRoundTripper{value: "all"}
+	tests := []struct {
+		name    string
+		opts    []Option
+		want    string
+		wantErr bool
+	}{
+		{"none", nil, "", false},
+		{"named", []Option{HTTPRoundTripper("req", named)}, "named", false},
+		{"all", []Option{HTTPRoundTripperForAll(all)}, "all", false},
+		{"named overrides all", []Option{HTTPRoundTripperForAll(all), HTTPRoundTripper("req", named)}, "named", false},
+		{"runner not found", []Option{HTTPRoundTripper("unknown", named)}, "", true},
+		{"runner with handler", []Option{HTTPRunnerWithHandler("handler", http.NotFoundHandler()), HTTPRoundTripper("handler", named)}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &http.Client{}
+			opts := append([]Option{HTTPRunner("req", ts.URL, client)}, tt.opts...)
+			o, err := New(opts...)
+			if err != nil {
+				if !tt.wantErr {
+					t.Error(err)
+				}
+				return
+			}
+			if tt.wantErr {
+				t.Fatal("want error")
+			}
+			if err := o.AppendStep("0", map[string]interface{}{
+				"req":  map[string]interface{}{"/": map[string]interface{}{"get": map[string]interface{}{"body": nil}}},
+				"test": fmt.Sprintf("current.res.body.value == %q", tt.want),
+			}); err != nil {
+				t.Fatal(err)
+			}
+			if err := o.Run(context.Background()); err != nil {
+				t.Error(err)
+			}
+			if len(tt.opts) > 0 && client.Transport != nil {
+				t.Error("the client given by the user should not be changed")
+			}
+		})
+	}
+	if named.requests != 2 || all.requests != 1 {
+		t.Errorf("got %d and %d requests", named.requests, all.requests)
+	}
+}
@@ -2,6 +2,8 @@ package runn
 
 import (
 	"context"
+	"fmt"
+	"os"
 	"path/filepath"
 )
 
@@ -29,8 +31,8 @@ func (rnr *includeRunner) Run(ctx context.Context, c *includeConfig) error {
 	if rnr.operator.thisT != nil {
 		rnr.operator.thisT.Helper()
 	}
-	ibp := filepath.Join(rnr.operator.root, c.path)
-	if err := fetchFile(ibp); err != nil {
+	ibp, err := rnr.resolvePath(c.path)
+	if err != nil {
 		return err
 	}
 
@@ -70,26 +72,55 @@ func (rnr *includeRunner) Run(ctx context.Context, c *includeConfig) error {
 			oo.store.vars[k] = o
 		}
 	}
-	if err := oo.run(ctx); err != nil {
+	err = oo.run(ctx)
+	// The bytes of the included runbook are counted in the parent runbook too
+	rnr.operator.addBytes(oo.bytesSent, oo.bytesReceived)
+	if err != nil {
 		return err
 	}
 	rnr.operator.record(oo.store.toNormalizedMap())
+	rnr.operator.restoreRunners(oo)
+
+	return nil
+}
 
-	// Restore the condition of runners re-used in child runbooks.
+// restoreRunners restores the condition of runners re-used in the nested operator.
+func (o *operator) restoreRunners(oo *operator) {
 	for _, r := range oo.httpRunners {
-		r.operator = rnr.operator
+		r.operator = o
 	}
 	for _, r := range oo.dbRunners {
-		r.operator = rnr.operator
+		r.operator = o
 	}
 	for _, r := range oo.grpcRunners {
-		r.operator = rnr.operator
+		r.operator = o
 	}
 	for _, r := range oo.sshRunners {
-		r.operator = rnr.operator
+		r.operator = o
 	}
+}
 
-	return nil
+// resolvePath expands the path of the included runbook ( e.g. `{{ vars.flavor }}/login.yml` ) and returns the path from the root.
+func (rnr *includeRunner) resolvePath(path string) (string, error) {
+	e, err := rnr.operator.expandBeforeRecord(path)
+	if err != nil {
+		return "", err
+	}
+	p, ok := e.(string)
+	if !ok || p == "" {
+		return "", fmt.Errorf("invalid include path: %v (%s)", e, path)
+	}
+	ibp := filepath.Join(rnr.operator.root, p)
+	if err := fetchFile(ibp); err != nil {
+		return "", err
+	}
+	if _, err := os.Stat(ibp); err != nil {
+		if p != path {
+			return "", fmt.Errorf("included runbook does not exist: %s (resolved from %s)", ibp, path)
+		}
+		return "", fmt.Errorf("included runbook does not exist: %s", ibp)
+	}
+	return ibp, nil
 }
 
 // newNestedOperator create nested operator.
@@ -110,14 +141,41 @@ func (o *operator) newNestedOperator(parent *step, opts ...Option) (*operator, e
 	for k, r := range o.sshRunners {
 		popts = append(popts, runnSSHRunner(k, r))
 	}
+	for k, r := range o.customRunners {
+		popts = append(popts, RegisterRunner(k, r.runner))
+	}
 
 	popts = append(popts, Debug(o.debug))
+	popts = append(popts, Trace(o.trace))
 	popts = append(popts, Profile(o.profile))
 	popts = append(popts, SkipTest(o.skipTest))
 	popts = append(popts, Force(o.force))
+	popts = append(popts, FailOnHTTPError(o.failOnHTTPError))
+	popts = append(popts, HTTPHeaders(o.httpHeaders))
+	if o.correlationIDHeader != "" {
+		// share the correlation ID with the included runbook
+		id := o.correlationID
+		popts = append(popts, CorrelationID(o.correlationIDHeader), CorrelationIDFunc(func() (string, error) { return id, nil }))
+	}
+	popts = append(popts, DBMaxRows(o.dbMaxRows))
+	popts = append(popts, StrictVars(o.strictVars))
+	popts = append(popts, StrictKeys(o.strictKeys))
+	popts = append(popts, CollectExprErrors(o.collectExprErrors))
+	popts = append(popts, NamespaceBinds(o.namespaceBinds))
+	popts = append(popts, UseJSONNumber(o.useJSONNumber))
+	popts = append(popts, SkipStepLabels(o.skipStepLabels...))
+	for scheme, driverName := range o.dbDrivers {
+		popts = append(popts, RegisterDBDriver(scheme, driverName))
+	}
+	if o.color != nil {
+		popts = append(popts, Color(*o.color))
+	}
 	for k, f := range o.store.funcs {
 		popts = append(popts, Func(k, f))
 	}
+	for k, v := range o.store.secrets {
+		popts = append(popts, Secret(k, v))
+	}
 	// Prefer child runbook opts
 	opts = append(popts, opts...)
 	oo, err := New(opts...)
@@ -129,6 +187,14 @@ func (o *operator) newNestedOperator(parent *step, opts ...Option) (*operator, e
 	oo.thisT = o.thisT
 	oo.sw = o.sw
 	oo.capturers = o.capturers
+	if oo.requestCounter != nil {
+		// Count requests of the included runbook for its own `expectRequests:`, `expectNoRequests:` and `maxQueries:`
+		oo.capturers = append(append(capturers{}, o.capturers...), oo.requestCounter)
+	}
+	if len(oo.store.secrets) > len(o.store.secrets) {
+		// Mask the secrets of the included runbook too
+		oo.capturers = oo.capturers.masked(oo.secretMasker)
+	}
 	oo.parent = parent
 	oo.store.parentVars = o.store.toMap()
 	return oo, nil
@@ -4,7 +4,10 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"strings"
 	"testing"
+
+	"github.com/k1LoW/runn/testutil"
 )
 
 func TestIncludeRunnerRun(t *testing.T) {
@@ -183,3 +186,46 @@ func TestUseParentStore(t *testing.T) {
 		})
 	}
 }
+
+func TestIncludePathFromVars(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		vars    map[string]interface{}
+		wantErr string
+	}{
+		{"literal", "testdata/book/db.yml", map[string]interface{}{}, ""},
+		{"expanded", "testdata/{{ vars.dir }}/db.yml", map[string]interface{}{"dir": "book"}, ""},
+		{"not exist", "testdata/{{ vars.dir }}/db.yml", map[string]interface{}{"dir": "missing"}, "included runbook does not exist"},
+		{"not string", "{{ vars.dir }}", map[string]interface{}{"dir": 1}, "invalid include path"},
+	}
+	ctx := context.Background()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, dsn := testutil.SQLite(t)
+			opts := []Option{Runner("db", dsn)}
+			for k, v := range tt.vars {
+				opts = append(opts, Var(k, v))
+			}
+			o, err := New(opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			r, err := newIncludeRunner(o)
+			if err != nil {
+				t.Fatal(err)
+			}
+			c := &includeConfig{path: tt.path}
+			err = r.Run(ctx, c)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Error(err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("got %v\nwant %v", err, tt.wantErr)
+			}
+		})
+	}
+}
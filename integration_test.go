@@ -81,6 +81,22 @@ func TestRunUsingMySQL(t *testing.T) {
 	}
 }
 
+func TestDBValueTypesUsingMySQL(t *testing.T) {
+	db := testutil.CreateMySQLContainer(t)
+	for _, numericAs := range []string{"", "float", "string"} {
+		t.Run(numericAs, func(t *testing.T) {
+			ctx := context.Background()
+			f, err := New(Book("testdata/db_types.yml"), DBRunner("db", db, NumericAs(numericAs)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := f.Run(ctx); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
 func TestRunUsingSSHd(t *testing.T) {
 	_, host, hostname, user, port := testutil.CreateSSHdContainer(t)
 	t.Setenv("TEST_HOST", host)
@@ -0,0 +1,48 @@
+package runn
+
+const labelsSectionKey = "labels"
+
+// labelFilter - Filter of runbooks by `labels:`.
+type labelFilter struct {
+	include []string
+	exclude []string
+}
+
+// match returns whether the labels match the filter.
+// The runbook matches if it has at least one of the include labels (OR) and has none of the exclude labels.
+func (f *labelFilter) match(labels []string) bool {
+	for _, l := range f.exclude {
+		if contains(labels, l) {
+			return false
+		}
+	}
+	if len(f.include) == 0 {
+		return true
+	}
+	for _, l := range f.include {
+		if contains(labels, l) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchLabels returns whether the labels match all of the filters (AND).
+func matchLabels(filters []*labelFilter, labels []string) bool {
+	for _, f := range filters {
+		if !f.match(labels) {
+			return false
+		}
+	}
+	return true
+}
+
+// skippedStepLabel returns the label of the step that matches the labels of SkipStepLabels.
+func (o *operator) skippedStepLabel(s *step) (string, bool) {
+	for _, l := range o.skipStepLabels {
+		if contains(s.labels, l) {
+			return l, true
+		}
+	}
+	return "", false
+}
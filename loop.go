@@ -2,8 +2,14 @@ package runn
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
 	"strconv"
 	"strings"
+	"syscall"
 	"time"
 
 	"github.com/goccy/go-yaml"
@@ -13,6 +19,15 @@ import (
 const (
 	loopSectionKey  = "loop"
 	loopCountVarKey = "i"
+	// retryUntilSectionKey - key of the condition to re-run the step until it holds ( the shorthand of `loop.until:` evaluated against the result of the step ).
+	retryUntilSectionKey = "retryUntil"
+)
+
+// classes of errors for `retryOnError:`.
+const (
+	retryOnErrorTimeout     = "timeout"
+	retryOnErrorConnReset   = "connreset"
+	retryOnErrorConnRefused = "connrefused"
 )
 
 var (
@@ -31,11 +46,29 @@ type Loop struct {
 	Jitter      *float64 `yaml:"jitter,omitempty"`
 	Multiplier  *float64 `yaml:"multiplier,omitempty"`
 	Until       string   `yaml:"until"`
-	ctrl        backoff.Controller
+	// RetryableStatus are the HTTP status codes ( e.g. 429 ) or ranges ( e.g. "500-599" ) to retry when until is not satisfied
+	RetryableStatus []interface{} `yaml:"retryableStatus,omitempty"`
+	// RetryOnError are the classes of errors of runners to retry ( timeout, connreset and connrefused )
+	RetryOnError []string `yaml:"retryOnError,omitempty"`
+	ctrl         backoff.Controller
+
+	interval        *time.Duration
+	minInterval     *time.Duration
+	maxInterval     *time.Duration
+	retryableStatus [][2]int
+	// whether the loop is of `retryUntil:` ( the condition is evaluated with the result of the step at the top level, e.g. `res.body.status` )
+	retryUntil bool
+	// interval between the retries adapted by IntervalBackoff
+	backoff *retryBackoff
+}
 
-	interval    *time.Duration
-	minInterval *time.Duration
-	maxInterval *time.Duration
+// retryBackoff is the interval between the retries that is multiplied each time an attempt fails, up to max ( 0 means no limit ).
+type retryBackoff struct {
+	base       time.Duration
+	current    time.Duration
+	multiplier float64
+	max        time.Duration
+	started    bool
 }
 
 func newLoop(v interface{}) (*Loop, error) {
@@ -94,11 +127,152 @@ func newLoop(v interface{}) (*Loop, error) {
 		}
 		l.maxInterval = &imax
 	}
+	for _, s := range l.RetryableStatus {
+		r, err := parseStatusRange(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid retryableStatus: %w", err)
+		}
+		l.retryableStatus = append(l.retryableStatus, r)
+	}
+	for _, c := range l.RetryOnError {
+		switch c {
+		case retryOnErrorTimeout, retryOnErrorConnReset, retryOnErrorConnRefused:
+		default:
+			return nil, fmt.Errorf("invalid retryOnError: %s", c)
+		}
+	}
+
+	return l, nil
+}
 
+// newRetryUntilLoop returns the loop of `retryUntil:`. `loop:` of the step ( v ) can set the count and the intervals, but not until.
+func newRetryUntilLoop(cond string, v interface{}) (*Loop, error) {
+	if strings.TrimSpace(cond) == "" {
+		return nil, errors.New("retryUntil should not be empty")
+	}
+	lv := map[string]interface{}{}
+	switch vv := v.(type) {
+	case nil:
+	case map[string]interface{}:
+		if _, ok := vv["until"]; ok {
+			return nil, errors.New("retryUntil cannot be used with loop.until")
+		}
+		for k, vvv := range vv {
+			lv[k] = vvv
+		}
+	default:
+		// short syntax of loop ( count )
+		lv["count"] = fmt.Sprintf("%v", vv)
+	}
+	lv["until"] = cond
+	l, err := newLoop(lv)
+	if err != nil {
+		return nil, err
+	}
+	l.retryUntil = true
 	return l, nil
 }
 
+// retryResponseSummary returns the status and the body of the HTTP response ( or the whole result ) of the step to report the attempts of `retryUntil:`.
+func retryResponseSummary(v map[string]interface{}) (string, string) {
+	res, ok := v[httpStoreResponseKey].(map[string]interface{})
+	if !ok {
+		return "-", fmt.Sprintf("%v", v)
+	}
+	status := "-"
+	if s, ok := res[httpStoreStatusKey]; ok {
+		status = fmt.Sprintf("%v", s)
+	}
+	if b, ok := res[httpStoreRawBodyKey].(string); ok {
+		return status, b
+	}
+	return status, fmt.Sprintf("%v", res[httpStoreBodyKey])
+}
+
+// parseStatusRange parses the status code ( e.g. 429 ) or the range of status codes ( e.g. "500-599" ).
+func parseStatusRange(v interface{}) ([2]int, error) {
+	switch vv := v.(type) {
+	case int:
+		return [2]int{vv, vv}, nil
+	case uint64:
+		return [2]int{int(vv), int(vv)}, nil
+	case int64:
+		return [2]int{int(vv), int(vv)}, nil
+	case string:
+		from, to, ok := strings.Cut(vv, "-")
+		if !ok {
+			to = from
+		}
+		f, err := strconv.Atoi(strings.TrimSpace(from))
+		if err != nil {
+			return [2]int{}, fmt.Errorf("%q is not a status code", vv)
+		}
+		t, err := strconv.Atoi(strings.TrimSpace(to))
+		if err != nil {
+			return [2]int{}, fmt.Errorf("%q is not a status code", vv)
+		}
+		if f > t {
+			return [2]int{}, fmt.Errorf("%q is not a range of status codes", vv)
+		}
+		return [2]int{f, t}, nil
+	default:
+		return [2]int{}, fmt.Errorf("%v is not a status code", v)
+	}
+}
+
+// hasRetryClassification returns whether `retryableStatus:` or `retryOnError:` is specified.
+func (l *Loop) hasRetryClassification() bool {
+	return len(l.RetryableStatus) > 0 || len(l.RetryOnError) > 0
+}
+
+// retryableStatusOf returns whether the HTTP status code of the result is retryable.
+// If `retryableStatus:` is not specified or the result has no HTTP status code, it returns true.
+func (l *Loop) retryableStatusOf(v map[string]interface{}) (int, bool) {
+	if len(l.retryableStatus) == 0 {
+		return 0, true
+	}
+	res, ok := v[httpStoreResponseKey].(map[string]interface{})
+	if !ok {
+		return 0, true
+	}
+	status, ok := res[httpStoreStatusKey].(int)
+	if !ok {
+		return 0, true
+	}
+	for _, r := range l.retryableStatus {
+		if r[0] <= status && status <= r[1] {
+			return status, true
+		}
+	}
+	return status, false
+}
+
+// retryableError returns whether the error of the runner is in the classes of `retryOnError:`.
+func (l *Loop) retryableError(err error) bool {
+	for _, c := range l.RetryOnError {
+		switch c {
+		case retryOnErrorTimeout:
+			var ne net.Error
+			if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, os.ErrDeadlineExceeded) || (errors.As(err, &ne) && ne.Timeout()) {
+				return true
+			}
+		case retryOnErrorConnReset:
+			if errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.EPIPE) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+				return true
+			}
+		case retryOnErrorConnRefused:
+			if errors.Is(err, syscall.ECONNREFUSED) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 func (l *Loop) Loop(ctx context.Context) bool {
+	if l.backoff != nil {
+		return l.backoff.wait(ctx)
+	}
 	if l.ctrl == nil {
 		var p backoff.Policy
 		if l.interval != nil {
@@ -120,3 +294,55 @@ func (l *Loop) Loop(ctx context.Context) bool {
 	}
 	return backoff.Continue(l.ctrl)
 }
+
+// useIntervalBackoff makes the interval between the retries start from the interval of the loop ( or minInterval ) and be multiplied by the multiplier each time an attempt fails.
+func (l *Loop) useIntervalBackoff(multiplier float64, max time.Duration) {
+	var base time.Duration
+	if l.interval != nil {
+		base = *l.interval
+	} else {
+		base = *l.minInterval
+	}
+	l.backoff = &retryBackoff{
+		base:       base,
+		current:    base,
+		multiplier: multiplier,
+		max:        max,
+	}
+}
+
+// failed multiplies the interval between the retries because the attempt failed.
+func (l *Loop) failed() {
+	if l.backoff == nil {
+		return
+	}
+	next := time.Duration(float64(l.backoff.current) * l.backoff.multiplier)
+	if l.backoff.max > 0 && next > l.backoff.max {
+		next = l.backoff.max
+	}
+	l.backoff.current = next
+}
+
+// succeeded resets the interval between the retries because the attempt succeeded.
+func (l *Loop) succeeded() {
+	if l.backoff == nil {
+		return
+	}
+	l.backoff.current = l.backoff.base
+}
+
+// wait waits for the current interval except for the first attempt.
+func (b *retryBackoff) wait(ctx context.Context) bool {
+	if !b.started {
+		b.started = true
+		return ctx.Err() == nil
+	}
+	t := time.NewTimer(b.current)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-t.C:
+		return true
+	}
+}
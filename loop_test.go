@@ -1,7 +1,18 @@
 package runn
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"syscall"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 )
@@ -84,3 +95,210 @@ func TestNewLoop(t *testing.T) {
 		}
 	}
 }
+
+func TestLoopRetryableStatus(t *testing.T) {
+	tests := []struct {
+		statuses     []int
+		wantRequests int
+		wantErr      bool
+	}{
+		{[]int{503, 429, 200}, 3, false},
+		{[]int{400, 200}, 1, true},
+		{[]int{500, 404, 200}, 2, true},
+		{[]int{502, 502, 502, 502, 502, 502}, 5, true},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%v", tt.statuses), func(t *testing.T) {
+			var requests int
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.statuses[requests])
+				requests++
+			}))
+			t.Cleanup(ts.Close)
+			t.Setenv("TEST_HTTP_END_POINT", ts.URL)
+			o, err := New(Book("testdata/loop_retryable_status.yml"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := o.Run(context.Background()); (err != nil) != tt.wantErr {
+				t.Errorf("got %v\nwantErr %v", err, tt.wantErr)
+			}
+			if requests != tt.wantRequests {
+				t.Errorf("got %v requests\nwant %v", requests, tt.wantRequests)
+			}
+		})
+	}
+}
+
+func TestLoopRetryUntil(t *testing.T) {
+	tests := []struct {
+		statuses     []string
+		wantRequests int
+		wantErr      bool
+	}{
+		{[]string{"done"}, 1, false},
+		{[]string{"pending", "pending", "done"}, 3, false},
+		{[]string{"pending", "pending", "pending", "done"}, 3, true},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%v", tt.statuses), func(t *testing.T) {
+			var requests int
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = fmt.Fprintf(w, `{"status":"%s"}`, tt.statuses[requests])
+				requests++
+			}))
+			t.Cleanup(ts.Close)
+			t.Setenv("TEST_HTTP_END_POINT", ts.URL)
+			o, err := New(Book("testdata/loop_retry_until.yml"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = o.Run(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Errorf("got %v\nwantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				// fail with the last body
+				if want := `{"status":"pending"}`; !strings.Contains(err.Error(), want) {
+					t.Errorf("got %v\nwant to contain %s", err, want)
+				}
+			}
+			if requests != tt.wantRequests {
+				t.Errorf("got %v requests\nwant %v", requests, tt.wantRequests)
+			}
+		})
+	}
+}
+
+func TestNewRetryUntilLoop(t *testing.T) {
+	tests := []struct {
+		cond      string
+		v         interface{}
+		wantCount string
+		wantErr   bool
+	}{
+		{"res.status == 200", nil, "3", false},
+		{"res.status == 200", 5, "5", false},
+		{"res.status == 200", map[string]interface{}{"count": 10, "interval": "1sec"}, "10", false},
+		{"res.status == 200", map[string]interface{}{"until": "current.res.status == 200"}, "", true},
+		{"", nil, "", true},
+	}
+	for _, tt := range tests {
+		got, err := newRetryUntilLoop(tt.cond, tt.v)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%v %v: got error %v", tt.cond, tt.v, err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if got.Count != tt.wantCount {
+			t.Errorf("got %v\nwant %v", got.Count, tt.wantCount)
+		}
+		if got.Until != tt.cond || !got.retryUntil {
+			t.Errorf("got %v, %v", got.Until, got.retryUntil)
+		}
+	}
+}
+
+func TestLoopRetryOnError(t *testing.T) {
+	timeout := &net.OpError{Op: "dial", Err: os.ErrDeadlineExceeded}
+	refused := &net.OpError{Op: "dial", Err: &os.SyscallError{Syscall: "connect", Err: syscall.ECONNREFUSED}}
+	reset := &net.OpError{Op: "read", Err: &os.SyscallError{Syscall: "read", Err: syscall.ECONNRESET}}
+	tests := []struct {
+		retryOnError []string
+		err          error
+		want         bool
+	}{
+		{nil, refused, false},
+		{[]string{"connrefused"}, fmt.Errorf("http request failed: %w", refused), true},
+		{[]string{"connrefused"}, reset, false},
+		{[]string{"connreset"}, reset, true},
+		{[]string{"connreset"}, io.ErrUnexpectedEOF, true},
+		{[]string{"timeout"}, timeout, true},
+		{[]string{"timeout"}, context.DeadlineExceeded, true},
+		{[]string{"timeout", "connreset"}, errors.New("test failed"), false},
+	}
+	for _, tt := range tests {
+		l, err := newLoop(map[string]any{"count": 3, "retryOnError": tt.retryOnError})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := l.retryableError(tt.err); got != tt.want {
+			t.Errorf("%v %v: got %v\nwant %v", tt.retryOnError, tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestLoopIntervalBackoff(t *testing.T) {
+	l, err := newLoop(map[string]any{"count": 5, "interval": "10ms", "until": "false"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.useIntervalBackoff(2, 50*time.Millisecond)
+	want := []time.Duration{20 * time.Millisecond, 40 * time.Millisecond, 50 * time.Millisecond, 50 * time.Millisecond}
+	for _, w := range want {
+		l.failed()
+		if l.backoff.current != w {
+			t.Errorf("got %v\nwant %v", l.backoff.current, w)
+		}
+	}
+	l.succeeded()
+	if want := 10 * time.Millisecond; l.backoff.current != want {
+		t.Errorf("got %v\nwant %v", l.backoff.current, want)
+	}
+
+	ctx := context.Background()
+	l.failed()
+	var elapsed []time.Duration
+	for i := 0; i < 3; i++ {
+		start := time.Now()
+		if !l.Loop(ctx) {
+			t.Fatal("want true")
+		}
+		elapsed = append(elapsed, time.Since(start))
+		l.failed()
+	}
+	// the first attempt does not wait
+	if elapsed[0] >= 20*time.Millisecond {
+		t.Errorf("got %v\nwant < %v", elapsed[0], 20*time.Millisecond)
+	}
+	for i, w := range []time.Duration{40 * time.Millisecond, 50 * time.Millisecond} {
+		if elapsed[i+1] < w {
+			t.Errorf("got %v\nwant >= %v", elapsed[i+1], w)
+		}
+	}
+}
+
+func TestParseStatusRange(t *testing.T) {
+	tests := []struct {
+		v       interface{}
+		want    [2]int
+		wantErr bool
+	}{
+		{429, [2]int{429, 429}, false},
+		{uint64(503), [2]int{503, 503}, false},
+		{"500-599", [2]int{500, 599}, false},
+		{"502", [2]int{502, 502}, false},
+		{"599-500", [2]int{}, true},
+		{"5xx", [2]int{}, true},
+		{true, [2]int{}, true},
+	}
+	for _, tt := range tests {
+		got, err := parseStatusRange(tt.v)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%v: got error %v", tt.v, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%v: got %v\nwant %v", tt.v, got, tt.want)
+		}
+	}
+}
+
+func TestNewLoopInvalidRetryOnError(t *testing.T) {
+	if _, err := newLoop(map[string]any{"retryOnError": []string{"always"}}); err == nil {
+		t.Error("want error")
+	}
+}
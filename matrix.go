@@ -0,0 +1,95 @@
+package runn
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// matrixMaxCombinations is the maximum number of combinations of `matrix:` to avoid accidental explosion.
+const matrixMaxCombinations = 256
+
+// parseMatrix parses `matrix:` of runbook.
+func parseMatrix(in map[string]interface{}) (map[string][]interface{}, error) {
+	if len(in) == 0 {
+		return nil, nil
+	}
+	m := map[string][]interface{}{}
+	for k, v := range in {
+		vv, ok := v.([]interface{})
+		if !ok || len(vv) == 0 {
+			return nil, fmt.Errorf("invalid matrix axis %s: should be a non-empty list: %v", k, v)
+		}
+		m[k] = vv
+	}
+	if _, err := matrixCombinations(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// matrixCombinations returns all combinations of the axes of matrix.
+func matrixCombinations(m map[string][]interface{}) ([]map[string]interface{}, error) {
+	keys := make([]string, 0, len(m))
+	total := 1
+	for k, v := range m {
+		keys = append(keys, k)
+		total *= len(v)
+		if total > matrixMaxCombinations {
+			return nil, fmt.Errorf("too many matrix combinations (max: %d)", matrixMaxCombinations)
+		}
+	}
+	sort.Strings(keys)
+	combs := []map[string]interface{}{{}}
+	for _, k := range keys {
+		var next []map[string]interface{}
+		for _, c := range combs {
+			for _, v := range m[k] {
+				nc := map[string]interface{}{}
+				for kk, vv := range c {
+					nc[kk] = vv
+				}
+				nc[k] = v
+				next = append(next, nc)
+			}
+		}
+		combs = next
+	}
+	return combs, nil
+}
+
+// matrixName returns the name of the combination of matrix ( e.g. `lang=en, user=alice` ).
+func matrixName(c map[string]interface{}) string {
+	keys := make([]string, 0, len(c))
+	for k := range c {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var kvs []string
+	for _, k := range keys {
+		kvs = append(kvs, fmt.Sprintf("%s=%v", k, c[k]))
+	}
+	return strings.Join(kvs, ", ")
+}
+
+// newMatrixOperator returns a new operator for the combination of matrix.
+// The combination is merged into `vars:` after opts, so that it takes precedence over Var of opts.
+func newMatrixOperator(b Option, c map[string]interface{}, opts []Option) (*operator, error) {
+	mopts := append([]Option{b}, opts...)
+	keys := make([]string, 0, len(c))
+	for k := range c {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		mopts = append(mopts, Var(k, c[k]))
+	}
+	o, err := New(mopts...)
+	if err != nil {
+		return nil, err
+	}
+	o.matrixVars = c
+	o.desc = fmt.Sprintf("%s (%s)", o.desc, matrixName(c))
+	o.runResult = newRunResult(o.desc, o.bookPathOrID())
+	return o, nil
+}
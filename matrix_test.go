@@ -0,0 +1,129 @@
+package runn
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestMatrixCombinations(t *testing.T) {
+	tests := []struct {
+		in      map[string][]interface{}
+		want    []map[string]interface{}
+		wantErr bool
+	}{
+		{
+			map[string][]interface{}{
+				"user": {"alice", "bob"},
+			},
+			[]map[string]interface{}{
+				{"user": "alice"},
+				{"user": "bob"},
+			},
+			false,
+		},
+		{
+			map[string][]interface{}{
+				"user": {"alice", "bob"},
+				"lang": {"en", "ja"},
+			},
+			[]map[string]interface{}{
+				{"lang": "en", "user": "alice"},
+				{"lang": "en", "user": "bob"},
+				{"lang": "ja", "user": "alice"},
+				{"lang": "ja", "user": "bob"},
+			},
+			false,
+		},
+		{
+			map[string][]interface{}{
+				"a": make([]interface{}, 16),
+				"b": make([]interface{}, 16),
+				"c": make([]interface{}, 2),
+			},
+			nil,
+			true,
+		},
+	}
+	for _, tt := range tests {
+		got, err := matrixCombinations(tt.in)
+		if err != nil {
+			if !tt.wantErr {
+				t.Error(err)
+			}
+			continue
+		}
+		if tt.wantErr {
+			t.Error("want error")
+		}
+		if diff := cmp.Diff(got, tt.want); diff != "" {
+			t.Error(diff)
+		}
+	}
+}
+
+func TestParseMatrix(t *testing.T) {
+	tests := []struct {
+		in      map[string]interface{}
+		wantErr bool
+	}{
+		{nil, false},
+		{map[string]interface{}{"user": []interface{}{"alice"}}, false},
+		{map[string]interface{}{"user": "alice"}, true},
+		{map[string]interface{}{"user": []interface{}{}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%v", tt.in), func(t *testing.T) {
+			_, err := parseMatrix(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("got %v\nwantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMatrixTakesPrecedenceOverVar(t *testing.T) {
+	ops, err := Load("testdata/matrix.yml", Var("user", "carol"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ops.RunN(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	for _, rr := range ops.Result().RunResults {
+		if rr.Err != nil {
+			t.Errorf("%s: %v", rr.Desc, rr.Err)
+		}
+	}
+}
+
+func TestMatrix(t *testing.T) {
+	ctx := context.Background()
+	ops, err := Load("testdata/matrix.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := ops.RunN(ctx); err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, rr := range ops.Result().RunResults {
+			if rr.Err != nil {
+				t.Error(rr.Err)
+			}
+			got = append(got, rr.Desc)
+		}
+		want := []string{
+			"Matrix test (lang=en, user=alice)",
+			"Matrix test (lang=en, user=bob)",
+			"Matrix test (lang=ja, user=alice)",
+			"Matrix test (lang=ja, user=bob)",
+		}
+		if diff := cmp.Diff(got, want); diff != "" {
+			t.Error(diff)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package runn
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+const (
+	mermaidClient     = "runn"
+	mermaidSummaryLen = 50
+)
+
+var mermaidEscaper = strings.NewReplacer("#", "#35;", ";", "#59;", "\n", " ")
+
+// OutMermaid - Output the executed steps of HTTP, DB and Exec runners as Mermaid sequence diagram.
+func (r *RunResult) OutMermaid(out io.Writer) error {
+	lines := []string{"sequenceDiagram"}
+	participants := []string{}
+	messages := []string{}
+	for _, sr := range r.StepResults {
+		if sr == nil || sr.Skipped {
+			continue
+		}
+		switch sr.RunnerType {
+		case RunnerTypeHTTP, RunnerTypeDB, RunnerTypeExec:
+		default:
+			continue
+		}
+		if !contains(participants, sr.RunnerKey) {
+			participants = append(participants, sr.RunnerKey)
+		}
+		label := sr.Summary
+		if sr.Desc != "" {
+			label = fmt.Sprintf("%s: %s", sr.Desc, sr.Summary)
+		}
+		label = mermaidEscaper.Replace(label)
+		if sr.Err != nil {
+			messages = append(messages,
+				fmt.Sprintf("  %s-x%s: %s", mermaidClient, sr.RunnerKey, label),
+				fmt.Sprintf("  Note over %s,%s: failure on steps.%s", mermaidClient, sr.RunnerKey, sr.Key),
+			)
+			continue
+		}
+		messages = append(messages, fmt.Sprintf("  %s->>%s: %s", mermaidClient, sr.RunnerKey, label))
+	}
+	lines = append(lines, fmt.Sprintf("  participant %s", mermaidClient))
+	for _, p := range participants {
+		lines = append(lines, fmt.Sprintf("  participant %s", p))
+	}
+	lines = append(lines, messages...)
+	if _, err := fmt.Fprintln(out, strings.Join(lines, "\n")); err != nil {
+		return err
+	}
+	return nil
+}
+
+// summary - return the summary of the request of the step ( e.g. `GET /users/1` ).
+func (s *step) summary() string {
+	switch {
+	case s.httpRunner != nil && s.httpRequest != nil:
+		for _, p := range sortedKeys(s.httpRequest) {
+			m, ok := s.httpRequest[p].(map[string]interface{})
+			if !ok {
+				return p
+			}
+			for _, method := range sortedKeys(m) {
+				return fmt.Sprintf("%s %s", strings.ToUpper(method), p)
+			}
+			return p
+		}
+	case s.dbRunner != nil && s.dbQuery != nil:
+		if q, ok := s.dbQuery["query"].(string); ok {
+			return truncateSummary(q)
+		}
+		if t, ok := s.dbQuery["describe"].(string); ok {
+			return fmt.Sprintf("DESCRIBE %s", t)
+		}
+		if l, ok := s.dbQuery["listen"]; ok {
+			if m, ok := l.(map[string]interface{}); ok {
+				l = m["channel"]
+			}
+			return fmt.Sprintf("LISTEN %v", l)
+		}
+	case s.execRunner != nil && s.execCommand != nil:
+		if c, ok := s.execCommand["command"].(string); ok {
+			return truncateSummary(c)
+		}
+	}
+	return ""
+}
+
+func truncateSummary(s string) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if r := []rune(s); len(r) > mermaidSummaryLen {
+		return string(r[:mermaidSummaryLen]) + "..."
+	}
+	return s
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
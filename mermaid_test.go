@@ -0,0 +1,38 @@
+package runn
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"testing"
+
+	"github.com/k1LoW/runn/testutil"
+	"github.com/tenntenn/golden"
+)
+
+func TestOutMermaid(t *testing.T) {
+	ctx := context.Background()
+	ts := testutil.HTTPServer(t)
+	t.Setenv("TEST_HTTP_END_POINT", ts.URL)
+	db, _ := testutil.SQLite(t)
+	if _, err := db.Exec("CREATE TABLE users (id INTEGER PRIMARY KEY, username TEXT);"); err != nil {
+		t.Fatal(err)
+	}
+	o, err := New(Book("testdata/mermaid.yml"), DBRunner("db", db))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = o.Run(ctx)
+	got := new(bytes.Buffer)
+	if err := o.Result().OutMermaid(got); err != nil {
+		t.Fatal(err)
+	}
+	key := "result_out_mermaid"
+	if os.Getenv("UPDATE_GOLDEN") != "" {
+		golden.Update(t, "testdata", key, got)
+		return
+	}
+	if diff := golden.Diff(t, "testdata", key, got); diff != "" {
+		t.Error(diff)
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"os"
 	"path/filepath"
 	"sort"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"testing"
@@ -24,11 +25,6 @@ import (
 	"go.uber.org/multierr"
 )
 
-var (
-	cyan   = color.New(color.FgCyan).SprintFunc()
-	yellow = color.New(color.FgYellow).SprintFunc()
-)
-
 var errStepSkiped = errors.New("step skipped")
 
 var _ otchkiss.Requester = (*operators)(nil)
@@ -40,27 +36,110 @@ type operator struct {
 	grpcRunners map[string]*grpcRunner
 	cdpRunners  map[string]*cdpRunner
 	sshRunners  map[string]*sshRunner
-	steps       []*step
-	store       store
-	desc        string
-	useMap      bool // Use map syntax in `steps:`.
-	debug       bool
-	profile     bool
-	interval    time.Duration
-	loop        *Loop
-	concurrency string
-	root        string
-	t           *testing.T
-	thisT       *testing.T
-	parent      *step
-	force       bool
-	failFast    bool
-	included    bool
-	ifCond      string
-	skipTest    bool
-	skipped     bool
-	stdout      io.Writer
-	stderr      io.Writer
+	// runners registered by RegisterRunner
+	customRunners map[string]*customRunner
+	steps         []*step
+	// index of the current step
+	stepIdx int
+	store   store
+	desc    string
+	useMap  bool // Use map syntax in `steps:`.
+	debug   bool
+	// dump requests and responses of runners
+	trace    bool
+	profile  bool
+	interval time.Duration
+	// multiplier and cap of the interval between steps after failures
+	intervalMultiplier float64
+	maxInterval        time.Duration
+	// current interval between steps
+	currentInterval time.Duration
+	loop            *Loop
+	concurrency     string
+	root            string
+	t               *testing.T
+	thisT           *testing.T
+	parent          *step
+	force           bool
+	failFast        bool
+	// whether a step has already run in the current run ( to sleep for the interval before the next step )
+	stepRan bool
+	// fail the step on HTTP error status ( >= 400 )
+	failOnHTTPError bool
+	// headers set in every request of HTTP runners ( HTTPHeaders )
+	httpHeaders map[string]string
+	// header to set the correlation ID in every request of HTTP runners ( CorrelationID )
+	correlationIDHeader string
+	// correlation ID generated per runbook
+	correlationID string
+	// fail on undefined variables in `{{ }}`
+	strictVars bool
+	// fail on unknown keys of steps
+	strictKeys bool
+	// continue past expr evaluation errors of steps and return all of them
+	collectExprErrors bool
+	// namespace the values bound by `bind:` under the step key
+	namespaceBinds bool
+	// decode numbers in JSON response bodies as json.Number
+	useJSONNumber bool
+	// request templates of `templates:`
+	templates map[string]map[string]interface{}
+	// reusable sequences of steps of `functions:`
+	functions map[string]*function
+	// names of the functions being called by `call:` ( outermost first )
+	callStack []string
+	// maximum number of rows to scan per result set of DB runners ( <= 0: unlimited )
+	dbMaxRows int
+	// database/sql drivers registered by RegisterDBDriver
+	dbDrivers map[string]string
+	included  bool
+	ifCond    string
+	labels    []string
+	// axes of `matrix:`
+	matrix map[string][]interface{}
+	// combination of `matrix:` merged into vars
+	matrixVars map[string]interface{}
+	// record of the dataset merged into vars and its name ( LoadWithDataset )
+	datasetVars map[string]interface{}
+	datasetName string
+	// expressions of `export:`
+	exports map[string]string
+	// values of `export:` evaluated at the end of the run
+	exported map[string]interface{}
+	// number of requests expected by `expectRequests:`
+	expectRequests *int
+	// path patterns of the requests that must not be sent by `expectNoRequests:`
+	expectNoRequests []string
+	// maximum number of DB queries allowed by `maxQueries:`
+	maxQueries     *int
+	requestCounter *requestCounter
+	// maximum total bytes of the responses allowed by `maxResponseBytesTotal:`
+	maxResponseBytesTotal *int64
+	// total bytes of the request and response bodies of the HTTP runners ( including included runbooks )
+	bytesSent     int64
+	bytesReceived int64
+	// number of times to run all steps by `repeat:`
+	repeat int
+	// condition tested across the iterations by `repeatTest:`
+	repeatTest string
+	// mask the values of secrets in outputs
+	secretMasker *secretMasker
+	// seed for randomizing the order of running steps
+	shuffleStepsSeed *int64
+	// skip because the labels do not match the filters of RunLabels
+	skipLabels bool
+	// skip because the runbook already passed in the checkpoint file of ResumeFrom
+	resumed bool
+	// skip because the runbook is not sampled by RunSample
+	unsampled bool
+	// elapsed times of the steps compared or updated by Baseline and UpdateBaseline
+	baseline *baseline
+	// skip steps that have any of the labels ( SkipStepLabels )
+	skipStepLabels []string
+	skipTest       bool
+	skipped        bool
+	stdout         io.Writer
+	stderr         io.Writer
 	// skip some errors for `runn list`
 	newOnly  bool
 	bookPath string
@@ -71,6 +150,7 @@ type operator struct {
 	sw            *stopw.Span
 	capturers     capturers
 	runResult     *RunResult
+	color         *bool
 }
 
 // Desc returns `desc:` of runbook.
@@ -83,6 +163,11 @@ func (o *operator) If() string {
 	return o.ifCond
 }
 
+// Labels returns `labels:` of runbook.
+func (o *operator) Labels() []string {
+	return o.labels
+}
+
 // BookPath returns path of runbook.
 func (o *operator) BookPath() string {
 	return o.bookPath
@@ -107,14 +192,24 @@ func (o *operator) Close() {
 }
 
 func (o *operator) runStep(ctx context.Context, i int, s *step) error {
+	o.stepIdx = i
 	ids := s.ids()
 	o.capturers.setCurrentIDs(ids)
 	defer o.sw.Start(ids.toInterfaceSlice()...).Stop()
-	if i != 0 {
-		// interval:
-		time.Sleep(o.interval)
+	if o.stepRan {
+		// interval: between steps in the order of running ( steps may not start from the first with ShuffleSteps )
+		time.Sleep(o.currentInterval)
 		o.Debugln("")
 	}
+	o.stepRan = true
+	start := time.Now()
+	defer func() {
+		s.elapsed = time.Since(start)
+	}()
+	if l, ok := o.skippedStepLabel(s); ok {
+		o.Debugf(o.yellow("Skip %s because it has the label '%s'\n"), o.stepName(i), l)
+		return errStepSkiped
+	}
 	if s.ifCond != "" {
 		tf, err := o.expandCondBeforeRecord(s.ifCond)
 		if err != nil {
@@ -122,19 +217,19 @@ func (o *operator) runStep(ctx context.Context, i int, s *step) error {
 		}
 		if !tf {
 			if s.desc != "" {
-				o.Debugf(yellow("Skip '%s' on %s\n"), s.desc, o.stepName(i))
+				o.Debugf(o.yellow("Skip '%s' on %s\n"), s.desc, o.stepName(i))
 			} else if s.runnerKey != "" {
-				o.Debugf(yellow("Skip '%s' on %s\n"), s.runnerKey, o.stepName(i))
+				o.Debugf(o.yellow("Skip '%s' on %s\n"), s.runnerKey, o.stepName(i))
 			} else {
-				o.Debugf(yellow("Skip on %s\n"), o.stepName(i))
+				o.Debugf(o.yellow("Skip on %s\n"), o.stepName(i))
 			}
 			return errStepSkiped
 		}
 	}
 	if s.desc != "" {
-		o.Debugf(cyan("Run '%s' on %s\n"), s.desc, o.stepName(i))
+		o.Debugf(o.cyan("Run '%s' on %s\n"), s.desc, o.stepName(i))
 	} else if s.runnerKey != "" {
-		o.Debugf(cyan("Run '%s' on %s\n"), s.runnerKey, o.stepName(i))
+		o.Debugf(o.cyan("Run '%s' on %s\n"), s.runnerKey, o.stepName(i))
 	}
 
 	stepFn := func(t *testing.T) error {
@@ -156,6 +251,9 @@ func (o *operator) runStep(ctx context.Context, i int, s *step) error {
 			if err != nil {
 				return err
 			}
+			if err := o.setDefaultHTTPHeaders(req, s.httpRunner); err != nil {
+				return err
+			}
 			if err := s.httpRunner.Run(ctx, req); err != nil {
 				return fmt.Errorf("http request failed on %s: %w", o.stepName(i), err)
 			}
@@ -221,15 +319,64 @@ func (o *operator) runStep(ctx context.Context, i int, s *step) error {
 				return fmt.Errorf("exec command failed on %s: %w", o.stepName(i), err)
 			}
 			run = true
+		case s.pingRunner != nil && s.pingTargets != nil:
+			if err := s.pingRunner.Run(ctx, s.pingTargets); err != nil {
+				return fmt.Errorf("ping failed on %s: %w", o.stepName(i), err)
+			}
+			run = true
+		case s.waitRunner != nil && s.waitConfig != nil:
+			e, err := o.expandBeforeRecord(s.waitConfig)
+			if err != nil {
+				return err
+			}
+			wc, ok := e.(map[string]interface{})
+			if !ok {
+				return fmt.Errorf("invalid %s: %v", o.stepName(i), e)
+			}
+			c, err := parseWaitConfig(wc)
+			if err != nil {
+				return fmt.Errorf("invalid %s: %w", o.stepName(i), err)
+			}
+			if err := s.waitRunner.Run(ctx, c); err != nil {
+				return fmt.Errorf("wait failed on %s: %w", o.stepName(i), err)
+			}
+			run = true
+		case s.customRunner != nil && s.customRequest != nil:
+			e, err := o.expandBeforeRecord(s.customRequest)
+			if err != nil {
+				return err
+			}
+			req, ok := e.(map[string]interface{})
+			if !ok {
+				return fmt.Errorf("invalid %s: %v", o.stepName(i), e)
+			}
+			if err := s.customRunner.Run(ctx, req); err != nil {
+				return fmt.Errorf("%s failed on %s: %w", s.runnerKey, o.stepName(i), err)
+			}
+			run = true
 		case s.includeRunner != nil && s.includeConfig != nil:
 			if err := s.includeRunner.Run(ctx, s.includeConfig); err != nil {
 				return fmt.Errorf("include failed on %s: %w", o.stepName(i), err)
 			}
 			run = true
+		case s.callRunner != nil && s.callConfig != nil:
+			if err := s.callRunner.Run(ctx, s.callConfig); err != nil {
+				return fmt.Errorf("call failed on %s: %w", o.stepName(i), err)
+			}
+			run = true
+		}
+		// transform
+		if s.transform != "" {
+			if !run {
+				return fmt.Errorf("transform requires a runner: %s", o.stepName(i))
+			}
+			if err := o.transform(s); err != nil {
+				return fmt.Errorf("transform failed on %s: %w", o.stepName(i), err)
+			}
 		}
 		// dump runner
 		if s.dumpRunner != nil && s.dumpRequest != nil {
-			o.Debugf(cyan("Run '%s' on %s\n"), dumpRunnerKey, o.stepName(i))
+			o.Debugf(o.cyan("Run '%s' on %s\n"), dumpRunnerKey, o.stepName(i))
 			if err := s.dumpRunner.Run(ctx, s.dumpRequest, !run); err != nil {
 				return fmt.Errorf("dump failed on %s: %w", o.stepName(i), err)
 			}
@@ -237,7 +384,7 @@ func (o *operator) runStep(ctx context.Context, i int, s *step) error {
 		}
 		// bind runner
 		if s.bindRunner != nil && s.bindCond != nil {
-			o.Debugf(cyan("Run '%s' on %s\n"), bindRunnerKey, o.stepName(i))
+			o.Debugf(o.cyan("Run '%s' on %s\n"), bindRunnerKey, o.stepName(i))
 			if err := s.bindRunner.Run(ctx, s.bindCond, !run); err != nil {
 				return fmt.Errorf("bind failed on %s: %w", o.stepName(i), err)
 			}
@@ -246,13 +393,13 @@ func (o *operator) runStep(ctx context.Context, i int, s *step) error {
 		// test runner
 		if s.testRunner != nil && s.testCond != "" {
 			if o.skipTest {
-				o.Debugf(yellow("Skip '%s' on %s\n"), testRunnerKey, o.stepName(i))
+				o.Debugf(o.yellow("Skip '%s' on %s\n"), testRunnerKey, o.stepName(i))
 				if !run {
 					return errStepSkiped
 				}
 				return nil
 			}
-			o.Debugf(cyan("Run '%s' on %s\n"), testRunnerKey, o.stepName(i))
+			o.Debugf(o.cyan("Run '%s' on %s\n"), testRunnerKey, o.stepName(i))
 			if err := s.testRunner.Run(ctx, s.testCond, !run); err != nil {
 				if s.desc != "" {
 					return fmt.Errorf("test failed on %s '%s': %w", o.stepName(i), s.desc, err)
@@ -281,11 +428,20 @@ func (o *operator) runStep(ctx context.Context, i int, s *step) error {
 		var (
 			bt string
 			j  int
+			// error of the last attempt retried by retryOnError
+			lasterr error
 		)
+		section := loopSectionKey
+		if s.loop.retryUntil {
+			section = retryUntilSectionKey
+		}
 		c, err := EvalCount(s.loop.Count, o.store.toMap())
 		if err != nil {
 			return err
 		}
+		if o.intervalMultiplier > 1 && (s.loop.Until != "" || len(s.loop.RetryOnError) > 0) {
+			s.loop.useIntervalBackoff(o.intervalMultiplier, o.maxInterval)
+		}
 		for s.loop.Loop(ctx) {
 			if j >= c {
 				break
@@ -293,13 +449,29 @@ func (o *operator) runStep(ctx context.Context, i int, s *step) error {
 			jj := j
 			o.store.loopIndex = &jj
 			if err := stepFn(o.thisT); err != nil {
-				return fmt.Errorf("loop failed: %w", err)
+				if !s.loop.retryableError(err) {
+					return fmt.Errorf("loop failed: %w", err)
+				}
+				o.Debugf(o.yellow("Retry %s because of the retryable error: %v\n"), o.stepName(i), err)
+				lasterr = err
+				s.loop.failed()
+				j++
+				continue
+			}
+			lasterr = nil
+			if s.loop.Until == "" {
+				s.loop.succeeded()
 			}
 			if s.loop.Until != "" {
 				store := o.store.toMap()
 				store[storeIncludedKey] = o.included
 				store[storePreviousKey] = o.store.previous()
 				store[storeCurrentKey] = o.store.latest()
+				if s.loop.retryUntil {
+					for k, v := range o.store.latest() {
+						store[k] = v
+					}
+				}
 				bt, err = buildTree(s.loop.Until, store)
 				if err != nil {
 					return fmt.Errorf("loop failed on %s: %w", o.stepName(i), err)
@@ -308,20 +480,36 @@ func (o *operator) runStep(ctx context.Context, i int, s *step) error {
 				if err != nil {
 					return fmt.Errorf("loop failed on %s: %w", o.stepName(i), err)
 				}
+				if s.loop.retryUntil {
+					status, _ := retryResponseSummary(o.store.latest())
+					o.Tracef("%s attempt %d/%d: status %s: (%s) is %v\n", o.stepName(i), j+1, c, status, s.loop.Until, tf)
+				}
 				if tf {
 					retrySuccess = true
 					break
 				}
+				if status, ok := s.loop.retryableStatusOf(o.store.latest()); !ok {
+					// fail fast without retrying deterministic failures
+					return fmt.Errorf("retry loop failed on %s.%s: status %d is not retryable: (%s) is not true\n%s", o.stepName(i), section, status, s.loop.Until, bt)
+				}
+				s.loop.failed()
 			}
 			j++
 		}
+		if lasterr != nil {
+			return fmt.Errorf("retry loop failed on %s.%s (count: %d): %w", o.stepName(i), section, c, lasterr)
+		}
 		if !retrySuccess {
 			err := fmt.Errorf("(%s) is not true\n%s", s.loop.Until, bt)
+			if s.loop.retryUntil {
+				status, body := retryResponseSummary(o.store.latest())
+				err = fmt.Errorf("%w\nlast response: status %s\n%s", err, status, body)
+			}
 			o.store.loopIndex = nil
 			if s.loop.interval != nil {
-				return fmt.Errorf("retry loop failed on %s.loop (count: %d, interval: %v): %w", o.stepName(i), c, *s.loop.interval, err)
+				return fmt.Errorf("retry loop failed on %s.%s (count: %d, interval: %v): %w", o.stepName(i), section, c, *s.loop.interval, err)
 			} else {
-				return fmt.Errorf("retry loop failed on %s.loop (count: %d, minInterval: %v, maxInterval: %v): %w", o.stepName(i), c, *s.loop.minInterval, *s.loop.maxInterval, err)
+				return fmt.Errorf("retry loop failed on %s.%s (count: %d, minInterval: %v, maxInterval: %v): %w", o.stepName(i), section, c, *s.loop.minInterval, *s.loop.maxInterval, err)
 			}
 		}
 	} else {
@@ -334,44 +522,49 @@ func (o *operator) runStep(ctx context.Context, i int, s *step) error {
 
 // Record that it has not been run.
 func (o *operator) recordNotRun(i int) {
-	if o.store.length() == i+1 {
+	if o.store.length() > i {
+		// already recorded ( or filled as not run when steps are shuffled )
+		if o.useMap {
+			o.store.touchMapped(o.steps[i].key)
+		} else {
+			o.store.touchListed(i)
+		}
 		return
 	}
 	v := map[string]interface{}{}
 	v[storeStepRunKey] = false
 	if o.useMap {
-		o.recordAsMapped(v)
+		o.recordAsMapped(i, v)
 		return
 	}
-	o.recordAsListed(v)
+	o.recordAsListed(i, v)
 }
 
+// record - record the result of the current step.
 func (o *operator) record(v map[string]interface{}) {
 	if v == nil {
 		v = map[string]interface{}{}
 	}
 	v[storeStepRunKey] = true
+	// fill the steps that did not record
+	for i := o.store.length(); i < o.stepIdx; i++ {
+		o.recordNotRun(i)
+	}
 	if o.useMap {
-		o.recordAsMapped(v)
+		o.recordAsMapped(o.stepIdx, v)
 		return
 	}
-	o.recordAsListed(v)
+	o.recordAsListed(o.stepIdx, v)
 }
 
-func (o *operator) recordAsListed(v map[string]interface{}) {
-	if o.store.loopIndex != nil && *o.store.loopIndex > 0 {
-		// delete values of prevous loop
-		o.store.steps = o.store.steps[:len(o.store.steps)-1]
-	}
-	o.store.recordAsListed(v)
+// recordAsListed - record the result of the i-th step. The values of the previous loop are overwritten.
+func (o *operator) recordAsListed(i int, v map[string]interface{}) {
+	o.store.recordAsListed(i, v)
 }
 
-func (o *operator) recordAsMapped(v map[string]interface{}) {
-	if o.store.loopIndex != nil && *o.store.loopIndex > 0 {
-		// delete values of prevous loop
-		delete(o.store.stepMap, o.steps[len(o.store.stepMap)-1].key)
-	}
-	k := o.steps[len(o.store.stepMap)].key
+// recordAsMapped - record the result of the i-th step with the key of the step. The values of the previous loop are overwritten.
+func (o *operator) recordAsMapped(i int, v map[string]interface{}) {
+	k := o.steps[i].key
 	o.store.recordAsMapped(k, v)
 }
 
@@ -398,6 +591,7 @@ func (o *operator) ids() IDs {
 }
 
 // New returns *operator.
+// `matrix:` of the runbook is not expanded by New ( use Load ).
 func New(opts ...Option) (*operator, error) {
 	bk := newBook()
 	if err := bk.applyOptions(opts...); err != nil {
@@ -405,48 +599,119 @@ func New(opts ...Option) (*operator, error) {
 	}
 
 	o := &operator{
-		id:          generateRunbookID(),
-		httpRunners: map[string]*httpRunner{},
-		dbRunners:   map[string]*dbRunner{},
-		grpcRunners: map[string]*grpcRunner{},
-		cdpRunners:  map[string]*cdpRunner{},
-		sshRunners:  map[string]*sshRunner{},
+		id:            generateRunbookID(),
+		httpRunners:   map[string]*httpRunner{},
+		dbRunners:     map[string]*dbRunner{},
+		grpcRunners:   map[string]*grpcRunner{},
+		cdpRunners:    map[string]*cdpRunner{},
+		sshRunners:    map[string]*sshRunner{},
+		customRunners: map[string]*customRunner{},
 		store: store{
 			steps:    []map[string]interface{}{},
 			stepMap:  map[string]map[string]interface{}{},
 			vars:     bk.vars,
+			secrets:  bk.secrets,
 			funcs:    bk.funcs,
 			bindVars: map[string]interface{}{},
 			useMap:   bk.useMap,
 		},
-		useMap:      bk.useMap,
-		desc:        bk.desc,
-		debug:       bk.debug,
-		profile:     bk.profile,
-		interval:    bk.interval,
-		loop:        bk.loop,
-		concurrency: bk.concurrency,
-		t:           bk.t,
-		thisT:       bk.t,
-		force:       bk.force,
-		failFast:    bk.failFast,
-		included:    bk.included,
-		ifCond:      bk.ifCond,
-		skipTest:    bk.skipTest,
-		stdout:      bk.stdout,
-		stderr:      bk.stderr,
-		newOnly:     bk.loadOnly,
-		bookPath:    bk.path,
-		beforeFuncs: bk.beforeFuncs,
-		afterFuncs:  bk.afterFuncs,
-		sw:          stopw.New(),
-		capturers:   bk.capturers,
-		runResult:   newRunResult(bk.desc, bk.path),
-	}
-
-	if o.debug {
+		useMap:             bk.useMap,
+		desc:               bk.desc,
+		debug:              bk.debug,
+		trace:              bk.trace,
+		profile:            bk.profile,
+		interval:           bk.interval,
+		intervalMultiplier: bk.intervalMultiplier,
+		maxInterval:        bk.maxInterval,
+		currentInterval:    bk.interval,
+		loop:               bk.loop,
+		concurrency:        bk.concurrency,
+		t:                  bk.t,
+		thisT:              bk.t,
+		force:              bk.force,
+		failFast:           bk.failFast,
+		failOnHTTPError:    bk.failOnHTTPError,
+		httpHeaders:        bk.httpHeaders,
+		dbMaxRows:          defaultDBMaxRows,
+		strictVars:         bk.strictVars,
+		strictKeys:         bk.strictKeys,
+		collectExprErrors:  bk.collectExprErrors,
+		namespaceBinds:     bk.namespaceBinds,
+		useJSONNumber:      bk.useJSONNumber,
+		templates:          bk.templates,
+		functions:          bk.functions,
+		dbDrivers:          bk.dbDrivers,
+		included:           bk.included,
+		ifCond:             bk.ifCond,
+		labels:             bk.labels,
+		matrix:             bk.matrix,
+		exports:            bk.exports,
+		expectRequests:     bk.expectRequests,
+		maxQueries:         bk.maxQueries,
+		repeat:             bk.repeat,
+		repeatTest:         bk.repeatTest,
+		shuffleStepsSeed:   bk.shuffleStepsSeed,
+		skipLabels:         !matchLabels(bk.runLabelFilters, bk.labels),
+		skipStepLabels:     bk.skipStepLabels,
+		skipTest:           bk.skipTest,
+		stdout:             bk.stdout,
+		stderr:             bk.stderr,
+		newOnly:            bk.loadOnly,
+		bookPath:           bk.path,
+		beforeFuncs:        bk.beforeFuncs,
+		afterFuncs:         bk.afterFuncs,
+		sw:                 stopw.New(),
+		capturers:          bk.capturers,
+		runResult:          newRunResult(bk.desc, bk.path),
+		color:              bk.color,
+	}
+
+	if o.debug || o.trace {
 		o.capturers = append(o.capturers, NewDebugger(o.stderr))
 	}
+	if bk.dbMaxRows != nil {
+		o.dbMaxRows = *bk.dbMaxRows
+	}
+	if o.namespaceBinds {
+		o.store.boundVars = map[string]map[string]interface{}{}
+	}
+	o.maxResponseBytesTotal = bk.maxResponseBytesTotal
+	o.expectNoRequests = bk.expectNoRequests
+	if bk.correlationIDHeader != "" {
+		gen := bk.correlationIDFunc
+		if gen == nil {
+			gen = func() (string, error) { return generateRunbookID(), nil }
+		}
+		if v, ok := o.store.vars[correlationIDVarKey]; ok {
+			// keep the correlation ID set by the user ( e.g. `vars._correlationId:` ) instead of generating it
+			s, ok := v.(string)
+			if !ok || s == "" {
+				return nil, fmt.Errorf("invalid vars.%s: the correlation ID should be a non-empty string: %v", correlationIDVarKey, v)
+			}
+			gen = func() (string, error) { return s, nil }
+		}
+		id, err := gen()
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate the correlation ID: %w", err)
+		}
+		o.correlationIDHeader = bk.correlationIDHeader
+		o.correlationID = id
+		o.store.vars[correlationIDVarKey] = id
+	}
+	if bk.baselinePath != "" {
+		b, err := newBaseline(bk.baselinePath, bk.updateBaseline, bk.baselineTolerance)
+		if err != nil {
+			return nil, err
+		}
+		o.baseline = b
+	}
+	if o.expectRequests != nil || o.maxQueries != nil || len(o.expectNoRequests) > 0 {
+		o.requestCounter = newRequestCounter()
+		o.capturers = append(o.capturers, o.requestCounter)
+	}
+	o.secretMasker = newSecretMasker(bk.secrets)
+	o.capturers = o.capturers.masked(o.secretMasker)
+	o.runResult.masker = o.secretMasker
 	if o.concurrency == "" {
 		o.concurrency = o.id
 	}
@@ -459,12 +724,38 @@ func New(opts ...Option) (*operator, error) {
 
 	for k, v := range bk.httpRunners {
 		v.operator = o
+		rt, ok := bk.httpRoundTrippers[k]
+		if ok && v.client == nil {
+			return nil, fmt.Errorf("failed to set http.RoundTripper to the runner with http.Handler (%s): %s", o.bookPath, k)
+		}
+		if !ok {
+			rt = bk.httpRoundTripper
+		}
+		if rt != nil && v.client != nil {
+			// copy the client not to change the client given by the user
+			c := *v.client
+			c.Transport = rt
+			v.client = &c
+		}
 		o.httpRunners[k] = v
 	}
+	for k := range bk.httpRoundTrippers {
+		if _, ok := bk.httpRunners[k]; !ok {
+			return nil, fmt.Errorf("failed to set http.RoundTripper (%s): HTTP runner not found: %s", o.bookPath, k)
+		}
+	}
 	for k, v := range bk.dbRunners {
 		v.operator = o
 		o.dbRunners[k] = v
 	}
+	if _, ok := o.store.funcs[sqlIdentFuncName]; !ok {
+		// quote identifiers for the dialect of the DB runner
+		o.store.funcs[sqlIdentFuncName] = o.sqlQuoteIdent
+	}
+	if _, ok := o.store.funcs[sqlQuoteFuncName]; !ok {
+		// quote values for the dialect of the DB runner
+		o.store.funcs[sqlQuoteFuncName] = o.sqlQuote
+	}
 	for k, v := range bk.grpcRunners {
 		v.operator = o
 		if bk.grpcNoTLS {
@@ -481,6 +772,10 @@ func New(opts ...Option) (*operator, error) {
 		v.operator = o
 		o.sshRunners[k] = v
 	}
+	for k, v := range bk.customRunners {
+		v.operator = o
+		o.customRunners[k] = v
+	}
 
 	keys := map[string]struct{}{}
 	for k := range o.httpRunners {
@@ -510,10 +805,21 @@ func New(opts ...Option) (*operator, error) {
 		}
 		keys[k] = struct{}{}
 	}
+	for k := range o.customRunners {
+		if _, ok := keys[k]; ok {
+			return nil, fmt.Errorf("duplicate runner names (%s): %s", o.bookPath, k)
+		}
+		keys[k] = struct{}{}
+	}
 	var merr error
 	for k, err := range bk.runnerErrs {
 		merr = multierr.Append(merr, fmt.Errorf("runner %s error: %w", k, err))
 	}
+	for k, r := range o.dbRunners {
+		if err := r.validateGroup(o.dbRunners); err != nil {
+			merr = multierr.Append(merr, fmt.Errorf("runner %s error: %w", k, err))
+		}
+	}
 	if merr != nil && !o.newOnly {
 		return nil, fmt.Errorf("failed to add runners (%s): %w", o.bookPath, merr)
 	}
@@ -531,6 +837,26 @@ func New(opts ...Option) (*operator, error) {
 			}
 			return nil, fmt.Errorf("failed to append step (%s): %w", o.bookPath, err)
 		}
+		if i < len(bk.stepSources) {
+			o.steps[len(o.steps)-1].source = bk.stepSources[i]
+		}
+	}
+
+	// steps of `teardown:` are appended after the steps of `steps:`
+	for _, sec := range teardownSectionKeys {
+		for i, s := range bk.teardownSteps[sec] {
+			key := fmt.Sprintf("%d", len(o.steps))
+			if o.useMap {
+				key = fmt.Sprintf("teardown.%s[%d]", sec, i)
+			}
+			if err := o.AppendStep(key, s); err != nil {
+				if o.newOnly {
+					continue
+				}
+				return nil, fmt.Errorf("failed to append teardown step (%s): %w", o.bookPath, err)
+			}
+			o.steps[len(o.steps)-1].teardown = sec
+		}
 	}
 
 	return o, nil
@@ -541,7 +867,21 @@ func (o *operator) AppendStep(key string, s map[string]interface{}) error {
 	if o.t != nil {
 		o.t.Helper()
 	}
+	// use section
+	if _, ok := s[useSectionKey]; ok {
+		resolved, err := o.resolveTemplate(s)
+		if err != nil {
+			return err
+		}
+		s = resolved
+	}
+	if o.strictKeys {
+		if unknown := o.unknownStepKeys(s); len(unknown) > 0 {
+			return fmt.Errorf("unknown keys in step: %s", strings.Join(unknown, ", "))
+		}
+	}
 	step := newStep(key, o)
+	step.dependent = referencesPriorSteps(s)
 	// if section
 	if v, ok := s[ifSectionKey]; ok {
 		step.ifCond, ok = v.(string)
@@ -558,8 +898,59 @@ func (o *operator) AppendStep(key string, s map[string]interface{}) error {
 		}
 		delete(s, descSectionKey)
 	}
+	// ordered section
+	if v, ok := s[orderedSectionKey]; ok {
+		step.ordered, ok = v.(bool)
+		if !ok {
+			return fmt.Errorf("invalid ordered: %v", v)
+		}
+		delete(s, orderedSectionKey)
+	}
+	// fatal section
+	if v, ok := s[fatalSectionKey]; ok {
+		step.fatal, ok = v.(bool)
+		if !ok {
+			return fmt.Errorf("invalid fatal: %v", v)
+		}
+		delete(s, fatalSectionKey)
+	}
+	// labels section
+	if v, ok := s[labelsSectionKey]; ok {
+		vv, ok := v.([]interface{})
+		if !ok {
+			return fmt.Errorf("invalid labels: %v", v)
+		}
+		for _, l := range vv {
+			ls, ok := l.(string)
+			if !ok {
+				return fmt.Errorf("invalid labels: %v", v)
+			}
+			step.labels = append(step.labels, ls)
+		}
+		delete(s, labelsSectionKey)
+	}
+	// transform section
+	if v, ok := s[transformSectionKey]; ok {
+		step.transform, ok = v.(string)
+		if !ok {
+			return fmt.Errorf("invalid transform: %v", v)
+		}
+		delete(s, transformSectionKey)
+	}
 	// loop section
-	if v, ok := s[loopSectionKey]; ok {
+	if v, ok := s[retryUntilSectionKey]; ok {
+		cond, ok := v.(string)
+		if !ok {
+			return fmt.Errorf("invalid retryUntil: %v", v)
+		}
+		r, err := newRetryUntilLoop(cond, s[loopSectionKey])
+		if err != nil {
+			return fmt.Errorf("invalid retryUntil: %w\n%v", err, v)
+		}
+		step.loop = r
+		delete(s, retryUntilSectionKey)
+		delete(s, loopSectionKey)
+	} else if v, ok := s[loopSectionKey]; ok {
 		r, err := newLoop(v)
 		if err != nil {
 			return fmt.Errorf("invalid loop: %w\n%v", err, v)
@@ -601,14 +992,27 @@ func (o *operator) AppendStep(key string, s map[string]interface{}) error {
 				expr: vv,
 			}
 		case map[string]interface{}:
-			expr, ok := vv["expr"]
+			expr, ok := vv["expr"].(string)
 			if !ok {
 				return fmt.Errorf("invalid dump request: %v", vv)
 			}
-			out := vv["out"]
+			out, ok := vv["out"].(string)
+			if !ok && vv["out"] != nil {
+				return fmt.Errorf("invalid dump request: %v", vv)
+			}
+			format, ok := vv["format"].(string)
+			if !ok && vv["format"] != nil {
+				return fmt.Errorf("invalid dump request: %v", vv)
+			}
+			switch format {
+			case "", dumpFormatJSON, dumpFormatYAML, dumpFormatRaw:
+			default:
+				return fmt.Errorf("invalid dump format: %s", format)
+			}
 			step.dumpRequest = &dumpRequest{
-				expr: expr.(string),
-				out:  out.(string),
+				expr:   expr,
+				out:    out,
+				format: format,
 			}
 		default:
 			return fmt.Errorf("invalid dump request: %v", vv)
@@ -654,6 +1058,56 @@ func (o *operator) AppendStep(key string, s map[string]interface{}) error {
 			}
 			c.step = step
 			step.includeConfig = c
+		case k == callRunnerKey:
+			cr, err := newCallRunner(o)
+			if err != nil {
+				return err
+			}
+			step.callRunner = cr
+			c, err := parseCallConfig(v)
+			if err != nil {
+				return err
+			}
+			c.step = step
+			step.callConfig = c
+		case k == pingRunnerKey:
+			pr, err := newPingRunner(o)
+			if err != nil {
+				return err
+			}
+			step.pingRunner = pr
+			switch vv := v.(type) {
+			case string:
+				step.pingTargets = []string{vv}
+			case []interface{}:
+				for _, vvv := range vv {
+					name, ok := vvv.(string)
+					if !ok {
+						return fmt.Errorf("invalid ping target: %v", v)
+					}
+					step.pingTargets = append(step.pingTargets, name)
+				}
+			default:
+				return fmt.Errorf("invalid ping target: %v", v)
+			}
+			if len(step.pingTargets) == 0 {
+				return fmt.Errorf("invalid ping target: %v", v)
+			}
+		case k == waitRunnerKey:
+			wr, err := newWaitRunner(o)
+			if err != nil {
+				return err
+			}
+			step.waitRunner = wr
+			switch vv := v.(type) {
+			case map[string]interface{}:
+				step.waitConfig = vv
+			case string, int, int64, uint64:
+				// shorthand of `wait: { duration: 3sec }`
+				step.waitConfig = map[string]interface{}{"duration": vv}
+			default:
+				return fmt.Errorf("invalid wait: %v", v)
+			}
 		case k == execRunnerKey:
 			er, err := newExecRunner(o)
 			if err != nil {
@@ -717,6 +1171,16 @@ func (o *operator) AppendStep(key string, s map[string]interface{}) error {
 				step.sshCommand = vv
 				detected = true
 			}
+			cr, ok := o.customRunners[k]
+			if ok && !detected {
+				step.customRunner = cr
+				vv, ok := v.(map[string]interface{})
+				if !ok {
+					return fmt.Errorf("invalid %s request: %v", k, v)
+				}
+				step.customRequest = vv
+				detected = true
+			}
 
 			if !detected {
 				return fmt.Errorf("cannot find client: %s", k)
@@ -768,8 +1232,16 @@ func (o *operator) Result() *RunResult {
 	return o.runResult
 }
 
+// addBytes adds the bytes of the request and response bodies to the totals of the runbook.
+func (o *operator) addBytes(sent, received int64) {
+	o.bytesSent += sent
+	o.bytesReceived += received
+}
+
 func (o *operator) clearResult() {
 	o.runResult = newRunResult(o.desc, o.bookPathOrID())
+	o.runResult.masker = o.secretMasker
+	o.exported = nil
 	for _, s := range o.steps {
 		s.clearResult()
 	}
@@ -780,6 +1252,7 @@ func (o *operator) run(ctx context.Context) error {
 	if o.newOnly {
 		return errors.New("this runbook is not allowed to run")
 	}
+	o.currentInterval = o.interval
 	var err error
 	if o.t != nil {
 		// As test helper
@@ -787,9 +1260,12 @@ func (o *operator) run(ctx context.Context) error {
 		o.t.Run(o.testName(), func(t *testing.T) {
 			t.Helper()
 			o.thisT = t
-			if o.loop != nil {
+			switch {
+			case o.loop != nil:
 				err = o.runLoop(ctx)
-			} else {
+			case o.repeat > 1:
+				err = o.runRepeat(ctx)
+			default:
 				err = o.runInternal(ctx)
 			}
 			if err != nil {
@@ -802,9 +1278,12 @@ func (o *operator) run(ctx context.Context) error {
 		}
 		return nil
 	}
-	if o.loop != nil {
+	switch {
+	case o.loop != nil:
 		err = o.runLoop(ctx)
-	} else {
+	case o.repeat > 1:
+		err = o.runRepeat(ctx)
+	default:
 		err = o.runInternal(ctx)
 	}
 	if err != nil {
@@ -832,6 +1311,9 @@ func (o *operator) runLoop(ctx context.Context) error {
 		return err
 	}
 	var looperr error
+	if o.intervalMultiplier > 1 && o.loop.Until != "" {
+		o.loop.useIntervalBackoff(o.intervalMultiplier, o.maxInterval)
+	}
 	for o.loop.Loop(ctx) {
 		if j >= c {
 			break
@@ -870,6 +1352,7 @@ func (o *operator) runLoop(ctx context.Context) error {
 				retrySuccess = true
 				break
 			}
+			o.loop.failed()
 		}
 		j++
 	}
@@ -895,13 +1378,32 @@ func (o *operator) runInternal(ctx context.Context) (rerr error) {
 	}
 	o.clearResult()
 	o.store.clearSteps()
+	o.stepIdx = 0
+	o.stepRan = false
+	if o.requestCounter != nil {
+		o.requestCounter.reset()
+	}
+	o.bytesSent = 0
+	o.bytesReceived = 0
 
+	ranSteps := false
 	defer func() {
+		// teardown
+		if ranSteps {
+			if terr := o.runTeardown(ctx, rerr != nil); terr != nil {
+				// the failures of teardown steps do not mask the primary error
+				rerr = multierr.Append(rerr, terr)
+			}
+		}
+
 		// set run error and skipped
+		rerr = o.secretMasker.maskError(rerr)
 		o.runResult.Err = rerr
 		o.runResult.Skipped = o.Skipped()
 		o.runResult.Store = o.store.toMap()
 		o.runResult.StepResults = o.StepResults()
+		o.runResult.BytesSent = o.bytesSent
+		o.runResult.BytesReceived = o.bytesReceived
 
 		if o.Skipped() {
 			// If the scenario is skipped, beforeFuncs/afterFuncs are not executed
@@ -917,13 +1419,19 @@ func (o *operator) runInternal(ctx context.Context) (rerr error) {
 			idsi := ids.toInterfaceSlice()
 			o.sw.Start(idsi...)
 			if aferr := fn(o.runResult); aferr != nil {
-				rerr = newAfterFuncError(aferr)
+				rerr = o.secretMasker.maskError(newAfterFuncError(aferr))
 				o.runResult.Err = rerr
 			}
 			o.sw.Stop(idsi...)
 		}
 	}()
 
+	// labels, checkpoint and sampling
+	if o.skipLabels || o.resumed || o.unsampled {
+		o.skip()
+		return nil
+	}
+
 	// if
 	if o.ifCond != "" {
 		tf, err := o.expandCondBeforeRecord(o.ifCond)
@@ -954,9 +1462,15 @@ func (o *operator) runInternal(ctx context.Context) (rerr error) {
 	}
 
 	// steps
+	if o.shuffleStepsSeed != nil {
+		o.Debugf(o.yellow("Shuffle steps (seed: %d)\n"), *o.shuffleStepsSeed)
+		o.runResult.ShuffleStepsSeed = o.shuffleStepsSeed
+	}
+	ranSteps = true
 	failed := false
 	force := o.force
-	for i, s := range o.steps {
+	for _, i := range o.stepOrder() {
+		s := o.steps[i]
 		if failed && !force {
 			s.setResult(errStepSkiped)
 			o.recordNotRun(i)
@@ -964,6 +1478,11 @@ func (o *operator) runInternal(ctx context.Context) (rerr error) {
 			continue
 		}
 		err := o.runStep(ctx, i, s)
+		collected := false
+		if err != nil && o.collectExprErrors && isExprError(err) {
+			err = newStepExprError(o.stepName(i), s.source, err)
+			collected = true
+		}
 		s.setResult(err)
 		switch {
 		case errors.Is(errStepSkiped, err):
@@ -973,15 +1492,93 @@ func (o *operator) runInternal(ctx context.Context) (rerr error) {
 			o.recordNotRun(i)
 			o.recordToLatest(storeOutcomeKey, resultFailure)
 			rerr = multierr.Append(rerr, err)
-			failed = true
+			if !collected || s.fatal {
+				failed = true
+			}
+			if s.fatal {
+				o.Debugf(o.yellow("Stop running steps because the fatal step failed: %s\n"), o.stepName(i))
+				force = false
+			}
+			o.backoffInterval()
 		default:
 			o.recordToLatest(storeOutcomeKey, resultSuccess)
+			o.currentInterval = o.interval
+		}
+	}
+
+	// expectRequests
+	if rerr == nil && o.expectRequests != nil {
+		if got := o.requestCounter.total(); got != *o.expectRequests {
+			return fmt.Errorf("expectRequests failed on %s: expected %d requests, but got %d (%s)", o.bookPathOrID(), *o.expectRequests, got, o.requestCounter)
+		}
+	}
+
+	// expectNoRequests
+	if rerr == nil && len(o.expectNoRequests) > 0 {
+		if p, pattern := o.requestCounter.matchPath(o.expectNoRequests); p != "" {
+			return fmt.Errorf("expectNoRequests failed on %s: expected no requests matching %s, but %s was requested", o.bookPathOrID(), pattern, p)
+		}
+	}
+
+	// maxQueries
+	if rerr == nil && o.maxQueries != nil {
+		if got := o.requestCounter.totalQueries(); got > *o.maxQueries {
+			return fmt.Errorf("maxQueries failed on %s: expected at most %d queries, but got %d (%s)", o.bookPathOrID(), *o.maxQueries, got, o.requestCounter.queriesString())
+		}
+	}
+
+	// maxResponseBytesTotal
+	if rerr == nil && o.maxResponseBytesTotal != nil {
+		if o.bytesReceived > *o.maxResponseBytesTotal {
+			return fmt.Errorf("maxResponseBytesTotal failed on %s: expected at most %d bytes of responses, but got %d bytes", o.bookPathOrID(), *o.maxResponseBytesTotal, o.bytesReceived)
+		}
+	}
+
+	// baseline
+	if rerr == nil && o.baseline != nil {
+		if err := o.baseline.apply(o); err != nil {
+			return fmt.Errorf("baseline failed on %s: %w", o.bookPathOrID(), err)
+		}
+	}
+
+	// export
+	if rerr == nil && len(o.exports) > 0 {
+		exported, err := o.export()
+		if err != nil {
+			return fmt.Errorf("export failed on %s: %w", o.bookPathOrID(), err)
 		}
+		o.exported = exported
 	}
 
 	return
 }
 
+// export evaluates the expressions of `export:` with the store.
+func (o *operator) export() (map[string]interface{}, error) {
+	store := o.store.toMap()
+	exported := map[string]interface{}{}
+	for k, expr := range o.exports {
+		v, err := Eval(expr, store)
+		if err != nil {
+			return nil, err
+		}
+		exported[k] = v
+	}
+	return exported, nil
+}
+
+// backoffInterval multiplies the interval between steps by the multiplier up to the max interval.
+func (o *operator) backoffInterval() {
+	if o.intervalMultiplier <= 1 {
+		return
+	}
+	next := time.Duration(float64(o.currentInterval) * o.intervalMultiplier)
+	if o.maxInterval > 0 && next > o.maxInterval {
+		next = o.maxInterval
+	}
+	o.currentInterval = next
+}
+
 func (o *operator) bookPathOrID() string {
 	if o.bookPath != "" {
 		return o.bookPath
@@ -1014,6 +1611,15 @@ func (o *operator) expandBeforeRecord(in interface{}) (interface{}, error) {
 	store := o.store.toMap()
 	store[storeIncludedKey] = o.included
 	store[storePreviousKey] = o.store.latest()
+	in = o.store.resolveRelativeIndex(in)
+	if o.strictVars {
+		if err := findUndefinedVar(in, store); err != nil {
+			if o.stepIdx < len(o.steps) {
+				return nil, fmt.Errorf("%w on %s", err, o.stepName(o.stepIdx))
+			}
+			return nil, err
+		}
+	}
 	return EvalExpand(in, store)
 }
 
@@ -1022,7 +1628,7 @@ func (o *operator) expandCondBeforeRecord(ifCond string) (bool, error) {
 	store := o.store.toMap()
 	store[storeIncludedKey] = o.included
 	store[storePreviousKey] = o.store.latest()
-	return EvalCond(ifCond, store)
+	return EvalCond(o.store.resolveRelativeIndex(ifCond).(string), store)
 }
 
 // Debugln print to out when debug = true.
@@ -1030,7 +1636,7 @@ func (o *operator) Debugln(a interface{}) {
 	if !o.debug {
 		return
 	}
-	_, _ = fmt.Fprintln(o.stderr, a)
+	_, _ = fmt.Fprintln(o.stderr, o.secretMasker.mask(fmt.Sprint(a)))
 }
 
 // Debugf print to out when debug = true.
@@ -1038,7 +1644,15 @@ func (o *operator) Debugf(format string, a ...interface{}) {
 	if !o.debug {
 		return
 	}
-	_, _ = fmt.Fprintf(o.stderr, format, a...)
+	_, _ = fmt.Fprint(o.stderr, o.secretMasker.mask(fmt.Sprintf(format, a...)))
+}
+
+// Tracef print to out when debug = true or trace = true.
+func (o *operator) Tracef(format string, a ...interface{}) {
+	if !o.debug && !o.trace {
+		return
+	}
+	_, _ = fmt.Fprint(o.stderr, o.secretMasker.mask(fmt.Sprintf(format, a...)))
 }
 
 // Warnf print to out.
@@ -1052,7 +1666,7 @@ func (o *operator) Skipped() bool {
 }
 
 func (o *operator) skip() {
-	o.Debugf(yellow("Skip %s\n"), o.desc)
+	o.Debugf(o.yellow("Skip %s\n"), o.desc)
 	o.skipped = true
 	for i, s := range o.steps {
 		s.setResult(errStepSkiped)
@@ -1079,11 +1693,25 @@ type operators struct {
 	shardN      int
 	shardIndex  int
 	sample      int
+	sampleSeed  int64
 	random      int
 	concmax     int
 	opts        []Option
 	results     []*runNResult
 	runCount    int64
+	color       *bool
+	// carry exported values over to the following runbooks
+	carry bool
+	// include the store in the JSON output of the result
+	includeStore bool
+	// runbooks that passed in the checkpoint file of ResumeFrom
+	resumed []string
+	// progress written to the checkpoint file
+	checkpoint *checkpoint
+	// elapsed times of the steps shared by the runbooks
+	baseline *baseline
+	// writer of the progress of RunN
+	progressOut io.Writer
 	mu          sync.Mutex
 }
 
@@ -1096,21 +1724,45 @@ func Load(pathp string, opts ...Option) (*operators, error) {
 
 	sw := stopw.New()
 	ops := &operators{
-		t:           bk.t,
-		sw:          sw,
-		profile:     bk.profile,
-		shuffle:     bk.runShuffle,
-		shuffleSeed: bk.runShuffleSeed,
-		shardN:      bk.runShardN,
-		shardIndex:  bk.runShardIndex,
-		sample:      bk.runSample,
-		random:      bk.runRandom,
-		concmax:     1,
-		opts:        opts,
+		t:            bk.t,
+		sw:           sw,
+		profile:      bk.profile,
+		shuffle:      bk.runShuffle,
+		shuffleSeed:  bk.runShuffleSeed,
+		shardN:       bk.runShardN,
+		shardIndex:   bk.runShardIndex,
+		sample:       bk.runSample,
+		sampleSeed:   bk.runSampleSeed,
+		random:       bk.runRandom,
+		concmax:      1,
+		opts:         opts,
+		color:        bk.color,
+		carry:        bk.runCarry,
+		includeStore: bk.includeStoreInJSON,
+		progressOut:  bk.progressOut,
 	}
 	if bk.runConcurrent {
 		ops.concmax = bk.runConcurrentMax
 	}
+	if bk.resumeFromPath != "" {
+		cf, err := readCheckpointFile(bk.resumeFromPath)
+		if err != nil {
+			return nil, err
+		}
+		ops.resumed = cf.Passed
+	}
+	if bk.checkpointPath != "" {
+		ops.checkpoint = &checkpoint{path: bk.checkpointPath}
+		// keep the runbooks that passed before resuming
+		ops.checkpoint.passed = append(ops.checkpoint.passed, ops.resumed...)
+	}
+	if bk.baselinePath != "" {
+		b, err := newBaseline(bk.baselinePath, bk.updateBaseline, bk.baselineTolerance)
+		if err != nil {
+			return nil, err
+		}
+		ops.baseline = b
+	}
 	books, err := Books(pathp)
 	if err != nil {
 		return nil, err
@@ -1129,22 +1781,47 @@ func Load(pathp string, opts ...Option) (*operators, error) {
 				}
 			}
 		}
-		om[o.bookPath] = o
+		if len(o.matrix) == 0 {
+			om[o.bookPath] = o
+			continue
+		}
+		// The operator is used only to read `matrix:`, so close its runners ( e.g. SSH connections dialed while parsing )
+		o.Close()
+		// Expand one operator per combination of matrix
+		combs, err := matrixCombinations(o.matrix)
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range combs {
+			mo, err := newMatrixOperator(b, c, opts)
+			if err != nil {
+				return nil, err
+			}
+			om[fmt.Sprintf("%s (%s)", mo.bookPath, matrixName(c))] = mo
+		}
 	}
 
-	for p, o := range om {
+	for _, o := range om {
+		p := o.bookPath
 		if !bk.runMatch.MatchString(p) {
-			o.Debugf(yellow("Skip %s because it does not match %s\n"), p, bk.runMatch.String())
+			o.Debugf(o.yellow("Skip %s because it does not match %s\n"), p, bk.runMatch.String())
 			continue
 		}
 		if contains(skipPaths, p) {
-			o.Debugf(yellow("Skip %s because it is already included from another runbook\n"), p)
+			o.Debugf(o.yellow("Skip %s because it is already included from another runbook\n"), p)
 			continue
 		}
 		o.sw = ops.sw
 		ops.ops = append(ops.ops, o)
 	}
 
+	if bk.datasetPath != "" {
+		ops.ops, err = expandDataset(ops.ops, bk.datasetPath, bk.datasetKey, opts)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// Fix order of running
 	sortOperators(ops.ops)
 	return ops, nil
@@ -1213,6 +1890,12 @@ func (ops *operators) Result() *runNResult {
 }
 
 func (ops *operators) SelectedOperators() ([]*operator, error) {
+	selected, _, err := ops.selectOperators()
+	return selected, err
+}
+
+// selectOperators returns the operators to run and the operators that are not sampled by RunSample ( recorded as skipped ).
+func (ops *operators) selectOperators() ([]*operator, []*operator, error) {
 	var err error
 	rc := ops.runCount
 	atomic.AddInt64(&ops.runCount, 1)
@@ -1221,7 +1904,7 @@ func (ops *operators) SelectedOperators() ([]*operator, error) {
 	if rc > 0 && ops.random == 0 {
 		tops, err = copyOperators(tops, ops.opts)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 	}
 	if ops.shuffle {
@@ -1232,25 +1915,26 @@ func (ops *operators) SelectedOperators() ([]*operator, error) {
 	if ops.shardN > 0 {
 		tops = partOperators(tops, ops.shardN, ops.shardIndex)
 	}
+	var unsampled []*operator
 	if ops.sample > 0 {
-		tops = sampleOperators(tops, ops.sample)
+		tops, unsampled = sampleOperators(tops, ops.sample, ops.sampleSeed, ops.resumed)
 	}
 	if ops.random > 0 {
 		rops, err := randomOperators(tops, ops.opts, ops.random)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 		for _, o := range rops {
 			o.sw = ops.sw
 		}
-		return rops, nil
+		return rops, unsampled, nil
 	}
 
-	return tops, nil
+	return tops, unsampled, nil
 }
 
 func (ops *operators) runN(ctx context.Context) (*runNResult, error) {
-	result := &runNResult{}
+	result := &runNResult{color: ops.color, includeStore: ops.includeStore}
 	if ops.t != nil {
 		ops.t.Helper()
 	}
@@ -1261,11 +1945,23 @@ func (ops *operators) runN(ctx context.Context) (*runNResult, error) {
 	defer ops.Close()
 	cg, cctx := concgroup.WithContext(ctx)
 	cg.SetLimit(ops.concmax)
-	selected, err := ops.SelectedOperators()
+	selected, unsampled, err := ops.selectOperators()
 	if err != nil {
 		return result, err
 	}
+	for _, o := range unsampled {
+		o.unsampled = true
+	}
+	selected = append(selected, unsampled...)
 	result.Total.Add(int64(len(selected)))
+	var pg *progress
+	if ops.progressOut != nil {
+		pg = newProgress(ops.progressOut, ops.color)
+		result.progressed = true
+		defer pg.done()
+	}
+	var carryMu sync.Mutex
+	carry := map[string]interface{}{}
 	for _, o := range selected {
 		o := o
 		cg.Go(o.concurrency, func() error {
@@ -1278,7 +1974,28 @@ func (ops *operators) runN(ctx context.Context) (*runNResult, error) {
 				result.mu.Lock()
 				result.RunResults = append(result.RunResults, o.Result())
 				result.mu.Unlock()
+				if pg != nil {
+					pg.report(o.Result())
+				}
 			}()
+			if ops.carry {
+				carryMu.Lock()
+				for k, v := range carry {
+					o.store.bindVars[k] = v
+				}
+				carryMu.Unlock()
+				defer func() {
+					carryMu.Lock()
+					for k, v := range o.exported {
+						carry[k] = v
+					}
+					carryMu.Unlock()
+				}()
+			}
+			o.resumed = contains(ops.resumed, o.checkpointKey())
+			if ops.baseline != nil {
+				o.baseline = ops.baseline
+			}
 			o.capturers.captureStart(o.ids(), o.bookPath, o.desc)
 			if err := o.run(cctx); err != nil {
 				if o.failFast {
@@ -1289,6 +2006,11 @@ func (ops *operators) runN(ctx context.Context) (*runNResult, error) {
 			}
 			o.capturers.captureResult(o.ids(), o.Result())
 			o.capturers.captureEnd(o.ids(), o.bookPath, o.desc)
+			if ops.checkpoint != nil && o.Result().Err == nil && !o.Result().Skipped {
+				if err := ops.checkpoint.pass(o.checkpointKey()); err != nil {
+					return fmt.Errorf("failed to write the checkpoint file: %w", err)
+				}
+			}
 			return nil
 		})
 	}
@@ -1323,6 +2045,22 @@ func copyOperators(ops []*operator, opts []Option) ([]*operator, error) {
 	var c []*operator
 	for _, o := range ops {
 		// FIXME: Need the function to copy the operator as it is heavy to parse the runbook each time
+		if o.datasetVars != nil {
+			oo, err := newDatasetOperator(o, o.datasetVars, o.datasetName, opts)
+			if err != nil {
+				return nil, err
+			}
+			c = append(c, oo)
+			continue
+		}
+		if o.matrixVars != nil {
+			oo, err := newMatrixOperator(Book(o.bookPath), o.matrixVars, opts)
+			if err != nil {
+				return nil, err
+			}
+			c = append(c, oo)
+			continue
+		}
 		oo, err := New(append([]Option{Book(o.bookPath)}, opts...)...)
 		if err != nil {
 			return nil, err
@@ -1332,21 +2070,36 @@ func copyOperators(ops []*operator, opts []Option) ([]*operator, error) {
 	return c, nil
 }
 
-func sampleOperators(ops []*operator, num int) []*operator {
-	if len(ops) <= num {
-		return ops
+// sampleOperators samples num operators by the seed keeping the order, and returns the sampled operators and the rest.
+// The operators skipped by the labels or the checkpoint are not sampled, but kept to be recorded as skipped.
+func sampleOperators(ops []*operator, num int, seed int64, resumed []string) ([]*operator, []*operator) {
+	var candidates []int
+	for i, o := range ops {
+		if o.skipLabels || contains(resumed, o.checkpointKey()) {
+			continue
+		}
+		candidates = append(candidates, i)
 	}
-	r := rand.New(rand.NewSource(time.Now().UnixNano())) //nolint:gosec
-	var sample []*operator
-	n := make([]*operator, len(ops))
-	copy(n, ops)
-
-	for i := 0; i < num; i++ {
-		idx := r.Intn(len(n))
-		sample = append(sample, n[idx])
-		n = append(n[:idx], n[idx+1:]...)
+	if len(candidates) <= num {
+		return ops, nil
+	}
+	r := rand.New(rand.NewSource(seed)) //nolint:gosec
+	unsampled := map[int]struct{}{}
+	for _, j := range r.Perm(len(candidates))[num:] {
+		unsampled[candidates[j]] = struct{}{}
+	}
+	var (
+		sample []*operator
+		rest   []*operator
+	)
+	for i, o := range ops {
+		if _, ok := unsampled[i]; ok {
+			rest = append(rest, o)
+			continue
+		}
+		sample = append(sample, o)
 	}
-	return sample
+	return sample, rest
 }
 
 func randomOperators(ops []*operator, opts []Option, num int) ([]*operator, error) {
@@ -1381,6 +2134,28 @@ func pop(s map[string]interface{}) (string, interface{}, bool) {
 	return "", nil, false
 }
 
+func (o *operator) cyan(a ...interface{}) string {
+	return colorSprintFunc(color.FgCyan, o.color)(a...)
+}
+
+func (o *operator) yellow(a ...interface{}) string {
+	return colorSprintFunc(color.FgYellow, o.color)(a...)
+}
+
+// colorSprintFunc returns Sprint func with the color attribute.
+// If enabled is nil, it follows the default detection of fatih/color ( NO_COLOR, non-TTY ).
+func colorSprintFunc(attr color.Attribute, enabled *bool) func(a ...interface{}) string {
+	c := color.New(attr)
+	if enabled != nil {
+		if *enabled {
+			c.EnableColor()
+		} else {
+			c.DisableColor()
+		}
+	}
+	return c.SprintFunc()
+}
+
 func generateRunbookID() string {
 	return xid.New().String()
 }
@@ -1393,3 +2168,12 @@ func contains(s []string, e string) bool {
 	}
 	return false
 }
+
+func indexOf(s []string, e string) int {
+	for i, v := range s {
+		if e == v {
+			return i
+		}
+	}
+	return -1
+}
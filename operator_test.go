@@ -4,14 +4,19 @@ import (
 	"bytes"
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/http/httptest"
 	"os"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/golang-sql/sqlexp/nest"
 	"github.com/google/go-cmp/cmp"
@@ -20,6 +25,7 @@ import (
 	"github.com/k1LoW/runn/testutil"
 	"github.com/k1LoW/stopw"
 	"github.com/tenntenn/golden"
+	"go.uber.org/multierr"
 )
 
 var ErrDummy = errors.New("dummy")
@@ -316,6 +322,8 @@ func TestLoadOnly(t *testing.T) {
 }
 
 func TestRunN(t *testing.T) {
+	passed := []AssertionResult{{Cond: "true", Result: true}}
+	failed := []AssertionResult{{Cond: "false", Result: false}}
 	tests := []struct {
 		paths    string
 		RUNN_RUN string
@@ -326,17 +334,17 @@ func TestRunN(t *testing.T) {
 			{
 				Path:        "testdata/book/runn_0_success.yml",
 				Err:         nil,
-				StepResults: []*StepResult{{Key: "0", Err: nil}},
+				StepResults: []*StepResult{{Key: "0", Err: nil, Assertions: passed}},
 			},
 			{
 				Path:        "testdata/book/runn_1_fail.yml",
 				Err:         ErrDummy,
-				StepResults: []*StepResult{{Key: "0", Err: ErrDummy}},
+				StepResults: []*StepResult{{Key: "0", Err: ErrDummy, Assertions: failed}},
 			},
 			{
 				Path:        "testdata/book/runn_2_success.yml",
 				Err:         nil,
-				StepResults: []*StepResult{{Key: "0", Err: nil}},
+				StepResults: []*StepResult{{Key: "0", Err: nil, Assertions: passed}},
 			},
 			{
 				Path:        "testdata/book/runn_3.skip.yml",
@@ -349,19 +357,19 @@ func TestRunN(t *testing.T) {
 			{
 				Path:        "testdata/book/runn_0_success.yml",
 				Err:         nil,
-				StepResults: []*StepResult{{Key: "0", Err: nil}},
+				StepResults: []*StepResult{{Key: "0", Err: nil, Assertions: passed}},
 			},
 			{
 				Path:        "testdata/book/runn_1_fail.yml",
 				Err:         ErrDummy,
-				StepResults: []*StepResult{{Key: "0", Err: ErrDummy}},
+				StepResults: []*StepResult{{Key: "0", Err: ErrDummy, Assertions: failed}},
 			},
 		})},
 		{"testdata/book/runn_*", "runn_0", false, newRunNResult(t, 1, []*RunResult{
 			{
 				Path:        "testdata/book/runn_0_success.yml",
 				Err:         nil,
-				StepResults: []*StepResult{{Key: "0", Err: nil}},
+				StepResults: []*StepResult{{Key: "0", Err: nil, Assertions: passed}},
 			},
 		})},
 	}
@@ -375,7 +383,12 @@ func TestRunN(t *testing.T) {
 		_ = ops.RunN(ctx)
 		got := ops.Result().Simplify()
 		want := tt.want.Simplify()
-		if diff := cmp.Diff(got, want, nil); diff != "" {
+		for _, e := range got.Elapsed {
+			if e.Count != 1 || e.Min <= 0 {
+				t.Errorf("invalid elapsed stats: %#v", e)
+			}
+		}
+		if diff := cmp.Diff(got, want, cmpopts.IgnoreFields(runNResultSimplified{}, "Elapsed")); diff != "" {
 			t.Errorf("%s", diff)
 		}
 	}
@@ -402,6 +415,456 @@ func TestSkipIncluded(t *testing.T) {
 	}
 }
 
+func TestRunLabels(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        []Option
+		wantSkipped []string
+	}{
+		{"no filter", nil, nil},
+		{
+			"include",
+			[]Option{RunLabels([]string{"smoke"}, nil)},
+			[]string{"testdata/book/labels_none.yml", "testdata/book/labels_slow.yml"},
+		},
+		{
+			"include or",
+			[]Option{RunLabels([]string{"smoke", "slow"}, nil)},
+			[]string{"testdata/book/labels_none.yml"},
+		},
+		{
+			"exclude",
+			[]Option{RunLabels(nil, []string{"slow"})},
+			[]string{"testdata/book/labels_slow.yml"},
+		},
+		{
+			"include and exclude",
+			[]Option{RunLabels([]string{"api"}, []string{"smoke"})},
+			[]string{"testdata/book/labels_none.yml", "testdata/book/labels_smoke.yml"},
+		},
+		{
+			"multiple filters are and",
+			[]Option{RunLabels([]string{"api"}, nil), RunLabels([]string{"slow"}, nil)},
+			[]string{"testdata/book/labels_none.yml", "testdata/book/labels_smoke.yml"},
+		},
+	}
+	ctx := context.Background()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ops, err := Load("testdata/book/labels_*", tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := ops.RunN(ctx); err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, rr := range ops.Result().RunResults {
+				if rr.Skipped {
+					got = append(got, rr.Path)
+				}
+			}
+			sort.Strings(got)
+			if diff := cmp.Diff(got, tt.wantSkipped); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestRunSample(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        []Option
+		wantRun     int
+		wantSkipped int
+		wantRunFrom []string
+	}{
+		{
+			"sample",
+			[]Option{RunSample(1, 1)},
+			1,
+			2,
+			[]string{"testdata/book/labels_none.yml", "testdata/book/labels_slow.yml", "testdata/book/labels_smoke.yml"},
+		},
+		{
+			"sample more than runbooks",
+			[]Option{RunSample(5, 1)},
+			3,
+			0,
+			[]string{"testdata/book/labels_none.yml", "testdata/book/labels_slow.yml", "testdata/book/labels_smoke.yml"},
+		},
+		{
+			"sample from the runbooks matching the labels",
+			[]Option{RunLabels(nil, []string{"slow"}), RunSample(1, 2)},
+			1,
+			2,
+			[]string{"testdata/book/labels_none.yml", "testdata/book/labels_smoke.yml"},
+		},
+		{
+			"sample from the shard",
+			[]Option{RunShard(2, 0), RunSample(1, 3)},
+			1,
+			1,
+			[]string{"testdata/book/labels_none.yml", "testdata/book/labels_smoke.yml"},
+		},
+	}
+	ctx := context.Background()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var prev []string
+			// the same runbooks are sampled by the same seed
+			for i := 0; i < 2; i++ {
+				ops, err := Load("testdata/book/labels_*", tt.opts...)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if err := ops.RunN(ctx); err != nil {
+					t.Fatal(err)
+				}
+				var (
+					run     []string
+					skipped int
+				)
+				for _, rr := range ops.Result().RunResults {
+					if rr.Skipped {
+						skipped++
+						continue
+					}
+					run = append(run, rr.Path)
+				}
+				sort.Strings(run)
+				if len(run) != tt.wantRun || skipped != tt.wantSkipped {
+					t.Errorf("got %d run ( %v ) and %d skipped\nwant %d run and %d skipped", len(run), run, skipped, tt.wantRun, tt.wantSkipped)
+				}
+				for _, p := range run {
+					if !contains(tt.wantRunFrom, p) {
+						t.Errorf("%s should not be run", p)
+					}
+				}
+				if prev != nil {
+					if diff := cmp.Diff(run, prev); diff != "" {
+						t.Error(diff)
+					}
+				}
+				prev = run
+			}
+		})
+	}
+}
+
+func TestSkipStepLabels(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        []Option
+		wantSkipped []bool
+	}{
+		{"no labels", nil, []bool{false, false, false, false}},
+		{"skip slow", []Option{SkipStepLabels("slow")}, []bool{false, true, false, false}},
+		{"skip slow and unknown", []Option{SkipStepLabels("slow", "unknown")}, []bool{false, true, false, false}},
+	}
+	ctx := context.Background()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := append([]Option{Book("testdata/step_labels.yml")}, tt.opts...)
+			o, err := New(opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := o.Run(ctx); err != nil {
+				t.Fatal(err)
+			}
+			var got []bool
+			for _, sr := range o.Result().StepResults {
+				got = append(got, sr.Skipped)
+			}
+			if diff := cmp.Diff(got, tt.wantSkipped); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestRunCarry(t *testing.T) {
+	tests := []struct {
+		carry   bool
+		wantErr bool
+	}{
+		{true, false},
+		{false, true},
+	}
+	ctx := context.Background()
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%v", tt.carry), func(t *testing.T) {
+			ops, err := Load("testdata/book/carry_*", RunCarry(tt.carry))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := ops.RunN(ctx); err != nil {
+				t.Fatal(err)
+			}
+			if got := ops.Result().HasFailure(); got != tt.wantErr {
+				t.Errorf("got %v\nwant %v", got, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestIntervalBackoff(t *testing.T) {
+	t.Run("backoff", func(t *testing.T) {
+		o, err := New(Interval(10*time.Millisecond), IntervalBackoff(2, 50*time.Millisecond))
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := []time.Duration{20 * time.Millisecond, 40 * time.Millisecond, 50 * time.Millisecond, 50 * time.Millisecond}
+		for _, w := range want {
+			o.backoffInterval()
+			if o.currentInterval != w {
+				t.Errorf("got %v\nwant %v", o.currentInterval, w)
+			}
+		}
+	})
+
+	tests := []struct {
+		book string
+		want time.Duration
+	}{
+		{"testdata/book/runn_1_fail.yml", 2 * time.Millisecond},
+		{"testdata/book/always_failure.yml", 1 * time.Millisecond},
+	}
+	ctx := context.Background()
+	for _, tt := range tests {
+		t.Run(tt.book, func(t *testing.T) {
+			o, err := New(Book(tt.book), Force(true), Interval(1*time.Millisecond), IntervalBackoff(2, 0))
+			if err != nil {
+				t.Fatal(err)
+			}
+			_ = o.Run(ctx)
+			if o.currentInterval != tt.want {
+				t.Errorf("got %v\nwant %v", o.currentInterval, tt.want)
+			}
+		})
+	}
+
+	t.Run("invalid multiplier", func(t *testing.T) {
+		if _, err := New(IntervalBackoff(0.5, 0)); err == nil {
+			t.Error("want error")
+		}
+	})
+
+	t.Run("retries of loop", func(t *testing.T) {
+		o, err := New(Book("testdata/book/loop_interval_backoff.yml"), IntervalBackoff(2, 50*time.Millisecond))
+		if err != nil {
+			t.Fatal(err)
+		}
+		start := time.Now()
+		if err := o.Run(ctx); err == nil {
+			t.Error("want error")
+		}
+		// 20ms + 40ms + 50ms between 4 attempts ( 30ms without IntervalBackoff )
+		if got, want := time.Since(start), 110*time.Millisecond; got < want {
+			t.Errorf("got %v\nwant >= %v", got, want)
+		}
+	})
+}
+
+func TestExpectRequests(t *testing.T) {
+	tests := []struct {
+		count   int
+		wantErr string
+	}{
+		{2, ""},
+		{1, "expected 3 requests, but got 2 (req: 2)"},
+		{3, "expected 3 requests, but got 4 (req: 4)"},
+	}
+	ctx := context.Background()
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("count %d", tt.count), func(t *testing.T) {
+			ts := testutil.HTTPServer(t)
+			t.Setenv("TEST_HTTP_END_POINT", ts.URL)
+			o, err := New(Book("testdata/book/expect_requests.yml"), Var("count", tt.count))
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = o.Run(ctx)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Error(err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("got %v\nwant %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestExpectNoRequests(t *testing.T) {
+	tests := []struct {
+		id      int
+		wantErr string
+	}{
+		{1, ""},
+		{2, "expected no requests matching /users/2, but /users/2 was requested"},
+	}
+	ctx := context.Background()
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("id %d", tt.id), func(t *testing.T) {
+			ts := testutil.HTTPServer(t)
+			t.Setenv("TEST_HTTP_END_POINT", ts.URL)
+			o, err := New(Book("testdata/book/expect_no_requests.yml"), Var("id", tt.id))
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = o.Run(ctx)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Error(err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("got %v\nwant %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMaxQueries(t *testing.T) {
+	tests := []struct {
+		count   int
+		wantErr string
+	}{
+		{1, ""},
+		{2, ""},
+		{3, "expected at most 4 queries, but got 5 (db: 5)"},
+	}
+	ctx := context.Background()
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("count %d", tt.count), func(t *testing.T) {
+			_, dsn := testutil.SQLite(t)
+			o, err := New(Book("testdata/max_queries.yml"), Runner("db", dsn), Var("count", tt.count))
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = o.Run(ctx)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Error(err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("got %v\nwant %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMaxResponseBytesTotal(t *testing.T) {
+	tests := []struct {
+		size    int
+		wantErr string
+	}{
+		{10, ""},
+		{20, ""},
+		{21, "expected at most 30 bytes of responses, but got 31 bytes"},
+	}
+	ctx := context.Background()
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("size %d", tt.size), func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				n, _ := strconv.Atoi(r.URL.Query().Get("n"))
+				_, _ = w.Write(bytes.Repeat([]byte("a"), n))
+			}))
+			t.Cleanup(ts.Close)
+			t.Setenv("TEST_HTTP_END_POINT", ts.URL)
+			o, err := New(Book("testdata/max_response_bytes_total.yml"), Var("size", tt.size))
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = o.Run(ctx)
+			if got, want := o.Result().BytesReceived, int64(tt.size+10); got != want {
+				t.Errorf("got %v bytes received\nwant %v", got, want)
+			}
+			if got, want := o.Result().BytesSent, int64(5); got != want {
+				t.Errorf("got %v bytes sent\nwant %v", got, want)
+			}
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Error(err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("got %v\nwant %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTrace(t *testing.T) {
+	tests := []struct {
+		trace       bool
+		debug       bool
+		wantTrace   bool
+		wantStepRun bool
+	}{
+		{false, false, false, false},
+		{true, false, true, false},
+		{false, true, true, true},
+	}
+	ctx := context.Background()
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("trace %v debug %v", tt.trace, tt.debug), func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			o, err := New(Book("testdata/book/exec.yml"), Trace(tt.trace), Debug(tt.debug), Stderr(buf))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := o.Run(ctx); err != nil {
+				t.Fatal(err)
+			}
+			got := buf.String()
+			if strings.Contains(got, "-----START COMMAND-----\necho hello world!!") != tt.wantTrace {
+				t.Errorf("got %q", got)
+			}
+			if strings.Contains(got, "Run 'exec' on") != tt.wantStepRun {
+				t.Errorf("got %q", got)
+			}
+		})
+	}
+}
+
+func TestRecordMappedSteps(t *testing.T) {
+	ctx := context.Background()
+	o, err := New(Book("testdata/book/map_interleaved.yml"), Stdout(io.Discard))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := o.Run(ctx); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"hello", "check_hello", "world", "check_world", "dump_world", "bye"}
+	if diff := cmp.Diff(o.store.stepMapKeys, want, nil); diff != "" {
+		t.Errorf("%s", diff)
+	}
+	for _, k := range want {
+		v, ok := o.store.stepMap[k]
+		if !ok {
+			t.Errorf("%s is not recorded", k)
+			continue
+		}
+		if v[storeStepRunKey] != true {
+			t.Errorf("%s: got %v want %v", k, v[storeStepRunKey], true)
+		}
+	}
+	if got := o.store.stepMap["world"]["stdout"]; got != "world2" {
+		t.Errorf("got %v want %v", got, "world2")
+	}
+}
+
 func TestSkipTest(t *testing.T) {
 	tests := []struct {
 		book string
@@ -420,6 +883,37 @@ func TestSkipTest(t *testing.T) {
 	}
 }
 
+func TestStepResultSource(t *testing.T) {
+	tests := []struct {
+		book string
+		want []int
+	}{
+		{"testdata/book/always_failure.yml", []int{3, 5, 7}},
+		{"testdata/book/runn_1_fail.yml", []int{3}},
+	}
+	ctx := context.Background()
+	for _, tt := range tests {
+		t.Run(tt.book, func(t *testing.T) {
+			o, err := New(Book(tt.book))
+			if err != nil {
+				t.Fatal(err)
+			}
+			_ = o.Run(ctx)
+			for i, sr := range o.StepResults() {
+				if sr == nil {
+					continue
+				}
+				if sr.Path != tt.book {
+					t.Errorf("got %v\nwant %v", sr.Path, tt.book)
+				}
+				if sr.Line != tt.want[i] {
+					t.Errorf("got %v\nwant %v", sr.Line, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
 func TestHookFuncTest(t *testing.T) {
 	count := 0
 	tests := []struct {
@@ -551,7 +1045,7 @@ func TestShard(t *testing.T) {
 				operator{}, httpRunner{}, dbRunner{}, grpcRunner{}, cdpRunner{}, sshRunner{},
 			}
 			ignore := []interface{}{
-				step{}, store{}, sql.DB{}, os.File{}, stopw.Span{}, debugger{}, nest.DB{}, Loop{},
+				step{}, store{}, sql.DB{}, os.File{}, stopw.Span{}, debugger{}, requestCounter{}, nest.DB{}, Loop{}, RunResult{},
 			}
 			dopts := []cmp.Option{
 				cmp.AllowUnexported(allow...),
@@ -904,6 +1398,7 @@ func TestStepResult(t *testing.T) {
 		{"testdata/book/force.yml", false, []*StepResult{{Skipped: false, Err: nil}, {Skipped: false, Err: errors.New("some error")}, {Skipped: false, Err: nil}}},
 		{"testdata/book/always_failure.yml", true, []*StepResult{{Skipped: false, Err: nil}, {Skipped: false, Err: errors.New("some error")}, {Skipped: false, Err: nil}}},
 		{"testdata/book/only_if_included.yml", true, []*StepResult{{Skipped: true, Err: nil}, {Skipped: true, Err: nil}}},
+		{"testdata/book/fatal.yml", false, []*StepResult{{Skipped: false, Err: nil}, {Skipped: false, Err: errors.New("some error")}, {Skipped: false, Err: errors.New("some error")}, {Skipped: true, Err: nil}}},
 	}
 	ctx := context.Background()
 	for _, tt := range tests {
@@ -946,6 +1441,7 @@ func TestStepOutcome(t *testing.T) {
 		{"testdata/book/only_if_included.yml", false, []result{resultSkipped, resultSkipped}},
 		{"testdata/book/always_failure.yml", true, []result{resultSuccess, resultFailure, resultSuccess}},
 		{"testdata/book/only_if_included.yml", true, []result{resultSkipped, resultSkipped}},
+		{"testdata/book/fatal.yml", false, []result{resultSuccess, resultFailure, resultFailure, resultSkipped}},
 	}
 	ctx := context.Background()
 	for _, tt := range tests {
@@ -1032,3 +1528,198 @@ func newRunNResult(t *testing.T, total int64, results []*RunResult) *runNResult
 	r.RunResults = results
 	return r
 }
+
+func TestRepeat(t *testing.T) {
+	tests := []struct {
+		insert  string
+		wantErr string
+	}{
+		{"INSERT OR IGNORE INTO users (id, username) VALUES (1, 'alice');", ""},
+		{"INSERT INTO users (username) VALUES ('alice');", "repeatTest failed on testdata/repeat.yml"},
+		{"INSERT INTO users (id, username) VALUES (1, 'alice');", "repeat[1]: "},
+	}
+	ctx := context.Background()
+	for _, tt := range tests {
+		t.Run(tt.insert, func(t *testing.T) {
+			_, dsn := testutil.SQLite(t)
+			o, err := New(Book("testdata/repeat.yml"), Runner("db", dsn), Var("insert", tt.insert))
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = o.Run(ctx)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Error(err)
+				}
+			} else if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("got %v\nwant %v", err, tt.wantErr)
+			}
+			r := o.Result()
+			if len(r.Iterations) != 2 {
+				t.Fatalf("got %d iterations", len(r.Iterations))
+			}
+			if r.Iterations[1] != nil && r.StepResults[1] != r.Iterations[1].StepResults[1] {
+				t.Error("the result should be the result of the last iteration")
+			}
+		})
+	}
+}
+
+func TestTeardown(t *testing.T) {
+	tests := []struct {
+		name         string
+		fail         bool
+		failTeardown bool
+		wantSkipped  []bool
+		wantErrs     []string
+	}{
+		{"success", false, false, []bool{false, true, false, false}, nil},
+		{"failure", true, false, []bool{false, false, true, false}, []string{"steps[0]"}},
+		{"teardown failure", false, true, []bool{false, true, false, false}, []string{"teardown.always failed"}},
+		{"failure and teardown failure", true, true, []bool{false, false, true, false}, []string{"steps[0]", "teardown.always failed"}},
+	}
+	ctx := context.Background()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o, err := New(Book("testdata/teardown.yml"), Var("fail", tt.fail), Var("failTeardown", tt.failTeardown))
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = o.Run(ctx)
+			if len(tt.wantErrs) == 0 {
+				if err != nil {
+					t.Fatal(err)
+				}
+			} else {
+				if err == nil {
+					t.Fatal("want error")
+				}
+				// the primary error comes first
+				errs := multierr.Errors(o.Result().Err)
+				if len(errs) != len(tt.wantErrs) {
+					t.Fatalf("got %d errors, want %d: %v", len(errs), len(tt.wantErrs), err)
+				}
+				for i, want := range tt.wantErrs {
+					if !strings.Contains(errs[i].Error(), want) {
+						t.Errorf("got %v\nwant %v", errs[i], want)
+					}
+				}
+			}
+			var got []bool
+			for _, sr := range o.Result().StepResults {
+				got = append(got, sr.Skipped)
+			}
+			if diff := cmp.Diff(got, tt.wantSkipped); diff != "" {
+				t.Error(diff)
+			}
+			if got := len(o.store.steps); got != 4 {
+				t.Errorf("got %v\nwant %v", got, 4)
+			}
+		})
+	}
+}
+
+func TestTemplates(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b := map[string]interface{}{}
+		if err := json.NewDecoder(r.Body).Decode(&b); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		b["authorization"] = r.Header.Get("Authorization")
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(b)
+	}))
+	t.Cleanup(ts.Close)
+	t.Setenv("TEST_HTTP_END_POINT", ts.URL)
+	o, err := New(Book("testdata/templates.yml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := o.Run(context.Background()); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestTemplatesInvalid(t *testing.T) {
+	tests := []struct {
+		step    map[string]interface{}
+		wantErr bool
+	}{
+		{map[string]interface{}{"use": "check"}, false},
+		{map[string]interface{}{"use": "check", "desc": "override"}, false},
+		{map[string]interface{}{"use": "unknown"}, true},
+		{map[string]interface{}{"use": 1}, true},
+		{map[string]interface{}{"use": "check", "exec": map[string]interface{}{"command": "echo"}}, true},
+	}
+	for _, tt := range tests {
+		o, err := New(HTTPRunner("req", "https://api.example.com", nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		o.templates = map[string]map[string]interface{}{
+			"check": {"req": map[string]interface{}{"/": map[string]interface{}{"get": map[string]interface{}{"body": nil}}}},
+		}
+		err = o.AppendStep("0", tt.step)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("got %v\nwantErr %v", err, tt.wantErr)
+		}
+	}
+}
+
+func TestTransform(t *testing.T) {
+	o, err := New(Book("testdata/transform.yml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := o.Run(context.Background()); err != nil {
+		t.Error(err)
+	}
+	got := o.store.stepMap["users"][storeTransformedKey]
+	want := []interface{}{"alice", "bob"}
+	if diff := cmp.Diff(got, want, nil); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestCollectExprErrors(t *testing.T) {
+	tests := []struct {
+		collect     bool
+		wantErrs    int
+		wantSkipped []bool
+	}{
+		{true, 3, []bool{false, false, false, false, false}},
+		{false, 1, []bool{false, true, true, true, true}},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%v", tt.collect), func(t *testing.T) {
+			o, err := New(Book("testdata/collect_expr_errors.yml"), CollectExprErrors(tt.collect))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := o.Run(context.Background()); err == nil {
+				t.Error("want error")
+			}
+			errs := multierr.Errors(o.Result().Err)
+			if len(errs) != tt.wantErrs {
+				t.Errorf("got %d errors\nwant %d: %v", len(errs), tt.wantErrs, errs)
+			}
+			if tt.collect {
+				for _, err := range errs {
+					var se *StepExprError
+					if !errors.As(err, &se) {
+						t.Errorf("got %v\nwant *StepExprError", err)
+					}
+				}
+			}
+			got := []bool{}
+			for _, sr := range o.Result().StepResults {
+				got = append(got, sr.Skipped)
+			}
+			if diff := cmp.Diff(got, tt.wantSkipped); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
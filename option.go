@@ -1,6 +1,7 @@
 package runn
 
 import (
+	"database/sql"
 	"encoding/base64"
 	"errors"
 	"fmt"
@@ -11,6 +12,7 @@ import (
 	"path/filepath"
 	"regexp"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -36,7 +38,20 @@ func Book(path string) Option {
 	}
 }
 
+// BookFromMap - Load runbook from the map ( desc, runners, vars, steps, ... ) without reading a file.
+// To use mapped steps, set `steps:` as yaml.MapSlice ( gopkg.in/yaml.v2 ) to keep the order of steps.
+func BookFromMap(m map[string]interface{}) Option {
+	return func(bk *book) error {
+		loaded, err := loadBookFromMap(m)
+		if err != nil {
+			return err
+		}
+		return bk.merge(loaded)
+	}
+}
+
 // Overlay - Overlay values on a runbook.
+// `vars:` and mapped steps with the same keys are deep-merged ( maps are merged and arrays are replaced ). The other steps are appended.
 func Overlay(path string) Option {
 	return func(bk *book) error {
 		if len(bk.rawSteps) == 0 {
@@ -46,7 +61,9 @@ func Overlay(path string) Option {
 		if err != nil {
 			return err
 		}
-		bk.desc = loaded.desc
+		if loaded.desc != noDesc {
+			bk.desc = loaded.desc
+		}
 		bk.ifCond = loaded.ifCond
 		if len(loaded.rawSteps) > 0 {
 			if bk.useMap != loaded.useMap {
@@ -71,14 +88,28 @@ func Overlay(path string) Option {
 		for k, r := range loaded.sshRunners {
 			bk.sshRunners[k] = r
 		}
-		for k, v := range loaded.vars {
-			bk.vars[k] = v
-		}
+		bk.vars = deepMerge(bk.vars, loaded.vars)
+		bk.secrets = deepMerge(bk.secrets, loaded.secrets)
 		for k, e := range loaded.runnerErrs {
 			bk.runnerErrs[k] = e
 		}
-		bk.rawSteps = append(bk.rawSteps, loaded.rawSteps...)
-		bk.stepKeys = append(bk.stepKeys, loaded.stepKeys...)
+		for i, s := range loaded.rawSteps {
+			if bk.useMap {
+				if j := indexOf(bk.stepKeys, loaded.stepKeys[i]); j >= 0 {
+					merged := deepMerge(bk.rawSteps[j], s)
+					if err := validateStepKeys(merged); err != nil {
+						return fmt.Errorf("invalid step '%s' overlaid by %s: %w", loaded.stepKeys[i], path, err)
+					}
+					bk.rawSteps[j] = merged
+					continue
+				}
+				bk.stepKeys = append(bk.stepKeys, loaded.stepKeys[i])
+			}
+			bk.rawSteps = append(bk.rawSteps, s)
+			if i < len(loaded.stepSources) {
+				bk.stepSources = append(bk.stepSources, loaded.stepSources[i])
+			}
+		}
 		bk.debug = loaded.debug
 		bk.skipTest = loaded.skipTest
 		bk.loop = loaded.loop
@@ -145,11 +176,17 @@ func Underlay(path string) Option {
 				bk.vars[k] = v
 			}
 		}
+		for k, v := range loaded.secrets {
+			if _, ok := bk.secrets[k]; !ok {
+				bk.secrets[k] = v
+			}
+		}
 		for k, e := range loaded.runnerErrs {
 			bk.runnerErrs[k] = e
 		}
 		bk.rawSteps = append(loaded.rawSteps, bk.rawSteps...)
 		bk.stepKeys = append(loaded.stepKeys, bk.stepKeys...)
+		bk.stepSources = append(loaded.stepSources, bk.stepSources...)
 		if bk.intervalStr == "" {
 			bk.interval = loaded.interval
 		}
@@ -176,6 +213,8 @@ func Runner(name, dsn string, opts ...httpRunnerOption) Option {
 				return err
 			}
 			if err := bk.parseRunner(name, dsn); err != nil {
+				// keep DSN to re-parse with drivers registered by RegisterDBDriver
+				bk.runners[name] = dsn
 				bk.runnerErrs[name] = err
 			}
 			return nil
@@ -197,6 +236,20 @@ func Runner(name, dsn string, opts ...httpRunnerOption) Option {
 			r.client.CheckRedirect = notFollowRedirectFn
 		}
 		r.multipartBoundary = c.MultipartBoundary
+		r.defaultContentType = c.DefaultContentType
+		r.basePath = c.BasePath
+		r.headers = c.Headers
+		if len(c.Protos) > 0 {
+			root, err := bk.generateOperatorRoot()
+			if err != nil {
+				return err
+			}
+			r.protos, err = parseProtos(c.Protos, c.ImportPaths, root)
+			if err != nil {
+				bk.runnerErrs[name] = err
+				return nil
+			}
+		}
 		if c.OpenApi3DocLocation != "" {
 			v, err := newHttpValidator(c)
 			if err != nil {
@@ -239,6 +292,9 @@ func HTTPRunner(name, endpoint string, client *http.Client, opts ...httpRunnerOp
 			r.client.CheckRedirect = notFollowRedirectFn
 		}
 		r.multipartBoundary = c.MultipartBoundary
+		r.defaultContentType = c.DefaultContentType
+		r.basePath = c.BasePath
+		r.headers = c.Headers
 		if c.OpenApi3DocLocation != "" && !strings.HasPrefix(c.OpenApi3DocLocation, "https://") && !strings.HasPrefix(c.OpenApi3DocLocation, "http://") && !strings.HasPrefix(c.OpenApi3DocLocation, "/") {
 			c.OpenApi3DocLocation = fp(c.OpenApi3DocLocation, root)
 		}
@@ -263,6 +319,10 @@ func HTTPRunner(name, endpoint string, client *http.Client, opts ...httpRunnerOp
 			}
 			r.key = b
 		}
+		r.protos, err = parseProtos(c.Protos, c.ImportPaths, root)
+		if err != nil {
+			return err
+		}
 		hv, err := newHttpValidator(c)
 		if err != nil {
 			bk.runnerErrs[name] = err
@@ -295,6 +355,20 @@ func HTTPRunnerWithHandler(name string, h http.Handler, opts ...httpRunnerOption
 				return nil
 			}
 			r.multipartBoundary = c.MultipartBoundary
+			r.defaultContentType = c.DefaultContentType
+			r.basePath = c.BasePath
+			r.headers = c.Headers
+			if len(c.Protos) > 0 {
+				root, err := bk.generateOperatorRoot()
+				if err != nil {
+					return err
+				}
+				r.protos, err = parseProtos(c.Protos, c.ImportPaths, root)
+				if err != nil {
+					bk.runnerErrs[name] = err
+					return nil
+				}
+			}
 			v, err := newHttpValidator(c)
 			if err != nil {
 				bk.runnerErrs[name] = err
@@ -308,17 +382,82 @@ func HTTPRunnerWithHandler(name string, h http.Handler, opts ...httpRunnerOption
 }
 
 // DBRunner - Set DB runner to runbook.
-func DBRunner(name string, client Querier) Option {
+func DBRunner(name string, client Querier, opts ...dbRunnerOption) Option {
 	return func(bk *book) error {
 		delete(bk.runnerErrs, name)
 		nt, err := nestTx(client)
 		if err != nil {
 			return err
 		}
-		bk.dbRunners[name] = &dbRunner{
+		r := &dbRunner{
 			name:   name,
 			client: nt,
 		}
+		bk.dbRunners[name] = r
+		if len(opts) == 0 {
+			return nil
+		}
+		c := &dbRunnerConfig{}
+		for _, opt := range opts {
+			if err := opt(c); err != nil {
+				bk.runnerErrs[name] = err
+				return nil
+			}
+		}
+		r.timeLayouts = c.TimeLayouts
+		r.numericAs = c.NumericAs
+		return nil
+	}
+}
+
+// DBRunnerGroup - Set DB runner group to runbook.
+// The DB runner group routes the read-only statements ( e.g. `SELECT`, not `SELECT ... FOR UPDATE` ) to the DB runner named read and the other statements to the DB runner named write.
+func DBRunnerGroup(name, read, write string) Option {
+	return func(bk *book) error {
+		delete(bk.runnerErrs, name)
+		if read == "" || write == "" {
+			bk.runnerErrs[name] = fmt.Errorf("DB runner group requires both read and write: %s", name)
+			return nil
+		}
+		bk.dbRunners[name] = &dbRunner{
+			name:  name,
+			group: &dbRunnerGroup{read: read, write: write},
+		}
+		return nil
+	}
+}
+
+// RegisterRunner - Register the custom runner to run steps with the key of name.
+func RegisterRunner(name string, r CustomRunner) Option {
+	return func(bk *book) error {
+		if err := validateRunnerKey(name); err != nil {
+			return err
+		}
+		cr, err := newCustomRunner(name, r)
+		if err != nil {
+			return err
+		}
+		delete(bk.runnerErrs, name)
+		if bk.customRunners == nil {
+			bk.customRunners = map[string]*customRunner{}
+		}
+		bk.customRunners[name] = cr
+		return nil
+	}
+}
+
+// RegisterDBDriver - Register the database/sql driver to be used for DB runners with the DSN of `scheme://`.
+// The DSN is passed to sql.Open as it is.
+func RegisterDBDriver(scheme, driverName string) Option {
+	return func(bk *book) error {
+		if !contains(sql.Drivers(), driverName) {
+			return fmt.Errorf("database/sql driver is not registered: %s", driverName)
+		}
+		if bk.dbDrivers == nil {
+			bk.dbDrivers = map[string]string{}
+		}
+		bk.dbDrivers[scheme] = driverName
+		bk.parseRunnersWithDBDrivers()
 		return nil
 	}
 }
@@ -534,6 +673,42 @@ func Var(k interface{}, v interface{}) Option {
 	}
 }
 
+// Secret - Set secret to runbook.
+// The value is referenced as `secrets.<key>` in the same way as `vars`, but masked in debug output, captures and results.
+// The value of the secret file can be loaded with "json://".
+func Secret(k string, v interface{}) Option {
+	return func(bk *book) error {
+		root, err := bk.generateOperatorRoot()
+		if err != nil {
+			return err
+		}
+		ev, err := evaluateSchema(v, root, nil)
+		if err != nil {
+			return err
+		}
+		bk.secrets[k] = ev
+		return nil
+	}
+}
+
+// SecretsFromEnv - Set the environment variables with the prefix as secrets.
+// The key of the secret is the name of the environment variable without the prefix ( e.g. RUNN_SECRET_API_KEY => secrets.API_KEY ).
+func SecretsFromEnv(prefix string) Option {
+	return func(bk *book) error {
+		if prefix == "" {
+			return errors.New("prefix of environment variables for secrets is required")
+		}
+		for _, e := range os.Environ() {
+			k, v, ok := strings.Cut(e, "=")
+			if !ok || !strings.HasPrefix(k, prefix) || k == prefix {
+				continue
+			}
+			bk.secrets[strings.TrimPrefix(k, prefix)] = v
+		}
+		return nil
+	}
+}
+
 // Func - Set function to runner.
 func Func(k string, v interface{}) Option {
 	return func(bk *book) error {
@@ -552,6 +727,16 @@ func Debug(debug bool) Option {
 	}
 }
 
+// Trace - Enable trace output of requests and responses of runners ( without debug output of steps ).
+func Trace(trace bool) Option {
+	return func(bk *book) error {
+		if !bk.trace {
+			bk.trace = trace
+		}
+		return nil
+	}
+}
+
 // Profile - Enable profile output.
 func Profile(profile bool) Option {
 	return func(bk *book) error {
@@ -573,6 +758,22 @@ func Interval(d time.Duration) Option {
 	}
 }
 
+// IntervalBackoff - Multiply the interval between steps by the multiplier each time a step fails, up to max ( 0 means no limit ). The interval is reset when a step succeeds.
+// It also multiplies the interval between the retries of `loop:` each time an attempt fails, and resets it when an attempt succeeds.
+func IntervalBackoff(multiplier float64, max time.Duration) Option {
+	return func(bk *book) error {
+		if multiplier < 1 {
+			return fmt.Errorf("invalid interval multiplier: %v", multiplier)
+		}
+		if max < 0 {
+			return fmt.Errorf("invalid max interval: %s", max)
+		}
+		bk.intervalMultiplier = multiplier
+		bk.maxInterval = max
+		return nil
+	}
+}
+
 // FailFast - Enable fail-fast.
 func FailFast(enable bool) Option {
 	return func(bk *book) error {
@@ -609,6 +810,117 @@ func Force(enable bool) Option {
 	}
 }
 
+// FailOnHTTPError - Fail the step when HTTP runners receive an error status ( >= 400 ). The step can opt out with `allowError: true`.
+func FailOnHTTPError(enabled bool) Option {
+	return func(bk *book) error {
+		bk.failOnHTTPError = enabled
+		return nil
+	}
+}
+
+// HTTPHeaders - Set the headers in every request of all HTTP runners. The values are expanded per request ( e.g. `{{ vars.runId }}` ).
+// The headers of the runner and the step override them.
+func HTTPHeaders(h map[string]string) Option {
+	return func(bk *book) error {
+		if bk.httpHeaders == nil {
+			bk.httpHeaders = map[string]string{}
+		}
+		for k, v := range h {
+			bk.httpHeaders[k] = v
+		}
+		return nil
+	}
+}
+
+// CorrelationID - Generate the correlation ID per runbook and set it in the header of every request of all HTTP runners.
+// The generated ID is stored as `vars._correlationId` to assert that the service echoed it back. The headers of HTTPHeaders, the runner and the step override it.
+// If `vars._correlationId` is already set ( e.g. by `vars:` or Var ), it is used as the correlation ID instead of generating it.
+func CorrelationID(header string) Option {
+	return func(bk *book) error {
+		if header == "" {
+			return errors.New("the header of the correlation ID is empty")
+		}
+		bk.correlationIDHeader = header
+		return nil
+	}
+}
+
+// CorrelationIDFunc - Set the function to generate the correlation ID of CorrelationID ( default: xid ).
+func CorrelationIDFunc(fn func() (string, error)) Option {
+	return func(bk *book) error {
+		bk.correlationIDFunc = fn
+		return nil
+	}
+}
+
+// HTTPRoundTripper - Set the http.RoundTripper to the client of the HTTP runner ( e.g. for recording requests or injecting faults ).
+// Runner settings that require *http.Transport ( e.g. cacert ) cannot be used with the RoundTripper other than *http.Transport.
+func HTTPRoundTripper(name string, rt http.RoundTripper) Option {
+	return func(bk *book) error {
+		if bk.httpRoundTrippers == nil {
+			bk.httpRoundTrippers = map[string]http.RoundTripper{}
+		}
+		bk.httpRoundTrippers[name] = rt
+		return nil
+	}
+}
+
+// HTTPRoundTripperForAll - Set the http.RoundTripper to the clients of all HTTP runners. HTTPRoundTripper of the runner overrides it.
+func HTTPRoundTripperForAll(rt http.RoundTripper) Option {
+	return func(bk *book) error {
+		bk.httpRoundTripper = rt
+		return nil
+	}
+}
+
+// DBMaxRows - Set the maximum number of rows to scan per result set of DB runners ( default: 100000 ). If n <= 0, the number of rows is unlimited.
+func DBMaxRows(n int) Option {
+	return func(bk *book) error {
+		bk.dbMaxRows = &n
+		return nil
+	}
+}
+
+// StrictVars - Fail when undefined variables are referenced in `{{ }}` of steps.
+func StrictVars(enable bool) Option {
+	return func(bk *book) error {
+		bk.strictVars = enable
+		return nil
+	}
+}
+
+// StrictKeys - Fail when steps have keys that are neither built-in runners, built-in sections nor configured runners ( e.g. typo of `test:` ).
+func StrictKeys(enable bool) Option {
+	return func(bk *book) error {
+		bk.strictKeys = enable
+		return nil
+	}
+}
+
+// CollectExprErrors - Continue running the steps past expr evaluation errors ( e.g. typos in "{{ }}" or "test:" ), marking those steps failed, and return all of them with the locations of the steps.
+func CollectExprErrors(enable bool) Option {
+	return func(bk *book) error {
+		bk.collectExprErrors = enable
+		return nil
+	}
+}
+
+// NamespaceBinds - Namespace the values bound by `bind:` under the step key ( e.g. `bound.login.token` ) in addition to the flat names, so that the values bound by earlier steps are not clobbered.
+func NamespaceBinds(enable bool) Option {
+	return func(bk *book) error {
+		bk.namespaceBinds = enable
+		return nil
+	}
+}
+
+// UseJSONNumber - Decode numbers in JSON response bodies of HTTP runners as json.Number to keep the precision of large integers ( e.g. IDs greater than 2^53 ).
+func UseJSONNumber(enable bool) Option {
+	return func(bk *book) error {
+		bk.useJSONNumber = enable
+		return nil
+	}
+}
+
 // GRPCNoTLS - Disable TLS use in all gRPC runners.
 func GRPCNoTLS(noTLS bool) Option {
 	return func(bk *book) error {
@@ -658,6 +970,27 @@ func Capture(c Capturer) Option {
 	}
 }
 
+// TraceFile - Record the run to the trace file as a timestamped log of every step ( request, response, assertion and timing ) in JSON Lines.
+// The values of `secrets` and sensitive headers ( e.g. Authorization ) are masked.
+func TraceFile(path string) Option {
+	var (
+		tf   *traceFile
+		err  error
+		once sync.Once
+	)
+	return func(bk *book) error {
+		// share the trace file among the runbooks
+		once.Do(func() {
+			tf, err = newTraceFile(path)
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create the trace file %s: %w", path, err)
+		}
+		bk.capturers = append(bk.capturers, newTracer(tf))
+		return nil
+	}
+}
+
 // RunMatch - Run only runbooks with matching paths.
 func RunMatch(m string) Option {
 	return func(bk *book) error {
@@ -670,13 +1003,15 @@ func RunMatch(m string) Option {
 	}
 }
 
-// RunSample - Sample the specified number of runbooks.
-func RunSample(n int) Option {
+// RunSample - Sample the specified number of runbooks at random ( deterministically by the seed ) and record the rest as skipped.
+// The runbooks are sampled from the runbooks of the shard ( RunShard ) that match the labels ( RunLabels ).
+func RunSample(n int, seed int64) Option {
 	return func(bk *book) error {
 		if n <= 0 {
 			return fmt.Errorf("sample must be greater than 0: %d", n)
 		}
 		bk.runSample = n
+		bk.runSampleSeed = seed
 		return nil
 	}
 }
@@ -708,6 +1043,23 @@ func RunShuffle(enable bool, seed int64) Option {
 	}
 }
 
+// ShuffleSteps - Randomize the order of running independent steps of runbooks with the seed.
+// Steps with `ordered: true`, steps referencing prior steps ( `steps` or `previous` ) and steps without runners ( test/dump/bind only ) are not moved.
+func ShuffleSteps(seed int64) Option {
+	return func(bk *book) error {
+		bk.shuffleStepsSeed = &seed
+		return nil
+	}
+}
+
+// IncludeStoreInJSON - Include the final store of each runbook in the JSON output of the result ( the values of sensitive keys are masked ).
+func IncludeStoreInJSON(enable bool) Option {
+	return func(bk *book) error {
+		bk.includeStoreInJSON = enable
+		return nil
+	}
+}
+
 // RunConcurrent - Run runbooks concurrently.
 func RunConcurrent(enable bool, max int) Option {
 	return func(bk *book) error {
@@ -728,6 +1080,102 @@ func RunRandom(n int) Option {
 	}
 }
 
+// RunLabels - Run only runbooks that have any of the include labels and none of the exclude labels.
+// Runbooks that do not match are skipped. When specified multiple times, runbooks must match all of them.
+func RunLabels(include, exclude []string) Option {
+	return func(bk *book) error {
+		bk.runLabelFilters = append(bk.runLabelFilters, &labelFilter{
+			include: include,
+			exclude: exclude,
+		})
+		return nil
+	}
+}
+
+// SkipStepLabels - Skip steps that have any of the labels in `steps[*].labels:`. The skipped steps are recorded as skipped.
+func SkipStepLabels(labels ...string) Option {
+	return func(bk *book) error {
+		bk.skipStepLabels = append(bk.skipStepLabels, labels...)
+		return nil
+	}
+}
+
+// RunCarry - Carry the values exported by `export:` over to the runbooks that run after it, as bind variables.
+func RunCarry(enabled bool) Option {
+	return func(bk *book) error {
+		bk.runCarry = enabled
+		return nil
+	}
+}
+
+// Checkpoint - Write the progress of RunN ( the runbooks that passed ) to the checkpoint file after each runbook. The format is CheckpointFile.
+func Checkpoint(path string) Option {
+	return func(bk *book) error {
+		bk.checkpointPath = path
+		return nil
+	}
+}
+
+// Baseline - Compare the elapsed time of each step with the baseline file written by UpdateBaseline, and fail the steps slower than the baseline beyond the tolerance ( BaselineTolerance ).
+// The steps that are not in the baseline file are not compared. The format is BaselineFile.
+func Baseline(path string) Option {
+	return func(bk *book) error {
+		bk.baselinePath = path
+		return nil
+	}
+}
+
+// UpdateBaseline - Write the elapsed time of each step that passed to the baseline file of Baseline instead of comparing them.
+func UpdateBaseline(enabled bool) Option {
+	return func(bk *book) error {
+		bk.updateBaseline = enabled
+		return nil
+	}
+}
+
+// BaselineTolerance - Set the allowed ratio of slowdown from the baseline of Baseline ( default: 0.2, fail if more than 20% slower ).
+func BaselineTolerance(ratio float64) Option {
+	return func(bk *book) error {
+		if ratio < 0 {
+			return fmt.Errorf("invalid baseline tolerance: %v", ratio)
+		}
+		bk.baselineTolerance = &ratio
+		return nil
+	}
+}
+
+// Dataset - Run each runbook loaded by Load once per record of the dataset ( CSV or JSON ) merged into `vars:`.
+func Dataset(path string) Option {
+	return func(bk *book) error {
+		bk.datasetPath = path
+		return nil
+	}
+}
+
+// DatasetKey - Set the column of the dataset whose value names each record of Dataset ( e.g. `user=alice` ). If it is not set, records are named by the index ( e.g. `#0` ).
+func DatasetKey(column string) Option {
+	return func(bk *book) error {
+		bk.datasetKey = column
+		return nil
+	}
+}
+
+// ResumeFrom - Skip the runbooks that passed in the checkpoint file written by Checkpoint. They are recorded as skipped. If the file does not exist, no runbooks are skipped.
+func ResumeFrom(path string) Option {
+	return func(bk *book) error {
+		bk.resumeFromPath = path
+		return nil
+	}
+}
+
+// Progress - Write a mark per runbook to out as it finishes in RunN ( `.` pass, `F` fail, `S` skip ), and the counts of the results after all runbooks.
+func Progress(out io.Writer) Option {
+	return func(bk *book) error {
+		bk.progressOut = out
+		return nil
+	}
+}
+
 // Stdout - Set STDOUT.
 func Stdout(w io.Writer) Option {
 	return func(bk *book) error {
@@ -744,6 +1192,14 @@ func Stderr(w io.Writer) Option {
 	}
 }
 
+// Color - Enable/Disable colored output. By default, it is determined by NO_COLOR and whether the output is a terminal.
+func Color(enabled bool) Option {
+	return func(bk *book) error {
+		bk.color = &enabled
+		return nil
+	}
+}
+
 // LoadOnly - Load only.
 func LoadOnly() Option {
 	return func(bk *book) error {
@@ -777,10 +1233,21 @@ func setupBuiltinFunctions(opts ...Option) []Option {
 		Func("string", func(v interface{}) string { return cast.ToString(v) }),
 		Func("int", func(v interface{}) int { return cast.ToInt(v) }),
 		Func("bool", func(v interface{}) bool { return cast.ToBool(v) }),
+		Func("asInt", builtin.AsInt),
+		Func("asFloat", builtin.AsFloat),
+		Func("asString", builtin.AsString),
 		Func("time", builtin.Time),
-		Func("compare", builtin.Compare),
+		Func("now", time.Now),
+		Func("duration", builtin.Duration),
+		Func(compareFuncName, builtin.Compare),
+		Func("approx", builtin.Approx),
+		Func("isSorted", builtin.IsSorted),
 		Func("diff", builtin.Diff),
 		Func("intersect", builtin.Intersect),
+		Func("jsonpath", builtin.JSONPath),
+		Func(containsFuncName, builtin.Contains),
+		Func(matchesAnyFuncName, builtin.MatchesAny),
+		Func(matchesAnyDiffFuncName, builtin.MatchesAnyDiff),
 		Func("input", func(msg, defaultMsg interface{}) string {
 			return prompter.Prompt(cast.ToString(msg), cast.ToString(defaultMsg))
 		}),
@@ -2,7 +2,10 @@ package runn
 
 import (
 	"fmt"
+	"net/http"
+	"net/url"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
@@ -51,17 +54,132 @@ func parseHTTPRequest(v map[string]interface{}) (*httpRequest, error) {
 					}
 				}
 			}
+			pp, ok := vvvvv["pathParams"]
+			if ok {
+				pm, ok := pp.(map[string]interface{})
+				if !ok {
+					return nil, fmt.Errorf("invalid request: %s", string(part))
+				}
+				p, err := replacePathParams(req.path, pm)
+				if err != nil {
+					return nil, fmt.Errorf("invalid request: %w: %s", err, string(part))
+				}
+				req.path = p
+			}
+			sb, ok := vvvvv["saveBody"]
+			if ok {
+				req.saveBody, ok = sb.(string)
+				if !ok || req.saveBody == "" {
+					return nil, fmt.Errorf("invalid request: %s", string(part))
+				}
+			}
+			cs, ok := vvvvv["checksum"]
+			if ok {
+				req.checksum, ok = cs.(string)
+				if !ok || req.checksum != httpChecksumSHA256 {
+					return nil, fmt.Errorf("invalid request: unsupported checksum: %v: %s", cs, string(part))
+				}
+			}
+			pb, ok := vvvvv["protobuf"]
+			if ok {
+				pm, ok := pb.(map[string]interface{})
+				if !ok {
+					return nil, fmt.Errorf("invalid request: %s", string(part))
+				}
+				req.protobuf = &httpProtobuf{}
+				for k, v := range pm {
+					s, ok := v.(string)
+					if !ok || s == "" {
+						return nil, fmt.Errorf("invalid request: %s", string(part))
+					}
+					switch k {
+					case "request":
+						req.protobuf.request = s
+					case "response":
+						req.protobuf.response = s
+					default:
+						return nil, fmt.Errorf("invalid request: unknown key of protobuf: %s: %s", k, string(part))
+					}
+				}
+			}
+			pre, ok := vvvvv["pre"]
+			if ok {
+				req.pre, ok = pre.(string)
+				if !ok || req.pre == "" {
+					return nil, fmt.Errorf("invalid request: %s", string(part))
+				}
+			}
+			ae, ok := vvvvv["allowError"]
+			if ok {
+				req.allowError, ok = ae.(bool)
+				if !ok {
+					return nil, fmt.Errorf("invalid request: %s", string(part))
+				}
+			}
+			db, ok := vvvvv["decodeBody"]
+			if ok {
+				decode, ok := db.(bool)
+				if !ok {
+					return nil, fmt.Errorf("invalid request: %s", string(part))
+				}
+				req.skipDecodeBody = !decode
+			}
+			fr, ok := vvvvv["followRedirects"]
+			if ok {
+				follow, ok := fr.(bool)
+				if !ok {
+					return nil, fmt.Errorf("invalid request: %s", string(part))
+				}
+				req.followRedirects = &follow
+			}
+			mr, ok := vvvvv["maxRedirects"]
+			if ok {
+				switch v := mr.(type) {
+				case int:
+					req.maxRedirects = v
+				case int64:
+					req.maxRedirects = int(v)
+				case uint64:
+					req.maxRedirects = int(v)
+				default:
+					return nil, fmt.Errorf("invalid request: %s", string(part))
+				}
+				if req.maxRedirects < 0 {
+					return nil, fmt.Errorf("invalid request: %s", string(part))
+				}
+			}
+			rr, ok := vvvvv["recordRedirects"]
+			if ok {
+				req.recordRedirects, ok = rr.(bool)
+				if !ok {
+					return nil, fmt.Errorf("invalid request: %s", string(part))
+				}
+			}
+			forceBody := false
+			fb, ok := vvvvv["forceBody"]
+			if ok {
+				forceBody, ok = fb.(bool)
+				if !ok {
+					return nil, fmt.Errorf("invalid request: %s", string(part))
+				}
+			}
 			bm, ok := vvvvv["body"]
 			if ok {
 				switch v := bm.(type) {
 				case map[string]interface{}:
-					if len(v) != 1 {
-						return nil, fmt.Errorf("invalid request: %s", string(part))
+					if len(v) == 1 {
+						for kkk, vvvvvv := range v {
+							// the key looking like a media type is validated as the media type ( e.g. unsupported application/xml )
+							if isSupportedMediaType(kkk) || looksLikeMediaType(kkk) {
+								req.mediaType = kkk
+								req.body = vvvvvv
+							}
+						}
 					}
-					for kkk, vvvvvv := range v {
-						req.mediaType = kkk
-						req.body = vvvvvv
-						break
+					if req.mediaType == "" {
+						// the body without the media type key
+						req.bareBody = true
+						req.body = v
 					}
 				default:
 					if v != nil {
@@ -69,6 +187,12 @@ func parseHTTPRequest(v map[string]interface{}) (*httpRequest, error) {
 					}
 				}
 			}
+			if req.body != nil && !forceBody && !methodTakesBody(req.method) {
+				// omit the body declared for the method that does not take body
+				req.mediaType = ""
+				req.body = nil
+				req.bareBody = false
+			}
 		}
 
 		break
@@ -79,14 +203,116 @@ func parseHTTPRequest(v map[string]interface{}) (*httpRequest, error) {
 	return req, nil
 }
 
+// replacePathParams replaces `{name}` in the path with the URL-encoded value of `pathParams:`.
+func replacePathParams(p string, params map[string]interface{}) (string, error) {
+	for k, v := range params {
+		ph := fmt.Sprintf("{%s}", k)
+		if !strings.Contains(p, ph) {
+			return "", fmt.Errorf("path parameter '%s' is not found in the path: %s", k, p)
+		}
+		var s string
+		switch vv := v.(type) {
+		case nil:
+			return "", fmt.Errorf("path parameter '%s' is nil", k)
+		case string:
+			s = vv
+		case float64:
+			s = strconv.FormatFloat(vv, 'f', -1, 64)
+		case map[string]interface{}, []interface{}:
+			return "", fmt.Errorf("invalid path parameter '%s': %v", k, v)
+		default:
+			s = fmt.Sprintf("%v", vv)
+		}
+		p = strings.ReplaceAll(p, ph, url.PathEscape(s))
+	}
+	return p, nil
+}
+
+// methodTakesBody returns whether the request body of the method is sent without `forceBody: true`.
+func methodTakesBody(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodDelete:
+		return false
+	}
+	return true
+}
+
 func parseDBQuery(v map[string]interface{}) (*dbQuery, error) {
 	q := &dbQuery{}
 	part, err := yaml.Marshal(v)
 	if err != nil {
 		return nil, err
 	}
-	if len(v) != 1 {
-		return nil, fmt.Errorf("invalid query: %s", string(part))
+	for k := range v {
+		switch k {
+		case "query", "poll", "listen", "maxRows", "keyBy", "describe", "explain":
+		default:
+			return nil, fmt.Errorf("invalid query: %s", string(part))
+		}
+	}
+	if d, ok := v["describe"]; ok {
+		if len(v) > 1 {
+			return nil, fmt.Errorf("invalid query: describe cannot be used with the other keys: %s", string(part))
+		}
+		table, ok := d.(string)
+		if !ok || strings.TrimSpace(table) == "" {
+			return nil, fmt.Errorf("invalid describe: %v", d)
+		}
+		q.describe = strings.TrimSpace(table)
+		return q, nil
+	}
+	if mr, ok := v["maxRows"]; ok {
+		var n int
+		switch vv := mr.(type) {
+		case int:
+			n = vv
+		case int64:
+			n = int(vv)
+		case uint64:
+			n = int(vv)
+		default:
+			return nil, fmt.Errorf("invalid maxRows: %v", mr)
+		}
+		q.maxRows = &n
+	}
+	if e, ok := v["explain"]; ok {
+		switch ev := e.(type) {
+		case bool:
+			if ev {
+				q.explain = dbExplainPlan
+			}
+		case string:
+			if ev != string(dbExplainAnalyze) {
+				return nil, fmt.Errorf("invalid explain: %v", e)
+			}
+			q.explain = dbExplainAnalyze
+		default:
+			return nil, fmt.Errorf("invalid explain: %v", e)
+		}
+	}
+	if kb, ok := v["keyBy"]; ok {
+		keyBy, ok := kb.(string)
+		if !ok || keyBy == "" {
+			return nil, fmt.Errorf("invalid keyBy: %v", kb)
+		}
+		q.keyBy = keyBy
+	}
+	if l, ok := v["listen"]; ok {
+		if _, ok := v["poll"]; ok {
+			return nil, fmt.Errorf("invalid query: listen and poll cannot be used together: %s", string(part))
+		}
+		q.listen, err = parseDBListen(l)
+		if err != nil {
+			return nil, fmt.Errorf("invalid listen: %w", err)
+		}
+		if s, ok := v["query"]; ok {
+			stmt, ok := s.(string)
+			if !ok || strings.Trim(stmt, " ") == "" {
+				return nil, fmt.Errorf("invalid query: %s", string(part))
+			}
+			q.stmt = strings.Trim(stmt, " \n")
+		}
+		return q, nil
 	}
 	s, ok := v["query"]
 	if !ok {
@@ -97,9 +323,77 @@ func parseDBQuery(v map[string]interface{}) (*dbQuery, error) {
 		return nil, fmt.Errorf("invalid query: %s", string(part))
 	}
 	q.stmt = strings.Trim(stmt, " \n")
+	p, ok := v["poll"]
+	if !ok {
+		return q, nil
+	}
+	q.poll, err = parseDBPoll(p)
+	if err != nil {
+		return nil, fmt.Errorf("invalid poll: %w", err)
+	}
 	return q, nil
 }
 
+// parseDBListen parses `listen:` of DB query.
+func parseDBListen(v interface{}) (*dbListen, error) {
+	l := &dbListen{
+		timeout: defaultDBListenTimeout,
+	}
+	switch vv := v.(type) {
+	case string:
+		l.channel = vv
+	case map[string]interface{}:
+		for k, vvv := range vv {
+			switch k {
+			case "channel":
+				c, ok := vvv.(string)
+				if !ok {
+					return nil, fmt.Errorf("invalid channel: %v", vvv)
+				}
+				l.channel = c
+			case "timeout":
+				d, err := parseDuration(fmt.Sprintf("%v", vvv))
+				if err != nil {
+					return nil, fmt.Errorf("invalid timeout: %w", err)
+				}
+				l.timeout = d
+			default:
+				return nil, fmt.Errorf("invalid listen key: %s", k)
+			}
+		}
+	default:
+		return nil, fmt.Errorf("invalid listen: %v", v)
+	}
+	if strings.Trim(l.channel, " ") == "" {
+		return nil, fmt.Errorf("listen requires channel: %v", v)
+	}
+	return l, nil
+}
+
+// parseDBPoll parses `poll:` of DB query as Loop.
+func parseDBPoll(v interface{}) (*Loop, error) {
+	pm, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("invalid poll: %v", v)
+	}
+	l := map[string]interface{}{}
+	for k, vv := range pm {
+		switch k {
+		case "maxAttempts":
+			l["count"] = vv
+		case "interval", "until":
+			l[k] = vv
+		default:
+			return nil, fmt.Errorf("invalid poll key: %s", k)
+		}
+	}
+	u, ok := l["until"].(string)
+	if !ok || strings.Trim(u, " ") == "" {
+		return nil, fmt.Errorf("poll requires until: %v", v)
+	}
+	return newLoop(l)
+}
+
 func parseGrpcRequest(v map[string]interface{}, expand func(interface{}) (interface{}, error)) (*grpcRequest, error) {
 	v = trimDelimiter(v)
 	req := &grpcRequest{
@@ -283,8 +577,12 @@ func parseExecCommand(v map[string]interface{}) (*execCommand, error) {
 	if err != nil {
 		return nil, err
 	}
-	if len(v) != 1 && len(v) != 2 {
-		return nil, fmt.Errorf("invalid command: %s", string(part))
+	for k := range v {
+		switch k {
+		case "command", "stdin", "outputAs", "env", "remote":
+		default:
+			return nil, fmt.Errorf("invalid command: %s", string(part))
+		}
 	}
 	cs, ok := v["command"]
 	if !ok {
@@ -295,18 +593,134 @@ func parseExecCommand(v map[string]interface{}) (*execCommand, error) {
 		return nil, fmt.Errorf("invalid command: %s", string(part))
 	}
 	c.command = strings.Trim(command, " \n")
-	ss, ok := v["stdin"]
-	if !ok {
-		return c, nil
+	if ss, ok := v["stdin"]; ok {
+		stdin, ok := ss.(string)
+		if !ok {
+			return nil, fmt.Errorf("invalid stdin: %s", string(part))
+		}
+		c.stdin = stdin
 	}
-	stdin, ok := ss.(string)
-	if !ok {
-		return nil, fmt.Errorf("invalid stdin: %s", string(part))
+	if rm, ok := v["remote"]; ok {
+		remote, ok := rm.(string)
+		if !ok || remote == "" {
+			return nil, fmt.Errorf("invalid remote: %s", string(part))
+		}
+		c.remote = remote
+	}
+	if oa, ok := v["outputAs"]; ok {
+		outputAs, ok := oa.(string)
+		if !ok || outputAs != execOutputAsJSON {
+			return nil, fmt.Errorf("invalid outputAs: %s", string(part))
+		}
+		c.outputAs = outputAs
+	}
+	if ev, ok := v["env"]; ok {
+		env, ok := ev.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid env: %s", string(part))
+		}
+		c.env = map[string]string{}
+		for k, vv := range env {
+			switch vvv := vv.(type) {
+			case string:
+				c.env[k] = vvv
+			case int, int64, uint64, float64, bool:
+				c.env[k] = fmt.Sprintf("%v", vvv)
+			default:
+				return nil, fmt.Errorf("invalid env: %s", string(part))
+			}
+		}
 	}
-	c.stdin = stdin
 	return c, nil
 }
 
+func parseWaitConfig(v map[string]interface{}) (*waitConfig, error) {
+	c := &waitConfig{
+		interval: defaultWaitInterval,
+		timeout:  defaultWaitTimeout,
+	}
+	part, err := yaml.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	n := 0
+	for k, vv := range v {
+		switch k {
+		case "duration":
+			c.duration, err = parseWaitDuration(vv)
+			if err != nil {
+				return nil, fmt.Errorf("invalid wait duration: %w: %s", err, string(part))
+			}
+			n++
+		case "time":
+			at, err := parseWaitTime(vv)
+			if err != nil {
+				return nil, fmt.Errorf("invalid wait time: %w: %s", err, string(part))
+			}
+			c.at = &at
+			n++
+		case "until":
+			until, ok := vv.(string)
+			if !ok || strings.TrimSpace(until) == "" {
+				return nil, fmt.Errorf("invalid wait condition: %s", string(part))
+			}
+			c.until = until
+			n++
+		case "interval":
+			c.interval, err = parseWaitDuration(vv)
+			if err != nil || c.interval <= 0 {
+				return nil, fmt.Errorf("invalid wait interval: %s", string(part))
+			}
+		case "timeout":
+			c.timeout, err = parseWaitDuration(vv)
+			if err != nil || c.timeout <= 0 {
+				return nil, fmt.Errorf("invalid wait timeout: %s", string(part))
+			}
+		default:
+			return nil, fmt.Errorf("invalid wait: unknown key %s: %s", k, string(part))
+		}
+	}
+	if n != 1 {
+		return nil, fmt.Errorf("invalid wait: one of duration, time and until should be specified: %s", string(part))
+	}
+	return c, nil
+}
+
+// parseWaitDuration parses the duration ( e.g. `3sec`, `500ms` ). The number without the unit is seconds.
+func parseWaitDuration(v interface{}) (time.Duration, error) {
+	switch vv := v.(type) {
+	case string:
+		return parseDuration(vv)
+	case int, int64, uint64:
+		return parseDuration(fmt.Sprintf("%v", vv))
+	default:
+		return 0, fmt.Errorf("invalid duration: %v", v)
+	}
+}
+
+// waitTimeLayouts - layouts of the wall-clock time to wait until. The time without the date is the time of today in the local time zone.
+var waitTimeLayouts = []string{time.RFC3339, "2006-01-02 15:04:05", "15:04:05", "15:04"}
+
+func parseWaitTime(v interface{}) (time.Time, error) {
+	switch vv := v.(type) {
+	case time.Time:
+		return vv, nil
+	case string:
+		for _, l := range waitTimeLayouts {
+			t, err := time.ParseInLocation(l, vv, time.Local)
+			if err != nil {
+				continue
+			}
+			if t.Year() == 0 {
+				now := time.Now()
+				t = time.Date(now.Year(), now.Month(), now.Day(), t.Hour(), t.Minute(), t.Second(), 0, time.Local)
+			}
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unsupported time format: %v", v)
+}
+
 func parseIncludeConfig(v interface{}) (*includeConfig, error) {
 	c := &includeConfig{vars: map[string]interface{}{}}
 	switch vv := v.(type) {
@@ -52,6 +52,325 @@ func TestParseHTTPRequest(t *testing.T) {
 		},
 		{
 			`
+/users/k1LoW:
+  get:
+    body:
+      application/json:
+        key: value
+`,
+			&httpRequest{
+				path:      "/users/k1LoW",
+				method:    http.MethodGet,
+				mediaType: "",
+				headers:   map[string]string{},
+				body:      nil,
+			},
+			false,
+		},
+		{
+			`
+/users/k1LoW:
+  delete:
+    body:
+      application/json:
+        key: value
+`,
+			&httpRequest{
+				path:      "/users/k1LoW",
+				method:    http.MethodDelete,
+				mediaType: "",
+				headers:   map[string]string{},
+				body:      nil,
+			},
+			false,
+		},
+		{
+			`
+/users/k1LoW:
+  delete:
+    forceBody: true
+    body:
+      application/json:
+        key: value
+`,
+			&httpRequest{
+				path:      "/users/k1LoW",
+				method:    http.MethodDelete,
+				mediaType: MediaTypeApplicationJSON,
+				headers:   map[string]string{},
+				body: map[string]interface{}{
+					"key": "value",
+				},
+			},
+			false,
+		},
+		{
+			`
+/users:
+  post:
+    body:
+      name: alice
+      age: 3
+`,
+			&httpRequest{
+				path:     "/users",
+				method:   http.MethodPost,
+				headers:  map[string]string{},
+				bareBody: true,
+				body: map[string]interface{}{
+					"name": "alice",
+					"age":  uint64(3),
+				},
+			},
+			false,
+		},
+		{
+			`
+/users:
+  post:
+    body:
+      name: alice
+`,
+			&httpRequest{
+				path:     "/users",
+				method:   http.MethodPost,
+				headers:  map[string]string{},
+				bareBody: true,
+				body: map[string]interface{}{
+					"name": "alice",
+				},
+			},
+			false,
+		},
+		{
+			`
+/users:
+  post:
+    body:
+      application/xml:
+        name: alice
+`,
+			nil,
+			true,
+		},
+		{
+			`
+/users/k1LoW:
+  get:
+    forceBody: "yes"
+`,
+			nil,
+			true,
+		},
+		{
+			`
+/oauth/token:
+  post:
+    body:
+      application/x-www-form-urlencoded:
+        grant_type: client_credentials
+        client_id: runn
+`,
+			&httpRequest{
+				path:      "/oauth/token",
+				method:    http.MethodPost,
+				mediaType: MediaTypeApplicationFormUrlencoded,
+				headers:   map[string]string{},
+				body: map[string]interface{}{
+					"grant_type": "client_credentials",
+					"client_id":  "runn",
+				},
+			},
+			false,
+		},
+		{
+			`
+/files/report.pdf:
+  get:
+    saveBody: out/report.pdf
+`,
+			&httpRequest{
+				path:     "/files/report.pdf",
+				method:   http.MethodGet,
+				headers:  map[string]string{},
+				saveBody: "out/report.pdf",
+			},
+			false,
+		},
+		{
+			`
+/users/k1LoW:
+  get:
+    allowError: true
+`,
+			&httpRequest{
+				path:       "/users/k1LoW",
+				method:     http.MethodGet,
+				headers:    map[string]string{},
+				allowError: true,
+			},
+			false,
+		},
+		{
+			`
+/users/k1LoW:
+  get:
+    allowError: "yes"
+`,
+			nil,
+			true,
+		},
+		{
+			`
+/users/k1LoW:
+  get:
+    pre: "{'headers': {'X-Signature': sha(request.path)}}"
+`,
+			&httpRequest{
+				path:    "/users/k1LoW",
+				method:  http.MethodGet,
+				headers: map[string]string{},
+				pre:     "{'headers': {'X-Signature': sha(request.path)}}",
+			},
+			false,
+		},
+		{
+			`
+/users/k1LoW:
+  get:
+    decodeBody: false
+`,
+			&httpRequest{
+				path:           "/users/k1LoW",
+				method:         http.MethodGet,
+				headers:        map[string]string{},
+				skipDecodeBody: true,
+			},
+			false,
+		},
+		{
+			`
+/users/k1LoW:
+  get:
+    followRedirects: false
+`,
+			&httpRequest{
+				path:            "/users/k1LoW",
+				method:          http.MethodGet,
+				headers:         map[string]string{},
+				followRedirects: func() *bool { v := false; return &v }(),
+			},
+			false,
+		},
+		{
+			`
+/users/k1LoW:
+  get:
+    maxRedirects: 3
+    recordRedirects: true
+`,
+			&httpRequest{
+				path:            "/users/k1LoW",
+				method:          http.MethodGet,
+				headers:         map[string]string{},
+				maxRedirects:    3,
+				recordRedirects: true,
+			},
+			false,
+		},
+		{
+			`
+/users/k1LoW:
+  get:
+    maxRedirects: -1
+`,
+			nil,
+			true,
+		},
+		{
+			`
+/hello:
+  post:
+    protobuf:
+      request: grpctest.HelloRequest
+      response: grpctest.HelloResponse
+    body:
+      application/x-protobuf:
+        name: alice
+`,
+			&httpRequest{
+				path:      "/hello",
+				method:    http.MethodPost,
+				headers:   map[string]string{},
+				mediaType: MediaTypeApplicationProtobuf,
+				body:      map[string]interface{}{"name": "alice"},
+				protobuf: &httpProtobuf{
+					request:  "grpctest.HelloRequest",
+					response: "grpctest.HelloResponse",
+				},
+			},
+			false,
+		},
+		{
+			`
+/hello:
+  post:
+    protobuf:
+      message: grpctest.HelloRequest
+`,
+			nil,
+			true,
+		},
+		{
+			`
+/files/archive.tar.gz:
+  get:
+    checksum: sha256
+`,
+			&httpRequest{
+				path:     "/files/archive.tar.gz",
+				method:   http.MethodGet,
+				headers:  map[string]string{},
+				checksum: "sha256",
+			},
+			false,
+		},
+		{
+			`
+/files/archive.tar.gz:
+  get:
+    checksum: md4
+`,
+			nil,
+			true,
+		},
+		{
+			`
+/orgs/{org}/users/{id}:
+  get:
+    pathParams:
+      org: k1LoW/runn
+      id: 3
+`,
+			&httpRequest{
+				path:    "/orgs/k1LoW%2Frunn/users/3",
+				method:  http.MethodGet,
+				headers: map[string]string{},
+			},
+			false,
+		},
+		{
+			`
+/users/{id}:
+  get:
+    pathParams:
+      name: alice
+`,
+			nil,
+			true,
+		},
+		{
+			`
 /users/k1LoW:
   get: null
 `,
@@ -84,7 +403,7 @@ func TestParseHTTPRequest(t *testing.T) {
 		if tt.wantErr {
 			t.Error("want error")
 		}
-		opts := cmp.AllowUnexported(httpRequest{})
+		opts := cmp.AllowUnexported(httpRequest{}, httpProtobuf{})
 		if diff := cmp.Diff(got, tt.want, opts); diff != "" {
 			t.Errorf("%s", diff)
 		}
@@ -116,6 +435,145 @@ query: |
 			},
 			false,
 		},
+		{
+			`
+query: SELECT * FROM users;
+maxRows: 10
+`,
+			&dbQuery{
+				stmt:    "SELECT * FROM users;",
+				maxRows: func() *int { n := 10; return &n }(),
+			},
+			false,
+		},
+		{
+			`
+query: SELECT * FROM users;
+maxRows: ten
+`,
+			nil,
+			true,
+		},
+		{
+			`
+query: SELECT * FROM users;
+keyBy: id
+`,
+			&dbQuery{
+				stmt:  "SELECT * FROM users;",
+				keyBy: "id",
+			},
+			false,
+		},
+		{
+			`
+query: SELECT * FROM users;
+keyBy: 1
+`,
+			nil,
+			true,
+		},
+		{
+			`
+describe: users
+`,
+			&dbQuery{
+				describe: "users",
+			},
+			false,
+		},
+		{
+			`
+query: SELECT * FROM users;
+explain: true
+`,
+			&dbQuery{
+				stmt:    "SELECT * FROM users;",
+				explain: dbExplainPlan,
+			},
+			false,
+		},
+		{
+			`
+query: SELECT * FROM users;
+explain: analyze
+`,
+			&dbQuery{
+				stmt:    "SELECT * FROM users;",
+				explain: dbExplainAnalyze,
+			},
+			false,
+		},
+		{
+			`
+query: SELECT * FROM users;
+explain: verbose
+`,
+			nil,
+			true,
+		},
+		{
+			`
+describe: users
+query: SELECT * FROM users;
+`,
+			nil,
+			true,
+		},
+		{
+			`
+listen: events
+`,
+			&dbQuery{
+				listen: &dbListen{
+					channel: "events",
+					timeout: defaultDBListenTimeout,
+				},
+			},
+			false,
+		},
+		{
+			`
+listen:
+  channel: events
+  timeout: 5
+query: UPDATE jobs SET status = 'start' WHERE id = 1;
+`,
+			&dbQuery{
+				stmt: "UPDATE jobs SET status = 'start' WHERE id = 1;",
+				listen: &dbListen{
+					channel: "events",
+					timeout: 5 * time.Second,
+				},
+			},
+			false,
+		},
+		{
+			`
+listen:
+  timeout: 5
+`,
+			nil,
+			true,
+		},
+		{
+			`
+listen: events
+poll:
+  until: 'true'
+query: SELECT 1;
+`,
+			nil,
+			true,
+		},
+		{
+			`
+query: SELECT 1;
+invalid: true
+`,
+			nil,
+			true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -133,7 +591,7 @@ query: |
 		if tt.wantErr {
 			t.Error("want error")
 		}
-		opts := cmp.AllowUnexported(dbQuery{})
+		opts := cmp.AllowUnexported(dbQuery{}, dbListen{})
 		if diff := cmp.Diff(got, tt.want, opts); diff != "" {
 			t.Errorf("%s", diff)
 		}
@@ -337,6 +795,75 @@ stdin: |
   alice
   bob
   charlie
+`,
+			nil,
+			true,
+		},
+		{
+			`
+command: hostname
+remote: sc
+`,
+			&execCommand{
+				command: "hostname",
+				remote:  "sc",
+			},
+			false,
+		},
+		{
+			`
+command: hostname
+remote: 1
+`,
+			nil,
+			true,
+		},
+		{
+			`
+command: echo '{"name":"alice"}'
+outputAs: json
+`,
+			&execCommand{
+				command:  `echo '{"name":"alice"}'`,
+				outputAs: "json",
+			},
+			false,
+		},
+		{
+			`
+command: echo hello
+outputAs: xml
+`,
+			nil,
+			true,
+		},
+		{
+			`
+command: echo $TOKEN
+env:
+  TOKEN: abc
+  RETRY: 3
+`,
+			&execCommand{
+				command: "echo $TOKEN",
+				env:     map[string]string{"TOKEN": "abc", "RETRY": "3"},
+			},
+			false,
+		},
+		{
+			`
+command: echo $TOKEN
+env:
+  TOKEN:
+    - abc
+`,
+			nil,
+			true,
+		},
+		{
+			`
+command: echo hello
+unknown: value
 `,
 			nil,
 			true,
@@ -474,3 +1001,31 @@ func TestParseDuration(t *testing.T) {
 		})
 	}
 }
+
+func TestReplacePathParams(t *testing.T) {
+	tests := []struct {
+		path    string
+		params  map[string]interface{}
+		want    string
+		wantErr bool
+	}{
+		{"/users/{id}", map[string]interface{}{"id": uint64(1)}, "/users/1", false},
+		{"/users/{id}", map[string]interface{}{"id": float64(1000000)}, "/users/1000000", false},
+		{"/users/{id}/items/{id}", map[string]interface{}{"id": "a b"}, "/users/a%20b/items/a%20b", false},
+		{"/search/{q}?limit=10", map[string]interface{}{"q": "a/b?c"}, "/search/a%2Fb%3Fc?limit=10", false},
+		{"/users/{id}", map[string]interface{}{}, "/users/{id}", false},
+		{"/users/{id}", map[string]interface{}{"name": "alice"}, "", true},
+		{"/users/{id}", map[string]interface{}{"id": nil}, "", true},
+		{"/users/{id}", map[string]interface{}{"id": []interface{}{1}}, "", true},
+	}
+	for _, tt := range tests {
+		got, err := replacePathParams(tt.path, tt.params)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s %v: got error %v", tt.path, tt.params, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("got %v\nwant %v", got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package runn
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+)
+
+const pingRunnerKey = "ping"
+
+const pingStoreStatusKey = "status"
+
+type pingRunner struct {
+	operator *operator
+}
+
+type pinger interface {
+	PingContext(ctx context.Context) error
+}
+
+func newPingRunner(o *operator) (*pingRunner, error) {
+	return &pingRunner{
+		operator: o,
+	}, nil
+}
+
+// Run checks the connectivity of the runners in order and fails on the first unreachable runner.
+func (rnr *pingRunner) Run(ctx context.Context, names []string) error {
+	res := map[string]interface{}{}
+	for _, name := range names {
+		v, err := rnr.ping(ctx, name)
+		if err != nil {
+			return err
+		}
+		res[name] = v
+	}
+	rnr.operator.record(res)
+	return nil
+}
+
+func (rnr *pingRunner) ping(ctx context.Context, name string) (map[string]interface{}, error) {
+	if h, ok := rnr.operator.httpRunners[name]; ok {
+		return h.ping(ctx)
+	}
+	if db, ok := rnr.operator.dbRunners[name]; ok {
+		return db.ping(ctx)
+	}
+	return nil, fmt.Errorf("cannot ping runner: %s (supported runners are HTTP and DB runners)", name)
+}
+
+// ping sends HEAD ( or GET if HEAD is not allowed ) request to the endpoint.
+// Any response is regarded as reachable.
+func (rnr *httpRunner) ping(ctx context.Context) (map[string]interface{}, error) {
+	if rnr.client == nil && rnr.handler != nil {
+		w := httptest.NewRecorder()
+		rnr.handler.ServeHTTP(w, httptest.NewRequest(http.MethodHead, "/", nil))
+		return map[string]interface{}{pingStoreStatusKey: w.Code}, nil
+	}
+	status, err := rnr.pingWithMethod(ctx, http.MethodHead)
+	if err == nil && status == http.StatusMethodNotAllowed {
+		status, err = rnr.pingWithMethod(ctx, http.MethodGet)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("http runner %s (%s) is unreachable: %w", rnr.name, rnr.endpoint.String(), err)
+	}
+	return map[string]interface{}{pingStoreStatusKey: status}, nil
+}
+
+func (rnr *httpRunner) pingWithMethod(ctx context.Context, method string) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, method, rnr.endpoint.String(), nil)
+	if err != nil {
+		return 0, err
+	}
+	res, err := rnr.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	_ = res.Body.Close()
+	return res.StatusCode, nil
+}
+
+func (rnr *dbRunner) ping(ctx context.Context) (map[string]interface{}, error) {
+	if rnr.group != nil {
+		for _, readOnly := range []bool{true, false} {
+			target, err := rnr.route(readOnly)
+			if err != nil {
+				return nil, err
+			}
+			if _, err := target.ping(ctx); err != nil {
+				return nil, err
+			}
+		}
+		return map[string]interface{}{}, nil
+	}
+	p, ok := rnr.client.(pinger)
+	if !ok {
+		return nil, fmt.Errorf("db runner %s does not support ping", rnr.name)
+	}
+	if err := p.PingContext(ctx); err != nil {
+		return nil, fmt.Errorf("db runner %s is unreachable: %w", rnr.name, err)
+	}
+	return map[string]interface{}{}, nil
+}
@@ -0,0 +1,98 @@
+package runn
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestPingRunner(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(ts.Close)
+	down := httptest.NewServer(http.NotFoundHandler())
+	down.Close()
+
+	tests := []struct {
+		target  interface{}
+		want    map[string]interface{}
+		wantErr string
+	}{
+		{"req", map[string]interface{}{"req": map[string]interface{}{"status": http.StatusOK}, "run": true}, ""},
+		{"db", map[string]interface{}{"db": map[string]interface{}{}, "run": true}, ""},
+		{
+			[]interface{}{"req", "handler", "db"},
+			map[string]interface{}{
+				"req":     map[string]interface{}{"status": http.StatusOK},
+				"handler": map[string]interface{}{"status": http.StatusNotFound},
+				"db":      map[string]interface{}{},
+				"run":     true,
+			},
+			"",
+		},
+		{"down", nil, "http runner down"},
+		{[]interface{}{"req", "unknown"}, nil, "cannot ping runner: unknown"},
+	}
+	ctx := context.Background()
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%v", tt.target), func(t *testing.T) {
+			o, err := New(
+				Runner("req", ts.URL),
+				Runner("down", down.URL),
+				HTTPRunnerWithHandler("handler", http.NotFoundHandler()),
+				Runner("db", fmt.Sprintf("sqlite://%s", filepath.Join(t.TempDir(), "ping.db"))),
+				Stdout(io.Discard),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := o.AppendStep("0", map[string]interface{}{"ping": tt.target}); err != nil {
+				t.Fatal(err)
+			}
+			if err := o.Run(ctx); err != nil {
+				if tt.wantErr == "" || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("got %v want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if tt.wantErr != "" {
+				t.Fatalf("want error %q", tt.wantErr)
+			}
+			got := o.store.steps[0]
+			delete(got, storeOutcomeKey)
+			if diff := cmp.Diff(got, tt.want, nil); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestPingRunnerInvalidTarget(t *testing.T) {
+	tests := []interface{}{
+		nil,
+		1,
+		[]interface{}{},
+		[]interface{}{"req", 1},
+	}
+	for _, tt := range tests {
+		o, err := New()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := o.AppendStep("0", map[string]interface{}{"ping": tt}); err == nil {
+			t.Errorf("%v: want error", tt)
+		}
+	}
+}
@@ -0,0 +1,235 @@
+package runn
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const postmanSchema = "https://schema.getpostman.com/json/collection/v2.1.0/collection.json"
+
+// postmanVarRe matches the templates that reference `vars` or `secrets` ( e.g. `{{ vars.id }}` ).
+var postmanVarRe = regexp.MustCompile(`\{\{\s*(?:vars|secrets)\.([A-Za-z_][A-Za-z0-9_]*)\s*\}\}`)
+
+type postmanCollection struct {
+	Info     postmanInfo       `json:"info"`
+	Item     []postmanItem     `json:"item"`
+	Variable []postmanKeyValue `json:"variable,omitempty"`
+}
+
+type postmanInfo struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Schema      string `json:"schema"`
+}
+
+type postmanItem struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	Item        []postmanItem   `json:"item,omitempty"`
+	Request     *postmanRequest `json:"request,omitempty"`
+}
+
+type postmanRequest struct {
+	Method string              `json:"method"`
+	Header []postmanKeyValue   `json:"header"`
+	URL    string              `json:"url"`
+	Body   *postmanRequestBody `json:"body,omitempty"`
+}
+
+type postmanRequestBody struct {
+	Mode       string                 `json:"mode"`
+	Raw        string                 `json:"raw,omitempty"`
+	URLEncoded []postmanKeyValue      `json:"urlencoded,omitempty"`
+	FormData   []postmanKeyValue      `json:"formdata,omitempty"`
+	Options    map[string]interface{} `json:"options,omitempty"`
+}
+
+type postmanKeyValue struct {
+	Key   string `json:"key"`
+	Value string `json:"value,omitempty"`
+	Type  string `json:"type,omitempty"`
+	Src   string `json:"src,omitempty"`
+}
+
+// ExportPostman - Export the steps of HTTP runners as a Postman collection ( v2.1 ).
+// It is a static export from the parsed steps, so the steps are not run and the included runbooks are not exported.
+// The templates referencing `vars` and `secrets` ( e.g. `{{ vars.id }}` ) are converted to the variables of Postman ( e.g. `{{id}}` ),
+// and the endpoints of the runners are exported as the variables named by the runner keys. The values of secrets are not exported.
+func (o *operator) ExportPostman(w io.Writer) error {
+	folder := postmanItem{
+		Name:        o.desc,
+		Description: o.bookPath,
+	}
+	runners := []string{}
+	for _, s := range o.steps {
+		if s.httpRunner == nil || s.httpRequest == nil {
+			continue
+		}
+		item, err := o.postmanItem(s)
+		if err != nil {
+			return err
+		}
+		if !contains(runners, s.httpRunner.name) {
+			runners = append(runners, s.httpRunner.name)
+		}
+		folder.Item = append(folder.Item, item)
+	}
+	c := postmanCollection{
+		Info: postmanInfo{
+			Name:        o.desc,
+			Description: o.bookPath,
+			Schema:      postmanSchema,
+		},
+		Item: []postmanItem{folder},
+	}
+	sort.Strings(runners)
+	for _, k := range runners {
+		var endpoint string
+		if e := o.httpRunners[k].endpoint; e != nil {
+			endpoint = strings.TrimSuffix(e.String(), "/")
+		}
+		c.Variable = append(c.Variable, postmanKeyValue{Key: k, Value: endpoint})
+	}
+	for _, k := range sortedKeys(o.store.vars) {
+		c.Variable = append(c.Variable, postmanKeyValue{Key: k, Value: postmanValue(o.store.vars[k])})
+	}
+	for _, k := range sortedKeys(o.store.secrets) {
+		c.Variable = append(c.Variable, postmanKeyValue{Key: k})
+	}
+	b, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintln(w, string(b)); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (o *operator) postmanItem(s *step) (postmanItem, error) {
+	req, err := parseHTTPRequest(s.httpRequest)
+	if err != nil {
+		return postmanItem{}, fmt.Errorf("failed to export steps.%s: %w", s.key, err)
+	}
+	name := s.desc
+	if name == "" {
+		name = fmt.Sprintf("%s %s", req.method, req.path)
+	}
+	pr := &postmanRequest{
+		Method: req.method,
+		Header: []postmanKeyValue{},
+		URL:    fmt.Sprintf("{{%s}}%s", s.httpRunner.name, postmanTemplate(req.path)),
+	}
+	headers := map[string]interface{}{}
+	for k, v := range s.httpRunner.headers {
+		headers[k] = v
+	}
+	for k, v := range req.headers {
+		headers[k] = v
+	}
+	mediaType := req.mediaType
+	if req.bareBody {
+		mediaType = s.httpRunner.defaultContentType
+	}
+	if mediaType != "" && req.body != nil {
+		if _, ok := headers["Content-Type"]; !ok && mediaType != MediaTypeMultipartFormData {
+			headers["Content-Type"] = mediaType
+		}
+	}
+	for _, k := range sortedKeys(headers) {
+		pr.Header = append(pr.Header, postmanKeyValue{Key: k, Value: postmanTemplate(postmanValue(headers[k]))})
+	}
+	if req.body != nil {
+		pr.Body, err = postmanBody(mediaType, req.body, o.root)
+		if err != nil {
+			return postmanItem{}, fmt.Errorf("failed to export steps.%s: %w", s.key, err)
+		}
+	}
+	return postmanItem{Name: name, Request: pr}, nil
+}
+
+func postmanBody(mediaType string, body interface{}, root string) (*postmanRequestBody, error) {
+	switch mediaType {
+	case MediaTypeApplicationFormUrlencoded:
+		m, ok := body.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid body: %v", body)
+		}
+		pb := &postmanRequestBody{Mode: "urlencoded"}
+		for _, k := range sortedKeys(m) {
+			vs, ok := m[k].([]interface{})
+			if !ok {
+				vs = []interface{}{m[k]}
+			}
+			for _, v := range vs {
+				pb.URLEncoded = append(pb.URLEncoded, postmanKeyValue{Key: k, Value: postmanTemplate(postmanValue(v))})
+			}
+		}
+		return pb, nil
+	case MediaTypeMultipartFormData:
+		m, ok := body.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid body: %v", body)
+		}
+		pb := &postmanRequestBody{Mode: "formdata"}
+		for _, k := range sortedKeys(m) {
+			vs, ok := m[k].([]interface{})
+			if !ok {
+				vs = []interface{}{m[k]}
+			}
+			for _, v := range vs {
+				sv := postmanValue(v)
+				if _, err := os.Stat(filepath.Join(root, sv)); err == nil {
+					pb.FormData = append(pb.FormData, postmanKeyValue{Key: k, Type: "file", Src: sv})
+					continue
+				}
+				pb.FormData = append(pb.FormData, postmanKeyValue{Key: k, Value: postmanTemplate(sv), Type: "text"})
+			}
+		}
+		return pb, nil
+	case MediaTypeTextPlain:
+		return &postmanRequestBody{
+			Mode:    "raw",
+			Raw:     postmanTemplate(postmanValue(body)),
+			Options: map[string]interface{}{"raw": map[string]interface{}{"language": "text"}},
+		}, nil
+	default:
+		b, err := json.MarshalIndent(body, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return &postmanRequestBody{
+			Mode:    "raw",
+			Raw:     postmanTemplate(string(b)),
+			Options: map[string]interface{}{"raw": map[string]interface{}{"language": "json"}},
+		}, nil
+	}
+}
+
+// postmanTemplate converts the templates referencing `vars` and `secrets` to the variables of Postman.
+func postmanTemplate(s string) string {
+	return postmanVarRe.ReplaceAllString(s, "{{$1}}")
+}
+
+func postmanValue(v interface{}) string {
+	switch vv := v.(type) {
+	case string:
+		return vv
+	case nil:
+		return ""
+	case map[string]interface{}, []interface{}:
+		b, err := json.Marshal(vv)
+		if err != nil {
+			return fmt.Sprintf("%v", vv)
+		}
+		return string(b)
+	default:
+		return fmt.Sprintf("%v", vv)
+	}
+}
@@ -0,0 +1,49 @@
+package runn
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/tenntenn/golden"
+)
+
+func TestExportPostman(t *testing.T) {
+	o, err := New(Book("testdata/postman.yml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := new(bytes.Buffer)
+	if err := o.ExportPostman(got); err != nil {
+		t.Fatal(err)
+	}
+	if !json.Valid(got.Bytes()) {
+		t.Errorf("invalid JSON: %s", got.String())
+	}
+	if os.Getenv("UPDATE_GOLDEN") != "" {
+		golden.Update(t, "testdata", "export_postman", got)
+		return
+	}
+	if diff := golden.Diff(t, "testdata", "export_postman", got); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestPostmanTemplate(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"/users/{{ vars.id }}", "/users/{{id}}"},
+		{"Bearer {{secrets.token}}", "Bearer {{token}}"},
+		{"{{ vars.user.id }}", "{{ vars.user.id }}"},
+		{"{{ steps[0].res.body.id }}", "{{ steps[0].res.body.id }}"},
+		{"/users", "/users"},
+	}
+	for _, tt := range tests {
+		if got := postmanTemplate(tt.in); got != tt.want {
+			t.Errorf("got %v\nwant %v", got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package runn
+
+import (
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/fatih/color"
+)
+
+// progress - Streaming reporter of RunN that writes a mark per runbook as it finishes ( `.` pass, `F` fail, `S` skip ).
+type progress struct {
+	out     io.Writer
+	passed  int
+	failed  int
+	skipped int
+	green   func(a ...interface{}) string
+	yellow  func(a ...interface{}) string
+	red     func(a ...interface{}) string
+	mu      sync.Mutex
+}
+
+func newProgress(out io.Writer, enabled *bool) *progress {
+	return &progress{
+		out:    out,
+		green:  colorSprintFunc(color.FgGreen, enabled),
+		yellow: colorSprintFunc(color.FgYellow, enabled),
+		red:    colorSprintFunc(color.FgRed, enabled),
+	}
+}
+
+// report writes the mark of the result of the runbook.
+func (p *progress) report(r *RunResult) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	switch {
+	case r.Err != nil:
+		p.failed++
+		_, _ = fmt.Fprint(p.out, p.red("F"))
+	case r.Skipped:
+		p.skipped++
+		_, _ = fmt.Fprint(p.out, p.yellow("S"))
+	default:
+		p.passed++
+		_, _ = fmt.Fprint(p.out, p.green("."))
+	}
+}
+
+// done ends the line of the marks and writes the summary of the counts.
+func (p *progress) done() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	sprint := p.green
+	if p.failed > 0 {
+		sprint = p.red
+	}
+	_, _ = fmt.Fprintf(p.out, "\n%s\n", sprint(fmt.Sprintf("%d passed, %d failed, %d skipped", p.passed, p.failed, p.skipped)))
+}
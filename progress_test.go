@@ -0,0 +1,40 @@
+package runn
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestProgress(t *testing.T) {
+	tests := []struct {
+		failFast bool
+		want     string
+		wantOut  string
+	}{
+		{false, ".F.S\n2 passed, 1 failed, 1 skipped\n", "\n1) t/b/runn_1_fail.yml\n"},
+		{true, ".F\n1 passed, 1 failed, 0 skipped\n", "\n1) t/b/runn_1_fail.yml\n"},
+	}
+	for _, tt := range tests {
+		t.Run("", func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			ops, err := Load("testdata/book/runn_*", Progress(buf), Color(false), FailFast(tt.failFast))
+			if err != nil {
+				t.Fatal(err)
+			}
+			_ = ops.RunN(context.Background())
+			if got := buf.String(); got != tt.want {
+				t.Errorf("got %q\nwant %q", got, tt.want)
+			}
+			// the line of the marks is already ended
+			out := new(bytes.Buffer)
+			if err := ops.Result().Out(out, false); err != nil {
+				t.Fatal(err)
+			}
+			if got := out.String(); !strings.HasPrefix(got, tt.wantOut) {
+				t.Errorf("got %q\nwant prefix %q", got, tt.wantOut)
+			}
+		})
+	}
+}
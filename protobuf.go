@@ -0,0 +1,148 @@
+package runn
+
+import (
+	"bytes"
+	"fmt"
+	"mime"
+	"path/filepath"
+
+	"github.com/goccy/go-json"
+	"github.com/golang/protobuf/jsonpb" //nolint
+	"github.com/jhump/protoreflect/desc"
+	"github.com/jhump/protoreflect/desc/protoparse"
+	"github.com/jhump/protoreflect/dynamic"
+)
+
+const MediaTypeApplicationProtobuf = "application/x-protobuf"
+
+// httpProtobuf - message types of the request body and the response body encoded in Protocol Buffers ( `protobuf:` ).
+type httpProtobuf struct {
+	request  string
+	response string
+
+	requestDesc  *desc.MessageDescriptor
+	responseDesc *desc.MessageDescriptor
+}
+
+// parseProtos parses the .proto files ( relative path from the runbook ).
+// When import paths are not specified, the directory of each .proto file is used as the import path.
+func parseProtos(protos, importPaths []string, root string) ([]*desc.FileDescriptor, error) {
+	if len(protos) == 0 {
+		return nil, nil
+	}
+	ips := []string{}
+	for _, ip := range importPaths {
+		ips = append(ips, fp(ip, root))
+	}
+	files := []string{}
+	for _, p := range protos {
+		f := fp(p, root)
+		if len(importPaths) == 0 && !contains(ips, filepath.Dir(f)) {
+			ips = append(ips, filepath.Dir(f))
+		}
+		files = append(files, f)
+	}
+	resolved, err := protoparse.ResolveFilenames(ips, files...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve protos: %w", err)
+	}
+	p := protoparse.Parser{
+		ImportPaths:           ips,
+		IncludeSourceCodeInfo: false,
+	}
+	fds, err := p.ParseFiles(resolved...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse protos: %w", err)
+	}
+	return fds, nil
+}
+
+// findMessage returns the descriptor of the message type of the fully-qualified name from the parsed .proto files.
+func (rnr *httpRunner) findMessage(name string) (*desc.MessageDescriptor, error) {
+	for _, fd := range rnr.protos {
+		if md := findMessageInFile(fd, name, map[string]struct{}{}); md != nil {
+			return md, nil
+		}
+	}
+	return nil, fmt.Errorf("cannot find message type in protos of %s: %s", rnr.name, name)
+}
+
+func findMessageInFile(fd *desc.FileDescriptor, name string, seen map[string]struct{}) *desc.MessageDescriptor {
+	if _, ok := seen[fd.GetName()]; ok {
+		return nil
+	}
+	seen[fd.GetName()] = struct{}{}
+	if md := fd.FindMessage(name); md != nil {
+		return md
+	}
+	for _, dep := range fd.GetDependencies() {
+		if md := findMessageInFile(dep, name, seen); md != nil {
+			return md
+		}
+	}
+	return nil
+}
+
+// resolveProtobuf resolves the message types of `protobuf:` from the parsed .proto files of the runner.
+func (rnr *httpRunner) resolveProtobuf(p *httpProtobuf) error {
+	if len(rnr.protos) == 0 {
+		return fmt.Errorf("protos of %s are not set", rnr.name)
+	}
+	if p.request != "" {
+		md, err := rnr.findMessage(p.request)
+		if err != nil {
+			return err
+		}
+		p.requestDesc = md
+	}
+	if p.response != "" {
+		md, err := rnr.findMessage(p.response)
+		if err != nil {
+			return err
+		}
+		p.responseDesc = md
+	}
+	return nil
+}
+
+// encodeProtobuf encodes the body built from the map into the message of Protocol Buffers.
+func encodeProtobuf(md *desc.MessageDescriptor, body interface{}) ([]byte, error) {
+	b, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	msg := dynamic.NewMessage(md)
+	if err := jsonpb.Unmarshal(bytes.NewBuffer(b), msg); err != nil {
+		return nil, fmt.Errorf("failed to encode body as %s: %w", md.GetFullyQualifiedName(), err)
+	}
+	return msg.Marshal()
+}
+
+// decodeProtobuf decodes the message of Protocol Buffers into the map in the same way as gRPC Runner.
+func decodeProtobuf(md *desc.MessageDescriptor, b []byte) (map[string]interface{}, error) {
+	msg := dynamic.NewMessage(md)
+	if err := msg.Unmarshal(b); err != nil {
+		return nil, fmt.Errorf("failed to decode body as %s: %w", md.GetFullyQualifiedName(), err)
+	}
+	buf := new(bytes.Buffer)
+	marshaler := jsonpb.Marshaler{
+		OrigName: true,
+	}
+	if err := marshaler.Marshal(buf, msg); err != nil {
+		return nil, err
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// isProtobufMediaType returns whether the content type is Protocol Buffers.
+func isProtobufMediaType(ct string) bool {
+	mt, _, err := mime.ParseMediaType(ct)
+	if err != nil {
+		return false
+	}
+	return mt == MediaTypeApplicationProtobuf || mt == "application/protobuf"
+}
@@ -0,0 +1,76 @@
+package runn
+
+import (
+	"context"
+	"fmt"
+)
+
+// runRepeat - run all steps of the runbook `repeat:` times in the same operator ( e.g. to check idempotency ).
+// The steps of the completed iterations are available as `iterations[n].steps`, and `repeatTest:` is tested across them after all iterations.
+func (o *operator) runRepeat(ctx context.Context) error {
+	if o.repeat < 2 {
+		panic("invalid usage")
+	}
+	defer func() {
+		o.store.iteration = nil
+		o.store.iterations = nil
+	}()
+	results := []*RunResult{}
+	o.store.iterations = []interface{}{}
+	var err error
+	for i := 0; i < o.repeat; i++ {
+		if i > 0 {
+			// Renew runners
+			for _, r := range o.cdpRunners {
+				if err := r.Renew(); err != nil {
+					return err
+				}
+			}
+		}
+		idx := i
+		o.store.iteration = &idx
+		err = o.runInternal(ctx)
+		results = append(results, o.runResult)
+		if err != nil {
+			err = fmt.Errorf("repeat[%d]: %w", i, err)
+			break
+		}
+		if o.Skipped() {
+			break
+		}
+		o.store.iterations = append(o.store.iterations, map[string]interface{}{
+			storeStepsKey: o.store.toMap()[storeStepsKey],
+		})
+	}
+	if err == nil && !o.Skipped() && o.repeatTest != "" {
+		store := o.store.toMap()
+		tf, terr := EvalCond(o.repeatTest, store)
+		switch {
+		case terr != nil:
+			err = fmt.Errorf("repeatTest failed on %s: %w", o.bookPathOrID(), terr)
+		case !tf:
+			bt, terr := buildTree(o.repeatTest, store)
+			if terr != nil {
+				err = fmt.Errorf("repeatTest failed on %s: %w", o.bookPathOrID(), terr)
+				break
+			}
+			err = fmt.Errorf("repeatTest failed on %s: (%s) is not true\n%s", o.bookPathOrID(), o.repeatTest, bt)
+		}
+		err = o.secretMasker.maskError(err)
+	}
+
+	// The result of the runbook is the result of the last iteration with the results of all iterations
+	last := results[len(results)-1]
+	o.runResult = &RunResult{
+		Desc:             last.Desc,
+		Path:             last.Path,
+		Skipped:          last.Skipped,
+		Err:              err,
+		StepResults:      last.StepResults,
+		Store:            last.Store,
+		ShuffleStepsSeed: last.ShuffleStepsSeed,
+		Iterations:       results,
+		masker:           o.secretMasker,
+	}
+	return err
+}
@@ -0,0 +1,152 @@
+package runn
+
+import (
+	"fmt"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+)
+
+var _ Capturer = (*requestCounter)(nil)
+
+// requestCounter - Capturer that counts HTTP/gRPC requests and DB queries per runner for `expectRequests:`, `expectNoRequests:` and `maxQueries:`.
+type requestCounter struct {
+	counts  map[string]int
+	queries map[string]int
+	// paths of the requests ( the URL path for HTTP and `/service/method` for gRPC )
+	paths []string
+	mu    sync.Mutex
+}
+
+func newRequestCounter() *requestCounter {
+	return &requestCounter{
+		counts:  map[string]int{},
+		queries: map[string]int{},
+	}
+}
+
+func (c *requestCounter) reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.counts = map[string]int{}
+	c.queries = map[string]int{}
+	c.paths = nil
+}
+
+func (c *requestCounter) count(name, p string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.counts[name]++
+	c.paths = append(c.paths, p)
+}
+
+func (c *requestCounter) countQuery(name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.queries[name]++
+}
+
+func (c *requestCounter) total() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return sumCounts(c.counts)
+}
+
+func (c *requestCounter) totalQueries() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return sumCounts(c.queries)
+}
+
+// String returns the number of requests per runner such as `req: 2, greq: 1`.
+func (c *requestCounter) String() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return countsString(c.counts)
+}
+
+// queriesString returns the number of queries per runner such as `db: 3`.
+func (c *requestCounter) queriesString() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return countsString(c.queries)
+}
+
+// matchPath returns the first requested path and the pattern that matches it.
+func (c *requestCounter) matchPath(patterns []string) (string, string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, p := range c.paths {
+		for _, pattern := range patterns {
+			if ok, _ := path.Match(pattern, p); ok {
+				return p, pattern
+			}
+		}
+	}
+	return "", ""
+}
+
+func sumCounts(counts map[string]int) int {
+	t := 0
+	for _, n := range counts {
+		t += n
+	}
+	return t
+}
+
+func countsString(counts map[string]int) string {
+	names := []string{}
+	for k := range counts {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	s := []string{}
+	for _, k := range names {
+		s = append(s, fmt.Sprintf("%s: %d", k, counts[k]))
+	}
+	return strings.Join(s, ", ")
+}
+
+func (c *requestCounter) CaptureStart(ids IDs, bookPath, desc string) {}
+func (c *requestCounter) CaptureResult(ids IDs, result *RunResult)    {}
+func (c *requestCounter) CaptureEnd(ids IDs, bookPath, desc string)   {}
+
+func (c *requestCounter) CaptureHTTPRequest(name string, req *http.Request) {
+	c.count(name, req.URL.Path)
+}
+
+func (c *requestCounter) CaptureHTTPResponse(name string, res *http.Response) {}
+
+func (c *requestCounter) CaptureGRPCStart(name string, typ GRPCType, service, method string) {
+	c.count(name, fmt.Sprintf("/%s/%s", service, method))
+}
+
+func (c *requestCounter) CaptureGRPCRequestHeaders(h map[string][]string)                  {}
+func (c *requestCounter) CaptureGRPCRequestMessage(m map[string]interface{})               {}
+func (c *requestCounter) CaptureGRPCResponseStatus(status int)                             {}
+func (c *requestCounter) CaptureGRPCResponseHeaders(h map[string][]string)                 {}
+func (c *requestCounter) CaptureGRPCResponseMessage(m map[string]interface{})              {}
+func (c *requestCounter) CaptureGRPCResponseTrailers(t map[string][]string)                {}
+func (c *requestCounter) CaptureGRPCClientClose()                                          {}
+func (c *requestCounter) CaptureGRPCEnd(name string, typ GRPCType, service, method string) {}
+func (c *requestCounter) CaptureCDPStart(name string)                                      {}
+func (c *requestCounter) CaptureCDPAction(a CDPAction)                                     {}
+func (c *requestCounter) CaptureCDPResponse(a CDPAction, res map[string]interface{})       {}
+func (c *requestCounter) CaptureCDPEnd(name string)                                        {}
+func (c *requestCounter) CaptureSSHCommand(command string)                                 {}
+func (c *requestCounter) CaptureSSHStdout(stdout string)                                   {}
+func (c *requestCounter) CaptureSSHStderr(stderr string)                                   {}
+func (c *requestCounter) CaptureDBStatement(name string, stmt string) {
+	c.countQuery(name)
+}
+
+func (c *requestCounter) CaptureDBResponse(name string, res *DBResponse) {}
+func (c *requestCounter) CaptureExecCommand(command string)              {}
+func (c *requestCounter) CaptureExecEnv(env map[string]string)           {}
+func (c *requestCounter) CaptureExecStdin(stdin string)                  {}
+func (c *requestCounter) CaptureExecStdout(stdout string)                {}
+func (c *requestCounter) CaptureExecStderr(stderr string)                {}
+func (c *requestCounter) SetCurrentIDs(ids IDs)                          {}
+func (c *requestCounter) Errs() error                                    { return nil }
@@ -4,15 +4,25 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
 	"strings"
 	"sync"
 	"sync/atomic"
+	"text/template"
+	"time"
 
 	"github.com/fatih/color"
 )
 
 type result string
 
+const maskedValue = "*****"
+
+var sensitiveKeyRe = regexp.MustCompile(`(?i)(password|passwd|secret|token|authorization|cookie|api[-_]?key|private[-_]?key)`)
+
 const (
 	resultSuccess result = "success"
 	resultFailure result = "failure"
@@ -26,19 +36,48 @@ type RunResult struct {
 	Err         error
 	StepResults []*StepResult
 	Store       map[string]interface{}
+	// ShuffleStepsSeed is the seed used to randomize the order of running steps ( ShuffleSteps )
+	ShuffleStepsSeed *int64
+	// Iterations are the results of each iteration of `repeat:`
+	Iterations []*RunResult
+	// BytesSent and BytesReceived are the total bytes of the request and response bodies of the HTTP runners
+	BytesSent     int64
+	BytesReceived int64
+
+	// mask the values of secrets in the outputs
+	masker *secretMasker
 }
 
 type StepResult struct {
-	Key     string
-	Desc    string
+	Key  string
+	Desc string
+	// Path and Line are the location of the step in the runbook file
+	Path    string
+	Line    int
 	Skipped bool
 	Err     error
+	// Elapsed is the elapsed time of running the step
+	Elapsed time.Duration
+	// TTFB is the time to first byte of the response of the HTTP runner ( 0 for the other runners )
+	TTFB time.Duration
+	// RunnerKey and RunnerType are the key and the type of the runner of the step
+	RunnerKey  string
+	RunnerType RunnerType
+	// Summary is the summary of the request of the step ( e.g. `GET /users/1` )
+	Summary string
+	// Assertions are the results of each condition of `test:`
+	Assertions []AssertionResult
 }
 
 type runNResult struct {
 	Total      atomic.Int64
 	RunResults []*RunResult
 	mu         sync.Mutex
+	color      *bool
+	// include the store of each runbook in the JSON output
+	includeStore bool
+	// whether the line of the marks of Progress is already ended
+	progressed bool
 }
 
 type runNResultSimplified struct {
@@ -47,17 +86,47 @@ type runNResultSimplified struct {
 	Failure int64                 `json:"failure"`
 	Skipped int64                 `json:"skipped"`
 	Results []runResultSimplified `json:"results"`
+	Elapsed []stepElapsedStats    `json:"elapsed,omitempty"`
 }
 
 type runResultSimplified struct {
 	Path   string                 `json:"path"`
 	Result result                 `json:"result"`
 	Steps  []stepResultSimplified `json:"steps"`
+	// Store is the final store of the runbook ( IncludeStoreInJSON )
+	Store map[string]interface{} `json:"store,omitempty"`
 }
 
 type stepResultSimplified struct {
-	Key    string `json:"key"`
-	Result result `json:"result"`
+	Key        string            `json:"key"`
+	Result     result            `json:"result"`
+	Assertions []AssertionResult `json:"assertions,omitempty"`
+}
+
+// stepElapsedStats - Statistics of elapsed time ( milliseconds ) of the step across run results.
+type stepElapsedStats struct {
+	Path  string  `json:"path"`
+	Key   string  `json:"key"`
+	Count int     `json:"count"`
+	Min   float64 `json:"min"`
+	P50   float64 `json:"p50"`
+	P90   float64 `json:"p90"`
+	P95   float64 `json:"p95"`
+	P99   float64 `json:"p99"`
+	Max   float64 `json:"max"`
+	// TTFB is the statistics of the time to first byte of the responses of the HTTP runner
+	TTFB *stepTTFBStats `json:"ttfb,omitempty"`
+}
+
+// stepTTFBStats - Statistics of time to first byte ( milliseconds ) of the step across run results.
+type stepTTFBStats struct {
+	Count int     `json:"count"`
+	Min   float64 `json:"min"`
+	P50   float64 `json:"p50"`
+	P90   float64 `json:"p90"`
+	P95   float64 `json:"p95"`
+	P99   float64 `json:"p99"`
+	Max   float64 `json:"max"`
 }
 
 func newRunResult(desc, path string) *RunResult {
@@ -81,39 +150,109 @@ func (r *runNResult) Simplify() runNResultSimplified {
 		Total: r.Total.Load(),
 	}
 	for _, rr := range r.RunResults {
+		rs := runResultSimplified{
+			Path:  rr.Path,
+			Steps: simplifyStepResults(rr.StepResults),
+		}
 		switch {
 		case rr.Err != nil:
 			s.Failure += 1
-			s.Results = append(s.Results, runResultSimplified{
-				Path:   rr.Path,
-				Result: resultFailure,
-				Steps:  simplifyStepResults(rr.StepResults),
-			})
+			rs.Result = resultFailure
 		case rr.Skipped:
 			s.Skipped += 1
-			s.Results = append(s.Results, runResultSimplified{
-				Path:   rr.Path,
-				Result: resultSkipped,
-				Steps:  simplifyStepResults(rr.StepResults),
-			})
+			rs.Result = resultSkipped
 		default:
 			s.Success += 1
-			s.Results = append(s.Results, runResultSimplified{
-				Path:   rr.Path,
-				Result: resultSuccess,
-				Steps:  simplifyStepResults(rr.StepResults),
-			})
+			rs.Result = resultSuccess
+		}
+		if r.includeStore && rr.Store != nil {
+			rs.Store, _ = storeForJSON(rr.masker.maskValue(rr.Store)).(map[string]interface{})
 		}
+		s.Results = append(s.Results, rs)
 	}
+	s.Elapsed = r.ElapsedStats()
 	return s
 }
 
+// ElapsedStats returns the percentile statistics of elapsed time of each step ( per runbook path and step key ) across run results.
+// Skipped steps and steps that were not measured are excluded.
+func (r *runNResult) ElapsedStats() []stepElapsedStats {
+	type stepID struct {
+		path string
+		key  string
+	}
+	ids := []stepID{}
+	samples := map[stepID][]time.Duration{}
+	ttfbs := map[stepID][]time.Duration{}
+	for _, rr := range r.RunResults {
+		for _, sr := range rr.StepResults {
+			if sr == nil || sr.Skipped || sr.Elapsed <= 0 {
+				continue
+			}
+			id := stepID{path: rr.Path, key: sr.Key}
+			if _, ok := samples[id]; !ok {
+				ids = append(ids, id)
+			}
+			samples[id] = append(samples[id], sr.Elapsed)
+			if sr.TTFB > 0 {
+				ttfbs[id] = append(ttfbs[id], sr.TTFB)
+			}
+		}
+	}
+	stats := []stepElapsedStats{}
+	for _, id := range ids {
+		d := samples[id]
+		sort.Slice(d, func(i, j int) bool { return d[i] < d[j] })
+		s := stepElapsedStats{
+			Path:  id.path,
+			Key:   id.key,
+			Count: len(d),
+			Min:   milliseconds(d[0]),
+			P50:   milliseconds(percentile(d, 50)),
+			P90:   milliseconds(percentile(d, 90)),
+			P95:   milliseconds(percentile(d, 95)),
+			P99:   milliseconds(percentile(d, 99)),
+			Max:   milliseconds(d[len(d)-1]),
+		}
+		if t := ttfbs[id]; len(t) > 0 {
+			sort.Slice(t, func(i, j int) bool { return t[i] < t[j] })
+			s.TTFB = &stepTTFBStats{
+				Count: len(t),
+				Min:   milliseconds(t[0]),
+				P50:   milliseconds(percentile(t, 50)),
+				P90:   milliseconds(percentile(t, 90)),
+				P95:   milliseconds(percentile(t, 95)),
+				P99:   milliseconds(percentile(t, 99)),
+				Max:   milliseconds(t[len(t)-1]),
+			}
+		}
+		stats = append(stats, s)
+	}
+	return stats
+}
+
+// percentile returns the p-th percentile of sorted durations using the nearest-rank method.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	i := int(math.Ceil(p/100*float64(len(sorted)))) - 1
+	if i < 0 {
+		i = 0
+	}
+	return sorted[i]
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
 func (r *runNResult) Out(out io.Writer, verbose bool) error {
 	var ts, fs string
-	green := color.New(color.FgGreen).SprintFunc()
-	red := color.New(color.FgRed).SprintFunc()
+	green := colorSprintFunc(color.FgGreen, r.color)
+	red := colorSprintFunc(color.FgRed, r.color)
 
-	_, _ = fmt.Fprintln(out, "")
+	if !r.progressed {
+		// end the line of the marks of the runbooks
+		_, _ = fmt.Fprintln(out, "")
+	}
 	if !verbose && r.HasFailure() {
 		_, _ = fmt.Fprintln(out, "")
 		i := 1
@@ -127,6 +266,9 @@ func (r *runNResult) Out(out io.Writer, verbose bool) error {
 					continue
 				}
 				_, _ = fmt.Fprintf(out, SprintMultilinef("  %s\n", "%v", red(fmt.Sprintf("Failure/Error: %s", strings.TrimRight(sr.Err.Error(), "\n")))))
+				if sr.Path != "" && sr.Line > 0 {
+					_, _ = fmt.Fprintf(out, "  # %s:%d\n", sr.Path, sr.Line)
+				}
 			}
 			i++
 		}
@@ -172,14 +314,110 @@ func (r *runNResult) OutJSON(out io.Writer) error {
 	return nil
 }
 
+// OutTemplate outputs the result using the Go text/template ( e.g. `{{ .Success }}/{{ .Total }} passed` ).
+// The data of the template is the simplified result that is the same as the JSON output, and the helper functions are available ( see outTemplateFuncs ).
+func (r *runNResult) OutTemplate(out io.Writer, tmpl string) error {
+	t, err := template.New("out").Funcs(outTemplateFuncs).Parse(tmpl)
+	if err != nil {
+		return fmt.Errorf("invalid output template: %w", err)
+	}
+	b := new(strings.Builder)
+	if err := t.Execute(b, r.Simplify()); err != nil {
+		return fmt.Errorf("failed to execute output template: %w", err)
+	}
+	s := b.String()
+	if !strings.HasSuffix(s, "\n") {
+		s += "\n"
+	}
+	if _, err := fmt.Fprint(out, s); err != nil {
+		return err
+	}
+	return nil
+}
+
+var outTemplateFuncs = template.FuncMap{
+	// json returns the value as JSON
+	"json": func(v interface{}) (string, error) {
+		b, err := json.Marshal(v)
+		if err != nil {
+			return "", err
+		}
+		return string(b), nil
+	},
+	// shortenPath returns the shortened path of the runbook
+	"shortenPath": ShortenPath,
+	// percent returns the percentage of n in total ( e.g. `{{ percent .Success .Total }}` )
+	"percent": func(n, total int64) string {
+		if total == 0 {
+			return "0.0"
+		}
+		return fmt.Sprintf("%.1f", float64(n)*100/float64(total))
+	},
+	// failures returns the results of failed runbooks
+	"failures": func(results []runResultSimplified) []runResultSimplified {
+		var f []runResultSimplified
+		for _, rs := range results {
+			if rs.Result == resultFailure {
+				f = append(f, rs)
+			}
+		}
+		return f
+	},
+	"join":  strings.Join,
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+}
+
+// storeForJSON returns a copy of the store that can be marshaled to JSON.
+// Functions are omitted and the values of sensitive keys ( e.g. password, token, Authorization ) are masked.
+func storeForJSON(v interface{}) interface{} {
+	if v == nil {
+		return nil
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			return v
+		}
+		m := map[string]interface{}{}
+		iter := rv.MapRange()
+		for iter.Next() {
+			k := iter.Key().String()
+			vv := iter.Value().Interface()
+			if vv != nil && reflect.TypeOf(vv).Kind() == reflect.Func {
+				continue
+			}
+			if vv != nil && sensitiveKeyRe.MatchString(k) {
+				m[k] = maskedValue
+				continue
+			}
+			m[k] = storeForJSON(vv)
+		}
+		return m
+	case reflect.Slice, reflect.Array:
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			return v
+		}
+		s := make([]interface{}, 0, rv.Len())
+		for i := 0; i < rv.Len(); i++ {
+			s = append(s, storeForJSON(rv.Index(i).Interface()))
+		}
+		return s
+	default:
+		return v
+	}
+}
+
 func simplifyStepResults(stepResults []*StepResult) []stepResultSimplified {
 	simplified := []stepResultSimplified{}
 	for _, sr := range stepResults {
 		switch {
 		case sr.Err != nil:
 			simplified = append(simplified, stepResultSimplified{
-				Key:    sr.Key,
-				Result: resultFailure,
+				Key:        sr.Key,
+				Result:     resultFailure,
+				Assertions: sr.Assertions,
 			})
 		case sr.Skipped:
 			simplified = append(simplified, stepResultSimplified{
@@ -188,8 +426,9 @@ func simplifyStepResults(stepResults []*StepResult) []stepResultSimplified {
 			})
 		default:
 			simplified = append(simplified, stepResultSimplified{
-				Key:    sr.Key,
-				Result: resultSuccess,
+				Key:        sr.Key,
+				Result:     resultSuccess,
+				Assertions: sr.Assertions,
 			})
 		}
 	}
@@ -3,9 +3,14 @@ package runn
 import (
 	"bytes"
 	"fmt"
+	"net/http"
+	"net/url"
 	"os"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/google/go-cmp/cmp"
 	"github.com/tenntenn/golden"
 )
 
@@ -74,6 +79,19 @@ func TestResultOut(t *testing.T) {
 				Err:  ErrDummy,
 			},
 		}), true},
+		{newRunNResult(t, 2, []*RunResult{
+			{
+				Path: "testdata/book/runn_0_success.yml",
+				Err:  nil,
+			},
+			{
+				Path: "testdata/book/runn_1_fail.yml",
+				Err:  ErrDummy,
+				StepResults: []*StepResult{
+					{Key: "0", Path: "testdata/book/runn_1_fail.yml", Line: 3, Err: ErrDummy},
+				},
+			},
+		}), false},
 	}
 	for i, tt := range tests {
 		key := fmt.Sprintf("result_out_%d", i)
@@ -158,6 +176,71 @@ func TestResultOutJSON(t *testing.T) {
 				StepResults: []*StepResult{{Key: "0", Err: ErrDummy}},
 			},
 		})},
+		{newRunNResult(t, 4, []*RunResult{
+			{
+				Path:        "testdata/book/runn_0_success.yml",
+				Err:         nil,
+				StepResults: []*StepResult{{Key: "0", Err: nil, Elapsed: 10 * time.Millisecond}, {Key: "1", Err: nil, Elapsed: 1 * time.Millisecond}},
+			},
+			{
+				Path:        "testdata/book/runn_0_success.yml",
+				Err:         nil,
+				StepResults: []*StepResult{{Key: "0", Err: nil, Elapsed: 30 * time.Millisecond}, {Key: "1", Err: nil, Skipped: true}},
+			},
+			{
+				Path:        "testdata/book/runn_0_success.yml",
+				Err:         nil,
+				StepResults: []*StepResult{{Key: "0", Err: nil, Elapsed: 20 * time.Millisecond}, {Key: "1", Err: nil, Elapsed: 3 * time.Millisecond}},
+			},
+			{
+				Path:        "testdata/book/runn_1_fail.yml",
+				Err:         ErrDummy,
+				StepResults: []*StepResult{{Key: "0", Err: ErrDummy, Elapsed: 1500 * time.Microsecond}},
+			},
+		})},
+		{func() *runNResult {
+			r := newRunNResult(t, 1, []*RunResult{
+				{
+					Path:        "testdata/book/runn_0_success.yml",
+					Err:         nil,
+					StepResults: []*StepResult{{Key: "0", Err: nil}},
+					Store: map[string]interface{}{
+						"vars": map[string]interface{}{
+							"username": "alice",
+							"password": "passw0rd",
+						},
+						"steps": []map[string]interface{}{
+							{
+								"res": map[string]interface{}{
+									"status": 200,
+									"headers": http.Header{
+										"Content-Type":  []string{"application/json"},
+										"Authorization": []string{"Bearer xxxxx"},
+									},
+									"body": map[string]interface{}{
+										"access_token": "xxxxx",
+										"ids":          []interface{}{1, 2},
+									},
+								},
+							},
+						},
+						"urlencode": url.QueryEscape,
+					},
+				},
+			})
+			r.includeStore = true
+			return r
+		}()},
+		{newRunNResult(t, 1, []*RunResult{
+			{
+				Path: "testdata/book/runn_1_fail.yml",
+				Err:  ErrDummy,
+				StepResults: []*StepResult{{Key: "0", Err: ErrDummy, Assertions: []AssertionResult{
+					{Cond: "current.res.status == 200", Result: true},
+					{Cond: "current.res.body.name == 'alice'", Result: false, Values: map[string]interface{}{"current.res.body.name": "bob"}},
+				}}},
+			},
+		})},
 	}
 	for i, tt := range tests {
 		key := fmt.Sprintf("result_out_json_%d", i)
@@ -176,3 +259,129 @@ func TestResultOutJSON(t *testing.T) {
 		})
 	}
 }
+
+func TestResultOutTemplate(t *testing.T) {
+	r := newRunNResult(t, 3, []*RunResult{
+		{
+			Path:        "testdata/book/runn_0_success.yml",
+			StepResults: []*StepResult{{Key: "0", Err: nil}},
+		},
+		{
+			Path:        "testdata/book/runn_1_fail.yml",
+			Err:         ErrDummy,
+			StepResults: []*StepResult{{Key: "0", Err: ErrDummy}},
+		},
+		{
+			Path:        "testdata/book/runn_3.skip.yml",
+			Skipped:     true,
+			StepResults: []*StepResult{{Key: "0", Skipped: true}},
+		},
+	})
+	tests := []struct {
+		tmpl    string
+		want    string
+		wantErr bool
+	}{
+		{"{{ .Success }}/{{ .Total }} passed", "1/3 passed\n", false},
+		{"{{ percent .Success .Total }}%\n", "33.3%\n", false},
+		{"{{ range failures .Results }}{{ .Path }} {{ upper (printf \"%s\" .Result) }}{{ end }}", "testdata/book/runn_1_fail.yml FAILURE\n", false},
+		{"{{ json (index .Results 2).Steps }}", `[{"key":"0","result":"skipped"}]` + "\n", false},
+		{"{{ .Success ", "", true},
+		{"{{ .Unknown }}", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tmpl, func(t *testing.T) {
+			got := new(bytes.Buffer)
+			if err := r.OutTemplate(got, tt.tmpl); err != nil {
+				if !tt.wantErr {
+					t.Error(err)
+				}
+				return
+			}
+			if tt.wantErr {
+				t.Error("want error")
+			}
+			if diff := cmp.Diff(got.String(), tt.want); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestResultElapsedStats(t *testing.T) {
+	sr := []*StepResult{}
+	for i := 1; i <= 100; i++ {
+		sr = append(sr, &StepResult{Key: "0", Elapsed: time.Duration(101-i) * time.Millisecond})
+	}
+	r := newRunNResult(t, 1, []*RunResult{
+		{
+			Path:        "testdata/book/runn_0_success.yml",
+			StepResults: sr,
+		},
+	})
+	got := r.ElapsedStats()
+	want := []stepElapsedStats{
+		{Path: "testdata/book/runn_0_success.yml", Key: "0", Count: 100, Min: 1, P50: 50, P90: 90, P95: 95, P99: 99, Max: 100},
+	}
+	if diff := cmp.Diff(got, want, nil); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestResultElapsedStatsTTFB(t *testing.T) {
+	r := newRunNResult(t, 1, []*RunResult{
+		{
+			Path: "testdata/book/runn_0_success.yml",
+			StepResults: []*StepResult{
+				{Key: "0", Elapsed: 30 * time.Millisecond, TTFB: 10 * time.Millisecond},
+				{Key: "1", Elapsed: 5 * time.Millisecond},
+			},
+		},
+		{
+			Path: "testdata/book/runn_0_success.yml",
+			StepResults: []*StepResult{
+				{Key: "0", Elapsed: 40 * time.Millisecond, TTFB: 20 * time.Millisecond},
+				{Key: "1", Elapsed: 5 * time.Millisecond},
+			},
+		},
+	})
+	got := r.ElapsedStats()
+	want := []stepElapsedStats{
+		{
+			Path: "testdata/book/runn_0_success.yml", Key: "0", Count: 2, Min: 30, P50: 30, P90: 40, P95: 40, P99: 40, Max: 40,
+			TTFB: &stepTTFBStats{Count: 2, Min: 10, P50: 10, P90: 20, P95: 20, P99: 20, Max: 20},
+		},
+		{Path: "testdata/book/runn_0_success.yml", Key: "1", Count: 2, Min: 5, P50: 5, P90: 5, P95: 5, P99: 5, Max: 5},
+	}
+	if diff := cmp.Diff(got, want, nil); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestResultOutColor(t *testing.T) {
+	tests := []struct {
+		color bool
+		want  bool
+	}{
+		{true, true},
+		{false, false},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%v", tt.color), func(t *testing.T) {
+			r := newRunNResult(t, 1, []*RunResult{
+				{
+					Path: "testdata/book/runn_0_success.yml",
+					Err:  nil,
+				},
+			})
+			r.color = &tt.color
+			got := new(bytes.Buffer)
+			if err := r.Out(got, false); err != nil {
+				t.Fatal(err)
+			}
+			if strings.Contains(got.String(), "\x1b[") != tt.want {
+				t.Errorf("got %q", got.String())
+			}
+		})
+	}
+}
@@ -6,9 +6,13 @@ import (
 	"io"
 	"net/http"
 	"os"
+	"path"
 	"strings"
 
 	"github.com/Songmu/axslogparser"
+	"github.com/goccy/go-yaml/ast"
+	"github.com/goccy/go-yaml/parser"
+	"github.com/goccy/go-yaml/token"
 	"github.com/k1LoW/curlreq"
 	"github.com/k1LoW/expand"
 	"github.com/k1LoW/grpcurlreq"
@@ -16,34 +20,63 @@ import (
 )
 
 type runbook struct {
-	Desc        string                 `yaml:"desc"`
-	Runners     map[string]interface{} `yaml:"runners,omitempty"`
-	Vars        map[string]interface{} `yaml:"vars,omitempty"`
-	Steps       []yaml.MapSlice        `yaml:"steps"`
-	Debug       bool                   `yaml:"debug,omitempty"`
-	Interval    string                 `yaml:"interval,omitempty"`
-	If          string                 `yaml:"if,omitempty"`
-	SkipTest    bool                   `yaml:"skipTest,omitempty"`
-	Loop        interface{}            `yaml:"loop,omitempty"`
-	Concurrency string                 `yaml:"concurrency,omitempty"`
-	Force       bool                   `yaml:"force,omitempty"`
+	Desc                  string                     `yaml:"desc"`
+	Runners               map[string]interface{}     `yaml:"runners,omitempty"`
+	Vars                  map[string]interface{}     `yaml:"vars,omitempty"`
+	Secrets               map[string]interface{}     `yaml:"secrets,omitempty"`
+	Steps                 []yaml.MapSlice            `yaml:"steps"`
+	Debug                 bool                       `yaml:"debug,omitempty"`
+	Interval              string                     `yaml:"interval,omitempty"`
+	If                    string                     `yaml:"if,omitempty"`
+	SkipTest              bool                       `yaml:"skipTest,omitempty"`
+	Loop                  interface{}                `yaml:"loop,omitempty"`
+	Concurrency           string                     `yaml:"concurrency,omitempty"`
+	Force                 bool                       `yaml:"force,omitempty"`
+	Labels                []string                   `yaml:"labels,omitempty"`
+	Matrix                map[string]interface{}     `yaml:"matrix,omitempty"`
+	Export                map[string]string          `yaml:"export,omitempty"`
+	ExpectRequests        *int                       `yaml:"expectRequests,omitempty"`
+	ExpectNoRequests      []string                   `yaml:"expectNoRequests,omitempty"`
+	MaxQueries            *int                       `yaml:"maxQueries,omitempty"`
+	MaxResponseBytesTotal *int64                     `yaml:"maxResponseBytesTotal,omitempty"`
+	Repeat                int                        `yaml:"repeat,omitempty"`
+	RepeatTest            string                     `yaml:"repeatTest,omitempty"`
+	Teardown              map[string][]yaml.MapSlice `yaml:"teardown,omitempty"`
+	Templates             map[string]interface{}     `yaml:"templates,omitempty"`
+	Functions             map[string]interface{}     `yaml:"functions,omitempty"`
+	StepsFiles            []string                   `yaml:"stepsFiles,omitempty"`
 
-	useMap   bool
-	stepKeys []string
+	useMap    bool
+	stepKeys  []string
+	stepLines []int
 }
 
 type runbookMapped struct {
-	Desc        string                 `yaml:"desc,omitempty"`
-	Runners     map[string]interface{} `yaml:"runners,omitempty"`
-	Vars        map[string]interface{} `yaml:"vars,omitempty"`
-	Steps       yaml.MapSlice          `yaml:"steps,omitempty"`
-	Debug       bool                   `yaml:"debug,omitempty"`
-	Interval    string                 `yaml:"interval,omitempty"`
-	If          string                 `yaml:"if,omitempty"`
-	SkipTest    bool                   `yaml:"skipTest,omitempty"`
-	Loop        interface{}            `yaml:"loop,omitempty"`
-	Concurrency string                 `yaml:"concurrency,omitempty"`
-	Force       bool                   `yaml:"force,omitempty"`
+	Desc                  string                     `yaml:"desc,omitempty"`
+	Runners               map[string]interface{}     `yaml:"runners,omitempty"`
+	Vars                  map[string]interface{}     `yaml:"vars,omitempty"`
+	Secrets               map[string]interface{}     `yaml:"secrets,omitempty"`
+	Steps                 yaml.MapSlice              `yaml:"steps,omitempty"`
+	Debug                 bool                       `yaml:"debug,omitempty"`
+	Interval              string                     `yaml:"interval,omitempty"`
+	If                    string                     `yaml:"if,omitempty"`
+	SkipTest              bool                       `yaml:"skipTest,omitempty"`
+	Loop                  interface{}                `yaml:"loop,omitempty"`
+	Concurrency           string                     `yaml:"concurrency,omitempty"`
+	Force                 bool                       `yaml:"force,omitempty"`
+	Labels                []string                   `yaml:"labels,omitempty"`
+	Matrix                map[string]interface{}     `yaml:"matrix,omitempty"`
+	Export                map[string]string          `yaml:"export,omitempty"`
+	ExpectRequests        *int                       `yaml:"expectRequests,omitempty"`
+	ExpectNoRequests      []string                   `yaml:"expectNoRequests,omitempty"`
+	MaxQueries            *int                       `yaml:"maxQueries,omitempty"`
+	MaxResponseBytesTotal *int64                     `yaml:"maxResponseBytesTotal,omitempty"`
+	Repeat                int                        `yaml:"repeat,omitempty"`
+	RepeatTest            string                     `yaml:"repeatTest,omitempty"`
+	Teardown              map[string][]yaml.MapSlice `yaml:"teardown,omitempty"`
+	Templates             map[string]interface{}     `yaml:"templates,omitempty"`
+	Functions             map[string]interface{}     `yaml:"functions,omitempty"`
+	StepsFiles            []string                   `yaml:"stepsFiles,omitempty"`
 }
 
 func NewRunbook(desc string) *runbook {
@@ -70,6 +103,7 @@ func ParseRunbook(in io.Reader) (*runbook, error) {
 
 func parseRunbook(b []byte) (*runbook, error) {
 	rb := NewRunbook("")
+	src := b
 	repFn := expand.InterpolateRepFn(os.LookupEnv)
 	rep, err := expand.ReplaceYAML(string(b), repFn)
 	if err != nil {
@@ -81,10 +115,60 @@ func parseRunbook(b []byte) (*runbook, error) {
 			return nil, err
 		}
 	}
+	rb.stepLines = stepLines(src, len(rb.Steps))
 
 	return rb, nil
 }
 
+// stepLines returns the line numbers of steps in the runbook source.
+// If the line numbers cannot be detected, it returns nil.
+func stepLines(b []byte, n int) []int {
+	f, err := parser.ParseBytes(b, 0)
+	if err != nil || len(f.Docs) == 0 {
+		return nil
+	}
+	var values []*ast.MappingValueNode
+	switch v := f.Docs[0].Body.(type) {
+	case *ast.MappingNode:
+		values = v.Values
+	case *ast.MappingValueNode:
+		values = []*ast.MappingValueNode{v}
+	}
+	var lines []int
+	for _, mv := range values {
+		if mv.Key.GetToken().Value != "steps" {
+			continue
+		}
+		switch v := mv.Value.(type) {
+		case *ast.SequenceNode:
+			for _, s := range v.Values {
+				// use the line of `-` of the entry
+				tk := s.GetToken()
+				for tk.Prev != nil && tk.Type != token.SequenceEntryType {
+					tk = tk.Prev
+				}
+				lines = append(lines, tk.Position.Line)
+			}
+		case *ast.MappingNode:
+			for _, s := range v.Values {
+				lines = append(lines, s.Key.GetToken().Position.Line)
+			}
+		case *ast.MappingValueNode:
+			lines = append(lines, v.Key.GetToken().Position.Line)
+		}
+	}
+	if len(lines) != n {
+		return nil
+	}
+	return lines
+}
+
+// marshalRunbookMap marshals the map of runbook into YAML.
+// gopkg.in/yaml.v2 is used to keep the order of mapped steps ( yaml.MapSlice ).
+func marshalRunbookMap(m map[string]interface{}) ([]byte, error) {
+	return yaml.Marshal(m)
+}
+
 func parseRunbookMapped(b []byte, rb *runbook) error {
 	m := &runbookMapped{}
 	if err := yaml.Unmarshal(b, m); err != nil {
@@ -94,11 +178,25 @@ func parseRunbookMapped(b []byte, rb *runbook) error {
 	rb.Desc = m.Desc
 	rb.Runners = m.Runners
 	rb.Vars = m.Vars
+	rb.Secrets = m.Secrets
 	rb.Debug = m.Debug
 	rb.Interval = m.Interval
 	rb.If = m.If
 	rb.SkipTest = m.SkipTest
 	rb.Force = m.Force
+	rb.Labels = m.Labels
+	rb.Matrix = m.Matrix
+	rb.Export = m.Export
+	rb.ExpectRequests = m.ExpectRequests
+	rb.ExpectNoRequests = m.ExpectNoRequests
+	rb.MaxQueries = m.MaxQueries
+	rb.MaxResponseBytesTotal = m.MaxResponseBytesTotal
+	rb.Repeat = m.Repeat
+	rb.RepeatTest = m.RepeatTest
+	rb.Teardown = m.Teardown
+	rb.Templates = m.Templates
+	rb.Functions = m.Functions
+	rb.StepsFiles = m.StepsFiles
 
 	keys := map[string]struct{}{}
 	for _, s := range m.Steps {
@@ -155,11 +253,25 @@ func (rb *runbook) MarshalYAML() (interface{}, error) {
 	m.Desc = rb.Desc
 	m.Runners = rb.Runners
 	m.Vars = rb.Vars
+	m.Secrets = rb.Secrets
 	m.Debug = rb.Debug
 	m.Interval = rb.Interval
 	m.If = rb.If
 	m.SkipTest = rb.SkipTest
 	m.Force = rb.Force
+	m.Labels = rb.Labels
+	m.Matrix = rb.Matrix
+	m.Export = rb.Export
+	m.ExpectRequests = rb.ExpectRequests
+	m.ExpectNoRequests = rb.ExpectNoRequests
+	m.MaxQueries = rb.MaxQueries
+	m.MaxResponseBytesTotal = rb.MaxResponseBytesTotal
+	m.Repeat = rb.Repeat
+	m.RepeatTest = rb.RepeatTest
+	m.Teardown = rb.Teardown
+	m.Templates = rb.Templates
+	m.Functions = rb.Functions
+	m.StepsFiles = rb.StepsFiles
 	ms := yaml.MapSlice{}
 	for i, k := range rb.stepKeys {
 		ms = append(ms, yaml.MapItem{
@@ -336,27 +448,107 @@ func (rb *runbook) toBook() (*book, error) {
 	if !ok {
 		return nil, fmt.Errorf("failed to normalize vars: %v", rb.Vars)
 	}
-	for _, s := range rb.Steps {
+	bk.secrets, ok = normalize(rb.Secrets).(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("failed to normalize secrets: %v", rb.Secrets)
+	}
+	for i, s := range rb.Steps {
 		v, ok := normalize(s).(map[string]interface{})
 		if !ok {
 			return nil, fmt.Errorf("failed to normalize step values: %v", s)
 		}
 		bk.rawSteps = append(bk.rawSteps, v)
+		src := &stepSource{}
+		if i < len(rb.stepLines) {
+			src.line = rb.stepLines[i]
+		}
+		bk.stepSources = append(bk.stepSources, src)
 	}
 	bk.debug = rb.Debug
 	bk.intervalStr = rb.Interval
 	bk.ifCond = rb.If
 	bk.skipTest = rb.SkipTest
 	bk.force = rb.Force
+	bk.labels = rb.Labels
+	bk.matrix, err = parseMatrix(rb.Matrix)
+	if err != nil {
+		return nil, err
+	}
+	bk.exports = rb.Export
+	bk.expectRequests = rb.ExpectRequests
+	for _, p := range rb.ExpectNoRequests {
+		if _, err := path.Match(p, ""); err != nil {
+			return nil, fmt.Errorf("invalid expectNoRequests: %s: %w", p, err)
+		}
+	}
+	bk.expectNoRequests = rb.ExpectNoRequests
+	bk.maxQueries = rb.MaxQueries
+	if rb.MaxResponseBytesTotal != nil && *rb.MaxResponseBytesTotal < 0 {
+		return nil, fmt.Errorf("invalid maxResponseBytesTotal: %d", *rb.MaxResponseBytesTotal)
+	}
+	bk.maxResponseBytesTotal = rb.MaxResponseBytesTotal
+	if rb.Repeat < 0 {
+		return nil, fmt.Errorf("invalid repeat: %d", rb.Repeat)
+	}
+	if rb.RepeatTest != "" && rb.Repeat < 2 {
+		return nil, errors.New("repeatTest requires repeat: 2 or more")
+	}
+	if rb.Repeat > 1 && rb.Loop != nil {
+		return nil, errors.New("repeat and loop cannot be used together")
+	}
+	bk.repeat = rb.Repeat
+	bk.repeatTest = rb.RepeatTest
+	for k, steps := range rb.Teardown {
+		if !contains(teardownSectionKeys, k) {
+			return nil, fmt.Errorf("invalid teardown: unknown section '%s'", k)
+		}
+		if bk.teardownSteps == nil {
+			bk.teardownSteps = map[string][]map[string]interface{}{}
+		}
+		for _, s := range steps {
+			v, ok := normalize(s).(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("failed to normalize teardown step values: %v", s)
+			}
+			bk.teardownSteps[k] = append(bk.teardownSteps[k], v)
+		}
+	}
+	for k, t := range rb.Templates {
+		v, ok := normalize(t).(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid template '%s': %v", k, t)
+		}
+		if _, ok := v[useSectionKey]; ok {
+			return nil, fmt.Errorf("invalid template '%s': template cannot use another template", k)
+		}
+		if bk.templates == nil {
+			bk.templates = map[string]map[string]interface{}{}
+		}
+		bk.templates[k] = v
+	}
+	if len(rb.Functions) > 0 {
+		fns, ok := normalize(rb.Functions).(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid functions: %v", rb.Functions)
+		}
+		bk.functions, err = parseFunctions(fns)
+		if err != nil {
+			return nil, err
+		}
+	}
 	if rb.Loop != nil {
 		bk.loop, err = newLoop(rb.Loop)
 		if err != nil {
 			return nil, err
 		}
+		if bk.loop.hasRetryClassification() {
+			return nil, errors.New("retryableStatus and retryOnError are available only in the loop of steps")
+		}
 	}
 	bk.concurrency = rb.Concurrency
 	bk.useMap = rb.useMap
 	bk.stepKeys = rb.stepKeys
+	bk.stepsFiles = rb.StepsFiles
 
 	return bk, nil
 }
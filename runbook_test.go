@@ -9,6 +9,7 @@ import (
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/tenntenn/golden"
 	"gopkg.in/yaml.v2"
 )
@@ -48,13 +49,67 @@ func TestParseRunbook(t *testing.T) {
 			if err != nil {
 				t.Error(err)
 			}
-			if diff := cmp.Diff(rb, rb2, cmp.AllowUnexported(runbook{})); diff != "" {
+			// line numbers of steps change by re-marshaling
+			if diff := cmp.Diff(rb, rb2, cmp.AllowUnexported(runbook{}), cmpopts.IgnoreFields(runbook{}, "stepLines")); diff != "" {
 				t.Errorf("%s", diff)
 			}
 		})
 	}
 }
 
+func TestStepLines(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []int
+	}{
+		{
+			`desc: list
+steps:
+  -
+    test: 'true'
+  - test: 'true'
+    desc: second
+  - dump: vars
+`,
+			[]int{3, 5, 7},
+		},
+		{
+			`desc: map
+steps:
+  a:
+    test: 'true'
+
+  b:
+    dump: vars
+`,
+			[]int{3, 6},
+		},
+		{
+			`steps:
+  only:
+    test: 'true'
+`,
+			[]int{2},
+		},
+		{
+			`desc: no steps
+vars:
+  key: value
+`,
+			nil,
+		},
+	}
+	for _, tt := range tests {
+		rb, err := parseRunbook([]byte(tt.in))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(rb.stepLines, tt.want); diff != "" {
+			t.Error(diff)
+		}
+	}
+}
+
 func TestAppendStep(t *testing.T) {
 	tests := []struct {
 		name string
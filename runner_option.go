@@ -8,15 +8,20 @@ import (
 )
 
 type httpRunnerConfig struct {
-	Endpoint             string `yaml:"endpoint"`
-	OpenApi3DocLocation  string `yaml:"openapi3,omitempty"`
-	SkipValidateRequest  bool   `yaml:"skipValidateRequest,omitempty"`
-	SkipValidateResponse bool   `yaml:"skipValidateResponse,omitempty"`
-	NotFollowRedirect    bool   `yaml:"notFollowRedirect,omitempty"`
-	MultipartBoundary    string `yaml:"multipartBoundary,omitempty"`
-	CACert               string `yaml:"cacert,omitempty"`
-	Cert                 string `yaml:"cert,omitempty"`
-	Key                  string `yaml:"key,omitempty"`
+	Endpoint             string            `yaml:"endpoint"`
+	OpenApi3DocLocation  string            `yaml:"openapi3,omitempty"`
+	SkipValidateRequest  bool              `yaml:"skipValidateRequest,omitempty"`
+	SkipValidateResponse bool              `yaml:"skipValidateResponse,omitempty"`
+	NotFollowRedirect    bool              `yaml:"notFollowRedirect,omitempty"`
+	MultipartBoundary    string            `yaml:"multipartBoundary,omitempty"`
+	DefaultContentType   string            `yaml:"defaultContentType,omitempty"`
+	BasePath             string            `yaml:"basePath,omitempty"`
+	Headers              map[string]string `yaml:"headers,omitempty"`
+	CACert               string            `yaml:"cacert,omitempty"`
+	Cert                 string            `yaml:"cert,omitempty"`
+	Key                  string            `yaml:"key,omitempty"`
+	Protos               []string          `yaml:"protos,omitempty"`
+	ImportPaths          []string          `yaml:"importPaths,omitempty"`
 
 	openApi3Doc *openapi3.T
 }
@@ -34,6 +39,19 @@ type grpcRunnerConfig struct {
 	key    []byte
 }
 
+type dbRunnerConfig struct {
+	DSN         string   `yaml:"dsn"`
+	Replicas    []string `yaml:"replicas,omitempty"`
+	TimeLayouts []string `yaml:"timeLayouts,omitempty"`
+	NumericAs   string   `yaml:"numericAs,omitempty"`
+}
+
+// dbRunnerGroupConfig - DB runner group to route the statements to the DB runners by the statement type.
+type dbRunnerGroupConfig struct {
+	Read  string `yaml:"read"`
+	Write string `yaml:"write"`
+}
+
 type sshRunnerConfig struct {
 	SSHConfig           string       `yaml:"sshConfig,omitempty"`
 	Host                string       `yaml:"host,omitempty"`
@@ -56,6 +74,8 @@ type httpRunnerOption func(*httpRunnerConfig) error
 
 type grpcRunnerOption func(*grpcRunnerConfig) error
 
+type dbRunnerOption func(*dbRunnerConfig) error
+
 type sshRunnerOption func(*sshRunnerConfig) error
 
 func (c *sshRunnerConfig) validate() error {
@@ -123,6 +143,33 @@ func MultipartBoundary(b string) httpRunnerOption {
 	}
 }
 
+// DefaultContentType sets the content type to encode the request body declared without the content type key.
+func DefaultContentType(ct string) httpRunnerOption {
+	return func(c *httpRunnerConfig) error {
+		if !isSupportedMediaType(ct) {
+			return fmt.Errorf("unsupported defaultContentType: %s", ct)
+		}
+		c.DefaultContentType = ct
+		return nil
+	}
+}
+
+// BasePath sets the base path prepended to the path of every request of the runner ( e.g. `/api/v2` ).
+func BasePath(p string) httpRunnerOption {
+	return func(c *httpRunnerConfig) error {
+		c.BasePath = p
+		return nil
+	}
+}
+
+// DefaultHeaders sets the headers set in every request of the runner ( overridden by the headers of the step ).
+func DefaultHeaders(h map[string]string) httpRunnerOption {
+	return func(c *httpRunnerConfig) error {
+		c.Headers = h
+		return nil
+	}
+}
+
 func HTTPCACert(path string) httpRunnerOption {
 	return func(c *httpRunnerConfig) error {
 		c.CACert = path
@@ -144,6 +191,41 @@ func HTTPKey(path string) httpRunnerOption {
 	}
 }
 
+// HTTPProtos sets the .proto files to encode and decode the bodies in Protocol Buffers.
+func HTTPProtos(protos ...string) httpRunnerOption {
+	return func(c *httpRunnerConfig) error {
+		c.Protos = protos
+		return nil
+	}
+}
+
+// HTTPImportPaths sets the import paths to resolve the .proto files set by HTTPProtos.
+func HTTPImportPaths(paths ...string) httpRunnerOption {
+	return func(c *httpRunnerConfig) error {
+		c.ImportPaths = paths
+		return nil
+	}
+}
+
+// TimeLayouts sets the Go time layouts tried in order to parse DATE/TIMESTAMP/DATETIME columns before falling back to dateparse.
+func TimeLayouts(layouts ...string) dbRunnerOption {
+	return func(c *dbRunnerConfig) error {
+		c.TimeLayouts = layouts
+		return nil
+	}
+}
+
+// NumericAs sets the representation of numeric columns regardless of the driver ( "float" or "string" ).
+func NumericAs(numericAs string) dbRunnerOption {
+	return func(c *dbRunnerConfig) error {
+		if err := validateDBNumericAs(numericAs); err != nil {
+			return err
+		}
+		c.NumericAs = numericAs
+		return nil
+	}
+}
+
 func TLS(useTLS bool) grpcRunnerOption {
 	return func(c *grpcRunnerConfig) error {
 		c.TLS = &useTLS
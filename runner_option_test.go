@@ -64,3 +64,31 @@ func TestMultipartBoundary(t *testing.T) {
 		t.Errorf("got %v\nwant %v", got, want)
 	}
 }
+
+func TestDefaultContentType(t *testing.T) {
+	tests := []struct {
+		in      string
+		wantErr bool
+	}{
+		{MediaTypeApplicationJSON, false},
+		{MediaTypeApplicationFormUrlencoded, false},
+		{"application/xml", true},
+	}
+	for _, tt := range tests {
+		c := &httpRunnerConfig{}
+		opt := DefaultContentType(tt.in)
+		if err := opt(c); err != nil {
+			if !tt.wantErr {
+				t.Error(err)
+			}
+			continue
+		}
+		if tt.wantErr {
+			t.Error("want error")
+		}
+		got := c.DefaultContentType
+		if got != tt.in {
+			t.Errorf("got %v\nwant %v", got, tt.in)
+		}
+	}
+}
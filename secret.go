@@ -0,0 +1,315 @@
+package runn
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/goccy/go-json"
+)
+
+// minNonStringSecretLength - minimum length of the non-string values of secrets ( e.g. numbers ) to be masked.
+// Shorter values ( e.g. the secret of number 1 ) are not masked so that they do not mask every occurrence in the output.
+const minNonStringSecretLength = 4
+
+// secretMasker - mask the values of secrets in the strings to be printed or captured.
+type secretMasker struct {
+	replacer *strings.Replacer
+}
+
+// newSecretMasker returns a new secretMasker for the values of secrets.
+// It returns nil if there are no values to mask.
+func newSecretMasker(secrets map[string]interface{}) *secretMasker {
+	values := secretValues(secrets)
+	if len(values) == 0 {
+		return nil
+	}
+	// mask longer values first so that a value containing another value is masked entirely
+	sort.SliceStable(values, func(i, j int) bool {
+		return len(values[i]) > len(values[j])
+	})
+	oldnew := []string{}
+	seen := map[string]struct{}{}
+	for _, v := range values {
+		// also mask the values escaped in JSON
+		b, _ := json.Marshal(v)
+		for _, vv := range []string{v, strings.Trim(string(b), `"`)} {
+			if _, ok := seen[vv]; ok {
+				continue
+			}
+			seen[vv] = struct{}{}
+			oldnew = append(oldnew, vv, maskedValue)
+		}
+	}
+	return &secretMasker{replacer: strings.NewReplacer(oldnew...)}
+}
+
+func secretValues(v interface{}) []string {
+	values := []string{}
+	switch vv := v.(type) {
+	case nil, bool:
+	case string:
+		if vv != "" {
+			values = append(values, vv)
+		}
+	case map[string]interface{}:
+		for _, vvv := range vv {
+			values = append(values, secretValues(vvv)...)
+		}
+	case []interface{}:
+		for _, vvv := range vv {
+			values = append(values, secretValues(vvv)...)
+		}
+	default:
+		if s := fmt.Sprintf("%v", vv); len(s) >= minNonStringSecretLength {
+			values = append(values, s)
+		}
+	}
+	return values
+}
+
+func (m *secretMasker) mask(s string) string {
+	if m == nil {
+		return s
+	}
+	return m.replacer.Replace(s)
+}
+
+// maskValue returns a copy of the value whose strings are masked.
+func (m *secretMasker) maskValue(v interface{}) interface{} {
+	if m == nil {
+		return v
+	}
+	switch vv := v.(type) {
+	case string:
+		return m.mask(vv)
+	case []byte:
+		return []byte(m.mask(string(vv)))
+	case map[string]interface{}:
+		masked := map[string]interface{}{}
+		for k, vvv := range vv {
+			masked[k] = m.maskValue(vvv)
+		}
+		return masked
+	case []interface{}:
+		masked := make([]interface{}, 0, len(vv))
+		for _, vvv := range vv {
+			masked = append(masked, m.maskValue(vvv))
+		}
+		return masked
+	case []map[string]interface{}:
+		masked := make([]interface{}, 0, len(vv))
+		for _, vvv := range vv {
+			masked = append(masked, m.maskValue(vvv))
+		}
+		return masked
+	case map[string][]string:
+		masked := map[string][]string{}
+		for k, vvv := range vv {
+			for _, s := range vvv {
+				masked[k] = append(masked[k], m.mask(s))
+			}
+		}
+		return masked
+	default:
+		return v
+	}
+}
+
+func (m *secretMasker) maskError(err error) error {
+	if m == nil || err == nil {
+		return err
+	}
+	var me *maskedError
+	if errors.As(err, &me) {
+		return err
+	}
+	return &maskedError{err: err, masker: m}
+}
+
+// maskedError - the error whose message is masked.
+type maskedError struct {
+	err    error
+	masker *secretMasker
+}
+
+func (e *maskedError) Error() string {
+	return e.masker.mask(e.err.Error())
+}
+
+func (e *maskedError) Unwrap() error {
+	u := errors.Unwrap(e.err)
+	if u == nil {
+		return nil
+	}
+	return &maskedError{err: u, masker: e.masker}
+}
+
+func (e *maskedError) Is(target error) bool {
+	return errors.Is(e.err, target)
+}
+
+func (e *maskedError) As(target interface{}) bool {
+	return errors.As(e.err, target)
+}
+
+// maskedCapturer - the capturer that captures the values with secrets masked.
+type maskedCapturer struct {
+	Capturer
+	masker *secretMasker
+}
+
+func (cs capturers) masked(m *secretMasker) capturers {
+	if m == nil {
+		return cs
+	}
+	masked := capturers{}
+	for _, c := range cs {
+		masked = append(masked, &maskedCapturer{Capturer: c, masker: m})
+	}
+	return masked
+}
+
+func (c *maskedCapturer) CaptureHTTPRequest(name string, req *http.Request) {
+	r := req.Clone(req.Context())
+	r.Header = c.masker.maskValue(map[string][]string(req.Header)).(map[string][]string)
+	if u, err := url.Parse(c.masker.mask(req.URL.String())); err == nil {
+		r.URL = u
+		r.RequestURI = ""
+	}
+	if req.Body != nil && req.Body != http.NoBody {
+		r.Body = &maskedBody{orig: &req.Body, contentLength: &r.ContentLength, masker: c.masker}
+	}
+	c.Capturer.CaptureHTTPRequest(name, r)
+}
+
+func (c *maskedCapturer) CaptureHTTPResponse(name string, res *http.Response) {
+	r := *res
+	r.Header = c.masker.maskValue(map[string][]string(res.Header)).(map[string][]string)
+	if res.Body != nil && res.Body != http.NoBody {
+		r.Body = &maskedBody{orig: &res.Body, contentLength: &r.ContentLength, masker: c.masker}
+	}
+	c.Capturer.CaptureHTTPResponse(name, &r)
+}
+
+// maskedBody - body of the request or response passed to the capturer, whose secrets are masked.
+// The original body is read only when the capturer reads the body, so that the body is not buffered for the capturers that do not consume it ( e.g. the request counter ).
+type maskedBody struct {
+	// Body of the original request or response to be restored after reading
+	orig *io.ReadCloser
+	// ContentLength of the request or response passed to the capturer to be updated to the length of the masked body
+	contentLength *int64
+	masker        *secretMasker
+	r             io.Reader
+}
+
+func (b *maskedBody) Read(p []byte) (int, error) {
+	if b.r == nil {
+		o, err := io.ReadAll(*b.orig)
+		if err != nil {
+			return 0, err
+		}
+		// restore the original body keeping the original Close ( e.g. to release the connection )
+		*b.orig = &restoredBody{Reader: bytes.NewReader(o), Closer: *b.orig}
+		mb := b.masker.mask(string(o))
+		*b.contentLength = int64(len(mb))
+		b.r = strings.NewReader(mb)
+	}
+	return b.r.Read(p)
+}
+
+func (b *maskedBody) Close() error {
+	return nil
+}
+
+// restoredBody - body restored after reading whose Close closes the original body.
+type restoredBody struct {
+	io.Reader
+	io.Closer
+}
+
+func (c *maskedCapturer) CaptureGRPCRequestHeaders(h map[string][]string) {
+	c.Capturer.CaptureGRPCRequestHeaders(c.masker.maskValue(h).(map[string][]string))
+}
+
+func (c *maskedCapturer) CaptureGRPCRequestMessage(m map[string]interface{}) {
+	c.Capturer.CaptureGRPCRequestMessage(c.masker.maskValue(m).(map[string]interface{}))
+}
+
+func (c *maskedCapturer) CaptureGRPCResponseHeaders(h map[string][]string) {
+	c.Capturer.CaptureGRPCResponseHeaders(c.masker.maskValue(h).(map[string][]string))
+}
+
+func (c *maskedCapturer) CaptureGRPCResponseMessage(m map[string]interface{}) {
+	c.Capturer.CaptureGRPCResponseMessage(c.masker.maskValue(m).(map[string]interface{}))
+}
+
+func (c *maskedCapturer) CaptureGRPCResponseTrailers(t map[string][]string) {
+	c.Capturer.CaptureGRPCResponseTrailers(c.masker.maskValue(t).(map[string][]string))
+}
+
+func (c *maskedCapturer) CaptureCDPAction(a CDPAction) {
+	c.Capturer.CaptureCDPAction(CDPAction{Fn: a.Fn, Args: c.masker.maskValue(a.Args).(map[string]interface{})})
+}
+
+func (c *maskedCapturer) CaptureCDPResponse(a CDPAction, res map[string]interface{}) {
+	c.Capturer.CaptureCDPResponse(CDPAction{Fn: a.Fn, Args: c.masker.maskValue(a.Args).(map[string]interface{})}, c.masker.maskValue(res).(map[string]interface{}))
+}
+
+func (c *maskedCapturer) CaptureSSHCommand(command string) {
+	c.Capturer.CaptureSSHCommand(c.masker.mask(command))
+}
+
+func (c *maskedCapturer) CaptureSSHStdout(stdout string) {
+	c.Capturer.CaptureSSHStdout(c.masker.mask(stdout))
+}
+
+func (c *maskedCapturer) CaptureSSHStderr(stderr string) {
+	c.Capturer.CaptureSSHStderr(c.masker.mask(stderr))
+}
+
+func (c *maskedCapturer) CaptureDBStatement(name string, stmt string) {
+	c.Capturer.CaptureDBStatement(name, c.masker.mask(stmt))
+}
+
+func (c *maskedCapturer) CaptureDBResponse(name string, res *DBResponse) {
+	rows := []map[string]interface{}{}
+	for _, r := range res.Rows {
+		rows = append(rows, c.masker.maskValue(r).(map[string]interface{}))
+	}
+	c.Capturer.CaptureDBResponse(name, &DBResponse{
+		LastInsertID: res.LastInsertID,
+		RowsAffected: res.RowsAffected,
+		Columns:      res.Columns,
+		Rows:         rows,
+	})
+}
+
+func (c *maskedCapturer) CaptureExecCommand(command string) {
+	c.Capturer.CaptureExecCommand(c.masker.mask(command))
+}
+
+func (c *maskedCapturer) CaptureExecEnv(env map[string]string) {
+	masked := make(map[string]string, len(env))
+	for k, v := range env {
+		masked[k] = c.masker.mask(v)
+	}
+	c.Capturer.CaptureExecEnv(masked)
+}
+
+func (c *maskedCapturer) CaptureExecStdin(stdin string) {
+	c.Capturer.CaptureExecStdin(c.masker.mask(stdin))
+}
+
+func (c *maskedCapturer) CaptureExecStdout(stdout string) {
+	c.Capturer.CaptureExecStdout(c.masker.mask(stdout))
+}
+
+func (c *maskedCapturer) CaptureExecStderr(stderr string) {
+	c.Capturer.CaptureExecStderr(c.masker.mask(stderr))
+}
@@ -0,0 +1,162 @@
+package runn
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/k1LoW/runn/testutil"
+)
+
+func TestSecretMasker(t *testing.T) {
+	tests := []struct {
+		secrets map[string]interface{}
+		in      string
+		want    string
+	}{
+		{map[string]interface{}{}, "token", "token"},
+		{map[string]interface{}{"token": "abc"}, "Bearer abc", "Bearer " + maskedValue},
+		{map[string]interface{}{"token": "abc", "long": "abcdef"}, "abcdef abc", maskedValue + " " + maskedValue},
+		{map[string]interface{}{"token": `a"b`}, `{"token":"a\"b"}`, `{"token":"` + maskedValue + `"}`},
+		{map[string]interface{}{"nested": map[string]interface{}{"pin": 1234}}, "pin is 1234", "pin is " + maskedValue},
+		{map[string]interface{}{"empty": "", "flag": true}, "true", "true"},
+		{map[string]interface{}{"num": 1}, "1 + 1", "1 + 1"},
+	}
+	for _, tt := range tests {
+		m := newSecretMasker(tt.secrets)
+		if got := m.mask(tt.in); got != tt.want {
+			t.Errorf("got %v want %v", got, tt.want)
+		}
+	}
+}
+
+type closeRecorder struct {
+	io.Reader
+	closed bool
+}
+
+func (r *closeRecorder) Close() error {
+	r.closed = true
+	return nil
+}
+
+func TestMaskedCapturerHTTPResponseBody(t *testing.T) {
+	m := newSecretMasker(map[string]interface{}{"token": "abc"})
+	const body = "token is abc"
+
+	t.Run("not consumed", func(t *testing.T) {
+		orig := &closeRecorder{Reader: strings.NewReader(body)}
+		res := &http.Response{Header: http.Header{}, Body: orig, ContentLength: int64(len(body))}
+		capturers{newRequestCounter()}.masked(m).captureHTTPResponse("req", res)
+		if res.Body != orig {
+			t.Error("the body should not be read by the capturer that does not consume it")
+		}
+	})
+
+	t.Run("consumed", func(t *testing.T) {
+		orig := &closeRecorder{Reader: strings.NewReader(body)}
+		res := &http.Response{ProtoMajor: 1, ProtoMinor: 1, StatusCode: http.StatusOK, Header: http.Header{}, Body: orig, ContentLength: int64(len(body))}
+		out := new(bytes.Buffer)
+		capturers{NewDebugger(out)}.masked(m).captureHTTPResponse("req", res)
+		if !strings.Contains(out.String(), "token is "+maskedValue) || strings.Contains(out.String(), "abc") {
+			t.Errorf("the body should be masked: %s", out.String())
+		}
+		b, err := io.ReadAll(res.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != body {
+			t.Errorf("got %q want %q", string(b), body)
+		}
+		if err := res.Body.Close(); err != nil {
+			t.Fatal(err)
+		}
+		if !orig.closed {
+			t.Error("the original body should be closed")
+		}
+	})
+}
+
+func TestMaskedError(t *testing.T) {
+	m := newSecretMasker(map[string]interface{}{"token": "abc"})
+	base := errors.New("invalid token abc")
+	err := m.maskError(newAfterFuncError(base))
+	if strings.Contains(err.Error(), "abc") {
+		t.Errorf("got %v", err)
+	}
+	if !errors.Is(err, base) {
+		t.Error("masked error should keep the wrapped errors")
+	}
+	var target *AfterFuncError
+	if !errors.As(err, &target) {
+		t.Error("masked error should keep the type of the error")
+	}
+	if u := errors.Unwrap(err); u == nil || strings.Contains(u.Error(), "abc") {
+		t.Errorf("got %v", u)
+	}
+}
+
+func TestSecrets(t *testing.T) {
+	const secret = "s3cr3t-t0ken"
+	ts := testutil.HTTPServer(t)
+	stdout := new(bytes.Buffer)
+	stderr := new(bytes.Buffer)
+	o, err := New(Book("testdata/secrets.yml"), HTTPRunner("req", ts.URL, ts.Client()), Secret("apiKey", secret), Debug(true), Stdout(stdout), Stderr(stderr))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = o.Run(context.Background())
+	if err == nil {
+		t.Fatal("want error")
+	}
+	if strings.Contains(err.Error(), secret) {
+		t.Errorf("the error should be masked: %v", err)
+	}
+	r := o.Result()
+	if r.StepResults[0].Err != nil {
+		t.Errorf("the secret should be expanded: %v", r.StepResults[0].Err)
+	}
+	if got := r.StepResults[0].Summary; strings.Contains(got, secret) {
+		t.Errorf("the summary should be masked: %v", got)
+	}
+	if got := r.StepResults[2].Err; got == nil || strings.Contains(got.Error(), secret) || !strings.Contains(got.Error(), maskedValue) {
+		t.Errorf("the error of the step should be masked: %v", got)
+	}
+	for name, out := range map[string]string{"stdout": stdout.String(), "stderr": stderr.String()} {
+		if strings.Contains(out, secret) {
+			t.Errorf("%s should be masked: %s", name, out)
+		}
+		if !strings.Contains(out, maskedValue) {
+			t.Errorf("%s should contain the masked value: %s", name, out)
+		}
+	}
+
+	rr := newRunNResult(t, 1, []*RunResult{r})
+	rr.includeStore = true
+	got := new(bytes.Buffer)
+	if err := rr.OutJSON(got); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(got.String(), secret) {
+		t.Errorf("the JSON output should be masked: %s", got.String())
+	}
+}
+
+func TestSecretsFromEnv(t *testing.T) {
+	t.Setenv("RUNN_TEST_SECRET_API_KEY", "abc")
+	t.Setenv("RUNN_TEST_OTHER", "def")
+	o, err := New(SecretsFromEnv("RUNN_TEST_SECRET_"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := o.store.secrets["API_KEY"]; got != "abc" {
+		t.Errorf("got %v want %v", got, "abc")
+	}
+	if len(o.store.secrets) != 1 {
+		t.Errorf("got %v", o.store.secrets)
+	}
+}
@@ -0,0 +1,159 @@
+package runn
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// AssertResponseShape - Assert that the response of the step recorded in the last run strictly conforms to the Go type of v ( json tags ).
+// The response body of HTTP Runner ( or the message of gRPC Runner ) is unmarshaled into v with DisallowUnknownFields,
+// so it fails if the response has unknown fields. The fields of structs without `omitempty` of the json tags are required ( the names are matched case-insensitively as encoding/json does ).
+func (o *operator) AssertResponseShape(stepKey string, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return fmt.Errorf("invalid value to assert the response shape: %T ( should be a non-nil pointer )", v)
+	}
+	res, err := o.recordedResponse(stepKey)
+	if err != nil {
+		return err
+	}
+	b, err := json.Marshal(res)
+	if err != nil {
+		return err
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return fmt.Errorf("the response of steps.%s does not conform to %T: %w", stepKey, v, err)
+	}
+	var data interface{}
+	if err := json.Unmarshal(b, &data); err != nil {
+		return err
+	}
+	if missing := missingFields(rv.Type(), data, ""); len(missing) > 0 {
+		return fmt.Errorf("the response of steps.%s does not conform to %T: missing required fields: %s", stepKey, v, strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// recordedResponse returns the response body ( or message ) of the step recorded in the last run.
+func (o *operator) recordedResponse(stepKey string) (interface{}, error) {
+	res, err := o.recordedRes(stepKey)
+	if err != nil {
+		return nil, err
+	}
+	if b, ok := res[httpStoreBodyKey]; ok {
+		return b, nil
+	}
+	if m, ok := res[grpcStoreMessageKey]; ok {
+		return m, nil
+	}
+	return nil, fmt.Errorf("the response of steps.%s has neither body nor message", stepKey)
+}
+
+// recordedRes returns `res` of the step recorded in the last run.
+func (o *operator) recordedRes(stepKey string) (map[string]interface{}, error) {
+	var sv map[string]interface{}
+	if o.useMap {
+		sv = o.store.stepMap[stepKey]
+	} else {
+		i, err := strconv.Atoi(stepKey)
+		if err != nil {
+			return nil, fmt.Errorf("invalid step key: %s", stepKey)
+		}
+		if i >= 0 && i < len(o.store.steps) {
+			sv = o.store.steps[i]
+		}
+	}
+	if sv == nil {
+		return nil, fmt.Errorf("the result of steps.%s is not recorded", stepKey)
+	}
+	res, ok := sv[httpStoreResponseKey].(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("the response of steps.%s is not recorded", stepKey)
+	}
+	return res, nil
+}
+
+// missingFields returns the paths of the required fields of the type t that are missing in the data.
+func missingFields(t reflect.Type, data interface{}, path string) []string {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	var missing []string
+	switch t.Kind() {
+	case reflect.Struct:
+		m, ok := data.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			tag := f.Tag.Get("json")
+			if tag == "-" {
+				// `json:"-,"` is the field named "-"
+				continue
+			}
+			name, opts, _ := strings.Cut(tag, ",")
+			if f.Anonymous && name == "" {
+				ft := f.Type
+				for ft.Kind() == reflect.Pointer {
+					ft = ft.Elem()
+				}
+				if ft.Kind() == reflect.Struct {
+					// the fields of the embedded struct are promoted even if the struct type is unexported
+					missing = append(missing, missingFields(ft, data, path)...)
+					continue
+				}
+			}
+			if !f.IsExported() {
+				continue
+			}
+			if name == "" {
+				name = f.Name
+			}
+			fv, ok := lookupField(m, name)
+			if !ok {
+				if !contains(strings.Split(opts, ","), "omitempty") {
+					missing = append(missing, fmt.Sprintf("%s.%s", path, name))
+				}
+				continue
+			}
+			missing = append(missing, missingFields(f.Type, fv, fmt.Sprintf("%s.%s", path, name))...)
+		}
+	case reflect.Slice, reflect.Array:
+		s, ok := data.([]interface{})
+		if !ok {
+			return nil
+		}
+		for i, e := range s {
+			missing = append(missing, missingFields(t.Elem(), e, fmt.Sprintf("%s[%d]", path, i))...)
+		}
+	case reflect.Map:
+		m, ok := data.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		for _, k := range sortedKeys(m) {
+			missing = append(missing, missingFields(t.Elem(), m[k], fmt.Sprintf("%s.%s", path, k))...)
+		}
+	}
+	return missing
+}
+
+// lookupField returns the value of the key matching the field name, preferring an exact match but also accepting a case-insensitive match as encoding/json does.
+func lookupField(m map[string]interface{}, name string) (interface{}, bool) {
+	if v, ok := m[name]; ok {
+		return v, true
+	}
+	for _, k := range sortedKeys(m) {
+		if strings.EqualFold(k, name) {
+			return m[k], true
+		}
+	}
+	return nil, false
+}
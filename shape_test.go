@@ -0,0 +1,120 @@
+package runn
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/k1LoW/runn/testutil"
+)
+
+func TestAssertResponseShape(t *testing.T) {
+	type user struct {
+		Username string `json:"username"`
+	}
+	type embedded struct {
+		user
+	}
+	tests := []struct {
+		stepKey string
+		v       interface{}
+		wantErr string
+	}{
+		{"user", &struct {
+			Data user `json:"data"`
+		}{}, ""},
+		{"user", &struct {
+			Data embedded `json:"data"`
+		}{}, ""},
+		{"user", &struct {
+			Data struct {
+				Username string `json:"username"`
+				Email    string `json:"email,omitempty"`
+			} `json:"data"`
+		}{}, ""},
+		{"user", &struct {
+			Data struct {
+				Username string `json:"username"`
+				Email    string `json:"email"`
+			} `json:"data"`
+		}{}, "missing required fields: .data.email"},
+		{"user", &struct {
+			Data struct{} `json:"data"`
+		}{}, `unknown field "username"`},
+		{"users", &[]user{}, ""},
+		{"users", &[]struct {
+			ID       int    `json:"id"`
+			Username string `json:"username"`
+		}{}, "missing required fields: [0].id, [1].id"},
+		{"users", &map[string]interface{}{}, "cannot unmarshal array"},
+		{"unknown", &user{}, "the result of steps.unknown is not recorded"},
+		{"user", user{}, "should be a non-nil pointer"},
+	}
+	ts := testutil.HTTPServer(t)
+	t.Setenv("TEST_HTTP_END_POINT", ts.URL)
+	o, err := New(Book("testdata/book/response_shape.yml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := o.Run(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range tests {
+		t.Run(tt.wantErr, func(t *testing.T) {
+			err := o.AssertResponseShape(tt.stepKey, tt.v)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Error(err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("got %v\nwant %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMissingFields(t *testing.T) {
+	type user struct {
+		Username string
+	}
+	tests := []struct {
+		v    interface{}
+		data interface{}
+		want []string
+	}{
+		{struct{ Username string }{}, map[string]interface{}{"username": "alice"}, nil},
+		{struct {
+			Username string `json:"userName"`
+		}{}, map[string]interface{}{"username": "alice"}, nil},
+		{struct{ Username string }{}, map[string]interface{}{}, []string{".Username"}},
+		{struct {
+			Username string
+			Password string `json:"-"`
+		}{}, map[string]interface{}{"username": "alice"}, nil},
+		{struct {
+			Username string
+			Dash     string `json:"-,"`
+		}{}, map[string]interface{}{"username": "alice"}, []string{".-"}},
+		{struct {
+			Username string
+			Email    string `json:",omitempty"`
+		}{}, map[string]interface{}{"username": "alice"}, nil},
+		{struct {
+			user
+			ID int `json:"id"`
+		}{}, map[string]interface{}{"id": 1}, []string{".Username"}},
+		{struct {
+			*user
+		}{}, map[string]interface{}{"USERNAME": "alice"}, nil},
+	}
+	for _, tt := range tests {
+		got := missingFields(reflect.TypeOf(tt.v), tt.data, "")
+		if diff := cmp.Diff(got, tt.want); diff != "" {
+			t.Errorf("%T: %s", tt.v, diff)
+		}
+	}
+}
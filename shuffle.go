@@ -0,0 +1,85 @@
+package runn
+
+import (
+	"math/rand"
+	"regexp"
+)
+
+const orderedSectionKey = "ordered"
+
+var priorStepsRefRe = regexp.MustCompile(`(^|[^./\w])(steps|previous)([^/\w]|$)`)
+
+// stepOrder - return the indexes of steps in the order of running.
+// When ShuffleSteps is enabled, each run of consecutive independent steps is shuffled.
+// Steps that are not independent stay in place, so steps referencing prior steps always run after them.
+func (o *operator) stepOrder() []int {
+	order := make([]int, 0, len(o.steps))
+	if o.shuffleStepsSeed == nil {
+		for i, s := range o.steps {
+			if s.teardown != "" {
+				continue
+			}
+			order = append(order, i)
+		}
+		return order
+	}
+	r := rand.New(rand.NewSource(*o.shuffleStepsSeed)) //nolint:gosec
+	independents := []int{}
+	flush := func() {
+		r.Shuffle(len(independents), func(i, j int) {
+			independents[i], independents[j] = independents[j], independents[i]
+		})
+		order = append(order, independents...)
+		independents = []int{}
+	}
+	for i, s := range o.steps {
+		if s.teardown != "" {
+			// steps of `teardown:` run after all steps
+			continue
+		}
+		if s.independent() {
+			independents = append(independents, i)
+			continue
+		}
+		flush()
+		order = append(order, i)
+	}
+	flush()
+	return order
+}
+
+// independent - whether the step can be run in any order.
+func (s *step) independent() bool {
+	if s.ordered || s.dependent {
+		return false
+	}
+	// steps without runners ( test/dump/bind only ) work on the results of prior steps
+	return s.runnerKey != "" && s.bindRunner == nil
+}
+
+// referencesPriorSteps - whether the step references the results of prior steps ( `steps` or `previous` ).
+func referencesPriorSteps(v interface{}) bool {
+	switch vv := v.(type) {
+	case string:
+		return priorStepsRefRe.MatchString(vv)
+	case map[string]interface{}:
+		for k, vvv := range vv {
+			if referencesPriorSteps(k) || referencesPriorSteps(vvv) {
+				return true
+			}
+		}
+	case map[interface{}]interface{}:
+		for k, vvv := range vv {
+			if referencesPriorSteps(k) || referencesPriorSteps(vvv) {
+				return true
+			}
+		}
+	case []interface{}:
+		for _, vvv := range vv {
+			if referencesPriorSteps(vvv) {
+				return true
+			}
+		}
+	}
+	return false
+}
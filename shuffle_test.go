@@ -0,0 +1,137 @@
+package runn
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/goccy/go-json"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestShuffleSteps(t *testing.T) {
+	tests := []struct {
+		book string
+	}{
+		{"testdata/book/shuffle_steps.yml"},
+		{"testdata/book/shuffle_steps_map.yml"},
+	}
+	ctx := context.Background()
+	for _, tt := range tests {
+		shuffled := false
+		for seed := int64(0); seed < 10; seed++ {
+			t.Run(fmt.Sprintf("%s seed %d", tt.book, seed), func(t *testing.T) {
+				o, err := New(Book(tt.book), ShuffleSteps(seed), Stdout(io.Discard))
+				if err != nil {
+					t.Fatal(err)
+				}
+				order := o.stepOrder()
+				if diff := cmp.Diff(order, o.stepOrder(), nil); diff != "" {
+					t.Errorf("order should be reproducible with the same seed: %s", diff)
+				}
+				head := append([]int{}, order[:3]...)
+				sort.Ints(head)
+				if diff := cmp.Diff([]int{0, 1, 2}, head, nil); diff != "" {
+					t.Error(diff)
+				}
+				tail := append([]int{}, order[5:7]...)
+				sort.Ints(tail)
+				if diff := cmp.Diff([]int{5, 6}, tail, nil); diff != "" {
+					t.Error(diff)
+				}
+				for _, i := range []int{3, 4, 7} {
+					if order[i] != i {
+						t.Errorf("step %d should not be moved: %v", i, order)
+					}
+				}
+				if order[0] != 0 || order[5] != 5 {
+					shuffled = true
+				}
+				if err := o.Run(ctx); err != nil {
+					t.Fatal(err)
+				}
+				got := o.Result().ShuffleStepsSeed
+				if got == nil || *got != seed {
+					t.Errorf("got %v want %v", got, seed)
+				}
+			})
+		}
+		if !shuffled {
+			t.Errorf("steps of %s are never shuffled", tt.book)
+		}
+	}
+}
+
+func TestReferencesPriorSteps(t *testing.T) {
+	tests := []struct {
+		in   interface{}
+		want bool
+	}{
+		{"echo hello", false},
+		{"{{ steps[0].stdout }}", true},
+		{"steps.login.res.status == 200", true},
+		{"previous.res.status == 200", true},
+		{"current.res.status == 200", false},
+		{"vars.steps", false},
+		{"/steps/1", false},
+		{map[string]interface{}{"req": map[string]interface{}{"/users": map[string]interface{}{"get": map[string]interface{}{"body": nil}}}}, false},
+		{map[string]interface{}{"exec": map[string]interface{}{"command": "cat", "stdin": "{{ steps[0].stdout }}"}}, true},
+		{map[string]interface{}{"test": "previous.stdout contains 'hello'"}, true},
+		{[]interface{}{"a", "steps[1]"}, true},
+	}
+	for _, tt := range tests {
+		got := referencesPriorSteps(tt.in)
+		if got != tt.want {
+			t.Errorf("%v: got %v want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestShuffleStepsInterval(t *testing.T) {
+	const interval = 50 * time.Millisecond
+	for seed := int64(0); seed < 10; seed++ {
+		p := filepath.Join(t.TempDir(), "trace.jsonl")
+		o, err := New(Book("testdata/book/shuffle_steps.yml"), ShuffleSteps(seed), Interval(interval), TraceFile(p), Stdout(io.Discard))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if o.stepOrder()[0] == 0 {
+			continue
+		}
+		if err := o.Run(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		b, err := os.ReadFile(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var starts []float64
+		for _, l := range strings.Split(strings.TrimSpace(string(b)), "\n") {
+			e := TraceEvent{}
+			if err := json.Unmarshal([]byte(l), &e); err != nil {
+				t.Fatal(err)
+			}
+			if e.Type == traceEventStepStart {
+				starts = append(starts, e.Offset)
+			}
+		}
+		// the step that runs first does not wait for the interval, and the others do
+		for i := 1; i < len(starts); i++ {
+			gap := time.Duration((starts[i] - starts[i-1]) * float64(time.Second))
+			if i == 1 && gap >= interval {
+				t.Errorf("the first step should not wait for the interval: %v", gap)
+			}
+			if i > 1 && gap < interval {
+				t.Errorf("the step %d should wait for the interval: %v", i-1, gap)
+			}
+		}
+		return
+	}
+	t.Fatal("steps are never shuffled to start from the other step")
+}
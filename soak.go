@@ -0,0 +1,192 @@
+package runn
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"text/template"
+	"time"
+
+	"github.com/dustin/go-humanize"
+)
+
+const soakReportTemplate = `
+Target QPS....................: {{ .QPS }}
+Duration......................: {{ .Duration }}
+Elapsed.......................: {{ .Elapsed }}
+
+Total.........................: {{ .Total }}
+Succeeded.....................: {{ .Succeeded }}
+Failed........................: {{ .Failed }}
+Error rate....................: {{ .ErrorRate }}%
+RunN per seconds..............: {{ .RPS }}
+Latency ......................: max={{ .MaxLatency }}ms min={{ .MinLatency }}ms avg={{ .AvgLatency }}ms med={{ .MedLatency }}ms p(90)={{ .Latency90p }}ms p(99)={{ .Latency99p }}ms
+`
+
+// SoakResult is the aggregated result of running a runbook repeatedly with Soak.
+type SoakResult struct {
+	QPS       float64
+	Duration  time.Duration
+	Elapsed   time.Duration
+	Succeeded int64
+	Failed    int64
+	Latencies []time.Duration
+	// Errors are the errors of the failed runs ( in the order of running ).
+	Errors []error
+}
+
+// Soak - Run the runbook repeatedly at the rate of qps until the duration has elapsed, and return the aggregated result.
+// Runs are not overlapped; when a run takes longer than the interval, the next run starts immediately.
+// When ShuffleSteps is enabled, the seed is advanced on each run so that every run has a different ( but reproducible ) order of steps.
+// Cancelling ctx stops soak early; the run interrupted by the cancellation is not counted.
+func (o *operator) Soak(ctx context.Context, qps float64, d time.Duration) (*SoakResult, error) {
+	if qps <= 0 || math.IsNaN(qps) || math.IsInf(qps, 0) {
+		return nil, fmt.Errorf("invalid qps: %v", qps)
+	}
+	if d <= 0 {
+		return nil, fmt.Errorf("invalid duration: %v", d)
+	}
+	if o.shuffleStepsSeed != nil {
+		seed := *o.shuffleStepsSeed
+		defer func() {
+			o.shuffleStepsSeed = &seed
+		}()
+	}
+	r := &SoakResult{
+		QPS:      qps,
+		Duration: d,
+	}
+	interval := time.Duration(float64(time.Second) / qps)
+	start := time.Now()
+	end := start.Add(d)
+	defer func() {
+		r.Elapsed = time.Since(start)
+	}()
+	for i := 0; ; i++ {
+		next := start.Add(time.Duration(i) * interval)
+		if !next.Before(end) {
+			return r, nil
+		}
+		if wait := time.Until(next); wait > 0 {
+			t := time.NewTimer(wait)
+			select {
+			case <-ctx.Done():
+				t.Stop()
+				return r, nil
+			case <-t.C:
+			}
+		}
+		if ctx.Err() != nil {
+			return r, nil
+		}
+		if o.shuffleStepsSeed != nil && i > 0 {
+			seed := *o.shuffleStepsSeed + 1
+			o.shuffleStepsSeed = &seed
+		}
+		s := time.Now()
+		err := o.Run(ctx)
+		l := time.Since(s)
+		if ctx.Err() != nil {
+			return r, nil
+		}
+		r.Latencies = append(r.Latencies, l)
+		if err != nil {
+			r.Failed++
+			r.Errors = append(r.Errors, err)
+			continue
+		}
+		r.Succeeded++
+	}
+}
+
+// Total returns the number of runs.
+func (r *SoakResult) Total() int64 {
+	return r.Succeeded + r.Failed
+}
+
+// ErrorRate returns the percentage of failed runs.
+func (r *SoakResult) ErrorRate() float64 {
+	if r.Total() == 0 {
+		return 0
+	}
+	return float64(r.Failed) / float64(r.Total()) * 100
+}
+
+// RPS returns the number of runs per second.
+func (r *SoakResult) RPS() float64 {
+	if r.Elapsed <= 0 {
+		return 0
+	}
+	return float64(r.Total()) / r.Elapsed.Seconds()
+}
+
+// PercentileLatency returns the p-th percentile ( 0-100 ) of the latencies.
+func (r *SoakResult) PercentileLatency(p float64) (time.Duration, error) {
+	if len(r.Latencies) == 0 {
+		return 0, errors.New("no latencies")
+	}
+	if p < 0 || p > 100 {
+		return 0, fmt.Errorf("invalid percentile: %v", p)
+	}
+	ll := make([]time.Duration, len(r.Latencies))
+	copy(ll, r.Latencies)
+	sort.Slice(ll, func(i, j int) bool { return ll[i] < ll[j] })
+	idx := int(math.Ceil(p/100*float64(len(ll)))) - 1
+	if idx < 0 {
+		idx = 0
+	}
+	return ll[idx], nil
+}
+
+// AvgLatency returns the average of the latencies.
+func (r *SoakResult) AvgLatency() time.Duration {
+	if len(r.Latencies) == 0 {
+		return 0
+	}
+	var sum time.Duration
+	for _, l := range r.Latencies {
+		sum += l
+	}
+	return sum / time.Duration(len(r.Latencies))
+}
+
+func (r *SoakResult) Report(w io.Writer) error {
+	tmpl, err := template.New("report").Parse(soakReportTemplate)
+	if err != nil {
+		return err
+	}
+	ms := func(l time.Duration) string {
+		return humanize.CommafWithDigits(float64(l)/float64(time.Millisecond), 1)
+	}
+	data := map[string]interface{}{
+		"QPS":        humanize.CommafWithDigits(r.QPS, 1),
+		"Duration":   r.Duration.String(),
+		"Elapsed":    r.Elapsed.Round(time.Millisecond).String(),
+		"Total":      r.Total(),
+		"Succeeded":  r.Succeeded,
+		"Failed":     r.Failed,
+		"ErrorRate":  humanize.CommafWithDigits(r.ErrorRate(), 1),
+		"RPS":        humanize.CommafWithDigits(r.RPS(), 1),
+		"AvgLatency": ms(r.AvgLatency()),
+	}
+	for k, p := range map[string]float64{
+		"MaxLatency": 100,
+		"MinLatency": 0,
+		"MedLatency": 50,
+		"Latency90p": 90,
+		"Latency99p": 99,
+	} {
+		l, err := r.PercentileLatency(p)
+		if err != nil {
+			l = 0
+		}
+		data[k] = ms(l)
+	}
+	if err := tmpl.Execute(w, data); err != nil {
+		return err
+	}
+	return nil
+}
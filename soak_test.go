@@ -0,0 +1,110 @@
+package runn
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSoak(t *testing.T) {
+	tests := []struct {
+		book          string
+		qps           float64
+		d             time.Duration
+		wantMinTotal  int64
+		wantMaxTotal  int64
+		wantAllFailed bool
+	}{
+		{"testdata/book/always_success.yml", 50, 200 * time.Millisecond, 5, 10, false},
+		{"testdata/book/always_failure.yml", 50, 200 * time.Millisecond, 5, 10, true},
+		{"testdata/book/always_success.yml", 0.1, 100 * time.Millisecond, 1, 1, false},
+	}
+	ctx := context.Background()
+	for _, tt := range tests {
+		t.Run(tt.book, func(t *testing.T) {
+			o, err := New(Book(tt.book), Stdout(io.Discard), Stderr(io.Discard))
+			if err != nil {
+				t.Fatal(err)
+			}
+			r, err := o.Soak(ctx, tt.qps, tt.d)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if r.Total() < tt.wantMinTotal || r.Total() > tt.wantMaxTotal {
+				t.Errorf("got total %d, want between %d and %d", r.Total(), tt.wantMinTotal, tt.wantMaxTotal)
+			}
+			if int64(len(r.Latencies)) != r.Total() {
+				t.Errorf("got %d latencies, want %d", len(r.Latencies), r.Total())
+			}
+			if tt.wantAllFailed {
+				if r.Succeeded != 0 || int64(len(r.Errors)) != r.Failed {
+					t.Errorf("got succeeded %d failed %d errors %d", r.Succeeded, r.Failed, len(r.Errors))
+				}
+			} else if r.Failed != 0 {
+				t.Errorf("got failed %d: %v", r.Failed, r.Errors)
+			}
+			buf := new(bytes.Buffer)
+			if err := r.Report(buf); err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(buf.String(), "Total.........................: ") {
+				t.Errorf("invalid report: %s", buf.String())
+			}
+		})
+	}
+}
+
+func TestSoakCancel(t *testing.T) {
+	o, err := New(Book("testdata/book/always_success.yml"), Stdout(io.Discard))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	r, err := o.Soak(ctx, 10, 10*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if time.Since(start) > 5*time.Second {
+		t.Errorf("soak should stop on cancellation: %v", time.Since(start))
+	}
+	if r.Total() == 0 || r.Total() > 2 {
+		t.Errorf("got total %d", r.Total())
+	}
+}
+
+func TestSoakInvalid(t *testing.T) {
+	o, err := New(Book("testdata/book/always_success.yml"), Stdout(io.Discard))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	if _, err := o.Soak(ctx, 0, time.Second); err == nil {
+		t.Error("want error for qps 0")
+	}
+	if _, err := o.Soak(ctx, 1, 0); err == nil {
+		t.Error("want error for duration 0")
+	}
+}
+
+func TestSoakShuffleSeed(t *testing.T) {
+	seed := int64(3)
+	o, err := New(Book("testdata/book/shuffle_steps.yml"), ShuffleSteps(seed), Stdout(io.Discard))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := o.Soak(context.Background(), 100, 50*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Failed != 0 {
+		t.Errorf("got failed %d: %v", r.Failed, r.Errors)
+	}
+	if got := *o.shuffleStepsSeed; got != seed {
+		t.Errorf("seed should be restored: got %d want %d", got, seed)
+	}
+}
@@ -1,13 +1,29 @@
 package runn
 
-import "errors"
+import (
+	"errors"
+	"net/http"
+	"time"
+)
 
 type step struct {
-	key           string
-	runnerKey     string
-	desc          string
-	ifCond        string
-	loop          *Loop
+	key       string
+	runnerKey string
+	desc      string
+	ifCond    string
+	loop      *Loop
+	// keep the position of the step even if steps are shuffled
+	ordered bool
+	// stop the run of the runbook when the step fails ( even if force is enabled )
+	fatal bool
+	// sub-section of `teardown:` that the step belongs to ( empty for the steps of `steps:` )
+	teardown string
+	// labels of the step to be skipped by SkipStepLabels
+	labels []string
+	// expr to transform the result of the step
+	transform string
+	// the step references prior steps
+	dependent     bool
 	httpRunner    *httpRunner
 	httpRequest   map[string]interface{}
 	dbRunner      *dbRunner
@@ -26,12 +42,36 @@ type step struct {
 	dumpRequest   *dumpRequest
 	bindRunner    *bindRunner
 	bindCond      map[string]string
+	pingRunner    *pingRunner
+	pingTargets   []string
+	waitRunner    *waitRunner
+	waitConfig    map[string]interface{}
+	customRunner  *customRunner
+	customRequest map[string]interface{}
 	includeRunner *includeRunner
 	includeConfig *includeConfig
+	callRunner    *callRunner
+	callConfig    *callConfig
 	// operator related to step
 	parent *operator
 	debug  bool
 	result *StepResult
+	// elapsed time of running the step ( excluding the interval )
+	elapsed time.Duration
+	// time to first byte of the response of the HTTP runner
+	ttfb time.Duration
+	// request sent by the HTTP runner in the last run ( for AssertResponseExample )
+	httpReq *http.Request
+	// results of each condition of `test:`
+	assertions []AssertionResult
+	// location of the step in the runbook file
+	source *stepSource
+}
+
+// stepSource - Location of the step in the runbook file.
+type stepSource struct {
+	path string
+	line int
 }
 
 func newStep(key string, parent *operator) *step {
@@ -84,13 +124,28 @@ func (s *step) setResult(err error) {
 	if s.result != nil {
 		panic("duplicate record of step results")
 	}
+	var (
+		path string
+		line int
+	)
+	if s.source != nil {
+		path = s.source.path
+		line = s.source.line
+	}
 	if errors.Is(errStepSkiped, err) {
-		s.result = &StepResult{Key: s.key, Desc: s.desc, Skipped: true, Err: nil}
+		s.result = &StepResult{Key: s.key, Desc: s.desc, Path: path, Line: line, Skipped: true, Err: nil}
 		return
 	}
-	s.result = &StepResult{Key: s.key, Desc: s.desc, Skipped: false, Err: err}
+	var m *secretMasker
+	if s.parent != nil {
+		m = s.parent.secretMasker
+	}
+	s.result = &StepResult{Key: s.key, Desc: s.desc, Path: path, Line: line, Skipped: false, Err: m.maskError(err), Elapsed: s.elapsed, TTFB: s.ttfb, Assertions: s.assertions, RunnerKey: s.runnerKey, RunnerType: s.generateID().StepRunnerType, Summary: m.mask(s.summary())}
 }
 
 func (s *step) clearResult() {
 	s.result = nil
+	s.elapsed = 0
+	s.assertions = nil
+	s.httpReq = nil
 }
@@ -1,9 +1,15 @@
 package runn
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+)
 
 const (
 	storeVarsKey     = "vars"
+	storeSecretsKey  = "secrets"
 	storeStepsKey    = "steps"
 	storeParentKey   = "parent"
 	storeIncludedKey = "included"
@@ -12,33 +18,86 @@ const (
 	storeFuncValue   = "[func]"
 	storeStepRunKey  = "run"
 	storeOutcomeKey  = "outcome"
+	// index of the current iteration and the results of the iterations of `repeat:`
+	storeIterationKey  = "iteration"
+	storeIterationsKey = "iterations"
+	// values bound by `bind:` namespaced under the step key ( NamespaceBinds )
+	storeBoundKey = "bound"
+	// key of `vars` of the correlation ID generated per runbook ( CorrelationID )
+	correlationIDVarKey = "_correlationId"
 )
 
+var relativeStepIndexRe = regexp.MustCompile(`(^|[^.\w])steps\[\s*-([0-9]+)\s*\]`)
+
 type store struct {
 	steps       []map[string]interface{}
 	stepMapKeys []string
 	stepMap     map[string]map[string]interface{}
 	vars        map[string]interface{}
+	secrets     map[string]interface{}
 	funcs       map[string]interface{}
 	bindVars    map[string]interface{}
 	parentVars  map[string]interface{}
 	useMap      bool // Use map syntax in `steps:`.
 	loopIndex   *int
+	// index of the current iteration of `repeat:`
+	iteration *int
+	// steps of the completed iterations of `repeat:`
+	iterations []interface{}
+	// indexes of listed steps in the order in which they were recorded ( for ShuffleSteps )
+	stepIdxs []int
+	// values bound by `bind:` per step key ( nil unless NamespaceBinds )
+	boundVars map[string]map[string]interface{}
 }
 
 func (s *store) recordAsMapped(k string, v map[string]interface{}) {
 	if !s.useMap {
 		panic("recordAsMapped can only be used if useMap = true")
 	}
+	if _, ok := s.stepMap[k]; ok {
+		s.touchMapped(k)
+	} else {
+		// keep the order of keys of steps
+		s.stepMapKeys = append(s.stepMapKeys, k)
+	}
 	s.stepMap[k] = v
-	s.stepMapKeys = append(s.stepMapKeys, k)
 }
 
-func (s *store) recordAsListed(v map[string]interface{}) {
+// recordAsListed - record the result of the i-th step.
+// The value of the step recorded in the previous loop or recorded as not run is overwritten.
+func (s *store) recordAsListed(i int, v map[string]interface{}) {
 	if s.useMap {
-		panic("recordAsMapped can only be used if useMap = false")
+		panic("recordAsListed can only be used if useMap = false")
+	}
+	if i < len(s.steps) && ((s.loopIndex != nil && *s.loopIndex > 0) || s.steps[i][storeStepRunKey] == false) {
+		s.steps[i] = v
+		s.touchListed(i)
+		return
 	}
 	s.steps = append(s.steps, v)
+	s.touchListed(len(s.steps) - 1)
+}
+
+// touchListed - make the i-th step the latest recorded step.
+func (s *store) touchListed(i int) {
+	for j, idx := range s.stepIdxs {
+		if idx == i {
+			s.stepIdxs = append(s.stepIdxs[:j], s.stepIdxs[j+1:]...)
+			break
+		}
+	}
+	s.stepIdxs = append(s.stepIdxs, i)
+}
+
+// touchMapped - make the step of the key the latest recorded step.
+func (s *store) touchMapped(k string) {
+	for j, kk := range s.stepMapKeys {
+		if kk == k {
+			s.stepMapKeys = append(s.stepMapKeys[:j], s.stepMapKeys[j+1:]...)
+			break
+		}
+	}
+	s.stepMapKeys = append(s.stepMapKeys, k)
 }
 
 func (s *store) length() int {
@@ -50,6 +109,12 @@ func (s *store) length() int {
 
 func (s *store) previous() map[string]interface{} {
 	if !s.useMap {
+		if len(s.stepIdxs) > 0 {
+			if len(s.stepIdxs) < 2 {
+				return nil
+			}
+			return s.steps[s.stepIdxs[len(s.stepIdxs)-2]]
+		}
 		if len(s.steps) < 2 {
 			return nil
 		}
@@ -67,6 +132,9 @@ func (s *store) previous() map[string]interface{} {
 
 func (s *store) latest() map[string]interface{} {
 	if !s.useMap {
+		if len(s.stepIdxs) > 0 {
+			return s.steps[s.stepIdxs[len(s.stepIdxs)-1]]
+		}
 		if len(s.steps) == 0 {
 			return nil
 		}
@@ -84,10 +152,11 @@ func (s *store) latest() map[string]interface{} {
 
 func (s *store) recordToLatest(key string, value interface{}) error {
 	if !s.useMap {
-		if len(s.steps) == 0 {
+		l := s.latest()
+		if l == nil {
 			return errors.New("failed to record")
 		}
-		s.steps[len(s.steps)-1][key] = value
+		l[key] = value
 		return nil
 	}
 	if len(s.stepMapKeys) == 0 {
@@ -101,12 +170,52 @@ func (s *store) recordToLatest(key string, value interface{}) error {
 	return errors.New("failed to record")
 }
 
+// resolveRelativeIndex - rewrite relative step indexes ( e.g. `steps[-1]` ) in expressions to step keys.
+// When steps are listed, expr supports negative indexes of slices natively.
+func (s *store) resolveRelativeIndex(in interface{}) interface{} {
+	if !s.useMap {
+		return in
+	}
+	switch v := in.(type) {
+	case string:
+		return relativeStepIndexRe.ReplaceAllStringFunc(v, func(m string) string {
+			sm := relativeStepIndexRe.FindStringSubmatch(m)
+			n, err := strconv.Atoi(sm[2])
+			if err != nil {
+				return m
+			}
+			i := len(s.stepMapKeys) - n
+			if n == 0 || i < 0 {
+				return m
+			}
+			return fmt.Sprintf("%ssteps[%s]", sm[1], strconv.Quote(s.stepMapKeys[i]))
+		})
+	case map[string]interface{}:
+		resolved := map[string]interface{}{}
+		for k, vv := range v {
+			resolved[k] = s.resolveRelativeIndex(vv)
+		}
+		return resolved
+	case []interface{}:
+		resolved := []interface{}{}
+		for _, vv := range v {
+			resolved = append(resolved, s.resolveRelativeIndex(vv))
+		}
+		return resolved
+	default:
+		return in
+	}
+}
+
 func (s *store) toNormalizedMap() map[string]interface{} {
 	store := map[string]interface{}{}
 	for k := range s.funcs {
 		store[k] = storeFuncValue
 	}
 	store[storeVarsKey] = s.vars
+	if len(s.secrets) > 0 {
+		store[storeSecretsKey] = s.secrets
+	}
 	if s.useMap {
 		store[storeStepsKey] = s.stepMap
 	} else {
@@ -115,9 +224,16 @@ func (s *store) toNormalizedMap() map[string]interface{} {
 	for k, v := range s.bindVars {
 		store[k] = v
 	}
+	if s.boundVars != nil {
+		store[storeBoundKey] = s.boundVars
+	}
 	if s.loopIndex != nil {
 		store[loopCountVarKey] = *s.loopIndex
 	}
+	if s.iteration != nil {
+		store[storeIterationKey] = *s.iteration
+		store[storeIterationsKey] = s.iterations
+	}
 	return store
 }
 
@@ -127,6 +243,9 @@ func (s *store) toMap() map[string]interface{} {
 		store[k] = v
 	}
 	store[storeVarsKey] = s.vars
+	if len(s.secrets) > 0 {
+		store[storeSecretsKey] = s.secrets
+	}
 	if s.useMap {
 		store[storeStepsKey] = s.stepMap
 	} else {
@@ -138,17 +257,37 @@ func (s *store) toMap() map[string]interface{} {
 	for k, v := range s.bindVars {
 		store[k] = v
 	}
+	if s.boundVars != nil {
+		store[storeBoundKey] = s.boundVars
+	}
 	if s.loopIndex != nil {
 		store[loopCountVarKey] = *s.loopIndex
 	}
+	if s.iteration != nil {
+		store[storeIterationKey] = *s.iteration
+		store[storeIterationsKey] = s.iterations
+	}
 	return store
 }
 
+// bind binds the value with the key, and also under the step key when the values are namespaced ( NamespaceBinds ).
+func (s *store) bind(stepKey, k string, v interface{}) {
+	s.bindVars[k] = v
+	if s.boundVars == nil {
+		return
+	}
+	if _, ok := s.boundVars[stepKey]; !ok {
+		s.boundVars[stepKey] = map[string]interface{}{}
+	}
+	s.boundVars[stepKey][k] = v
+}
+
 func (s *store) clearSteps() {
 	s.steps = []map[string]interface{}{}
 	s.stepMapKeys = []string{}
 	s.stepMap = map[string]map[string]interface{}{}
-	// keep vars, bindVars
+	s.stepIdxs = nil
+	// keep vars, bindVars, boundVars
 	s.parentVars = map[string]interface{}{}
 	s.loopIndex = nil
 }
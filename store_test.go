@@ -0,0 +1,72 @@
+package runn
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestResolveRelativeIndex(t *testing.T) {
+	listed := &store{
+		steps: []map[string]interface{}{
+			{"key": "zero"},
+			{"key": "one"},
+		},
+	}
+	mapped := &store{
+		stepMapKeys: []string{"req", "check"},
+		stepMap: map[string]map[string]interface{}{
+			"req":   {"key": "zero"},
+			"check": {"key": "one"},
+		},
+		useMap: true,
+	}
+	tests := []struct {
+		store *store
+		in    interface{}
+		want  interface{}
+	}{
+		{listed, "steps[-1].key", "steps[-1].key"},
+		{mapped, "steps[-1].key", `steps["check"].key`},
+		{mapped, "steps[ -2 ].key == 'zero'", `steps["req"].key == 'zero'`},
+		{mapped, "len(steps[-1]) > 0 && steps[-2].key", `len(steps["check"]) > 0 && steps["req"].key`},
+		{mapped, "parent.steps[-1].key", "parent.steps[-1].key"},
+		{mapped, "steps[-3].key", "steps[-3].key"},
+		{mapped, "steps.req.key", "steps.req.key"},
+		{
+			mapped,
+			map[string]interface{}{
+				"path": "/users/{{ steps[-1].key }}",
+				"list": []interface{}{"{{ steps[-2].key }}", 1},
+			},
+			map[string]interface{}{
+				"path": `/users/{{ steps["check"].key }}`,
+				"list": []interface{}{`{{ steps["req"].key }}`, 1},
+			},
+		},
+	}
+	for _, tt := range tests {
+		got := tt.store.resolveRelativeIndex(tt.in)
+		if diff := cmp.Diff(got, tt.want, nil); diff != "" {
+			t.Errorf("%s", diff)
+		}
+	}
+}
+
+func TestRelativeIndexEval(t *testing.T) {
+	s := &store{
+		stepMapKeys: []string{"req", "check"},
+		stepMap: map[string]map[string]interface{}{
+			"req":   {"key": "zero"},
+			"check": {"key": "one"},
+		},
+		useMap: true,
+	}
+	got, err := EvalCond(s.resolveRelativeIndex("steps[-1].key == 'one' && steps[-2].key == 'zero'").(string), s.toMap())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got {
+		t.Error("got false want true")
+	}
+}
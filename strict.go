@@ -0,0 +1,178 @@
+package runn
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/antonmedv/expr/ast"
+	exprbuiltin "github.com/antonmedv/expr/builtin"
+	"github.com/antonmedv/expr/parser"
+)
+
+// undefinedVarError - error of the variable that is referenced in `{{ }}` but is not defined ( for StrictVars ).
+type undefinedVarError struct {
+	name string
+	expr string
+}
+
+func (e *undefinedVarError) Error() string {
+	return fmt.Sprintf("undefined variable '%s' in '%s%s%s'", e.name, delimStart, e.expr, delimEnd)
+}
+
+// findUndefinedVar - return the error of the first undefined variable referenced in `{{ }}` of in.
+func findUndefinedVar(in interface{}, store map[string]interface{}) error {
+	switch v := in.(type) {
+	case string:
+		for _, e := range extractExprs(v) {
+			t, err := parser.Parse(replaceContainsFuncCall(trimComment(e)))
+			if err != nil {
+				// syntax errors are reported by the evaluation
+				continue
+			}
+			uv := &undefinedVarsVisitor{store: store}
+			ast.Walk(&t.Node, uv)
+			if len(uv.undefined) > 0 {
+				return &undefinedVarError{name: uv.undefined[0], expr: e}
+			}
+		}
+	case map[string]interface{}:
+		for k, vv := range v {
+			if err := findUndefinedVar(k, store); err != nil {
+				return err
+			}
+			if err := findUndefinedVar(vv, store); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		for _, vv := range v {
+			if err := findUndefinedVar(vv, store); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// extractExprs - extract expressions enclosed in `{{ }}`.
+func extractExprs(s string) []string {
+	exprs := []string{}
+	for {
+		i := strings.Index(s, delimStart)
+		if i < 0 {
+			break
+		}
+		s = s[i+len(delimStart):]
+		j := strings.Index(s, delimEnd)
+		if j < 0 {
+			break
+		}
+		exprs = append(exprs, strings.TrimSpace(s[:j]))
+		s = s[j+len(delimEnd):]
+	}
+	return exprs
+}
+
+type undefinedVarsVisitor struct {
+	store     map[string]interface{}
+	undefined []string
+}
+
+func (v *undefinedVarsVisitor) Visit(node *ast.Node) {
+	switch n := (*node).(type) {
+	case *ast.IdentifierNode:
+		if isExprBuiltin(n.Value) {
+			return
+		}
+		if _, ok := v.store[n.Value]; !ok {
+			v.undefined = append(v.undefined, n.Value)
+		}
+	case *ast.MemberNode:
+		if n.Optional {
+			return
+		}
+		path, ok := memberPath(n)
+		if !ok || len(path) < 2 || (path[0] != storeVarsKey && path[0] != storeSecretsKey) {
+			return
+		}
+		// vars.<key>.<key>... or secrets.<key>.<key>...
+		m, ok := v.store[path[0]].(map[string]interface{})
+		if !ok {
+			return
+		}
+		for _, k := range path[1 : len(path)-1] {
+			m, ok = m[k].(map[string]interface{})
+			if !ok {
+				// the undefined key is reported by the inner member node
+				return
+			}
+		}
+		if _, ok := m[path[len(path)-1]]; !ok {
+			v.undefined = append(v.undefined, strings.Join(path, "."))
+		}
+	}
+}
+
+// memberPath - return the path of the member node such as ["vars", "key"] for `vars.key` and `vars["key"]`.
+func memberPath(n *ast.MemberNode) ([]string, bool) {
+	p, ok := n.Property.(*ast.StringNode)
+	if !ok {
+		return nil, false
+	}
+	switch nn := n.Node.(type) {
+	case *ast.IdentifierNode:
+		return []string{nn.Value, p.Value}, true
+	case *ast.MemberNode:
+		path, ok := memberPath(nn)
+		if !ok {
+			return nil, false
+		}
+		return append(path, p.Value), true
+	default:
+		return nil, false
+	}
+}
+
+// isExprBuiltin - whether the name is the builtin function of expr such as `len`.
+func isExprBuiltin(name string) bool {
+	for _, f := range exprbuiltin.Builtins {
+		if f.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// unknownStepKeys returns the sorted keys of the step that are neither built-in runners, built-in sections nor configured runners.
+func (o *operator) unknownStepKeys(s map[string]interface{}) []string {
+	unknown := []string{}
+	for k := range s {
+		switch k {
+		case includeRunnerKey, testRunnerKey, dumpRunnerKey, execRunnerKey, bindRunnerKey, pingRunnerKey, waitRunnerKey, callRunnerKey,
+			ifSectionKey, descSectionKey, loopSectionKey, retryUntilSectionKey, orderedSectionKey, fatalSectionKey, labelsSectionKey, transformSectionKey:
+			continue
+		}
+		if _, ok := o.httpRunners[k]; ok {
+			continue
+		}
+		if _, ok := o.dbRunners[k]; ok {
+			continue
+		}
+		if _, ok := o.grpcRunners[k]; ok {
+			continue
+		}
+		if _, ok := o.cdpRunners[k]; ok {
+			continue
+		}
+		if _, ok := o.sshRunners[k]; ok {
+			continue
+		}
+		if _, ok := o.customRunners[k]; ok {
+			continue
+		}
+		unknown = append(unknown, k)
+	}
+	sort.Strings(unknown)
+	return unknown
+}
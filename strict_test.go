@@ -0,0 +1,125 @@
+package runn
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestFindUndefinedVar(t *testing.T) {
+	store := map[string]interface{}{
+		"vars": map[string]interface{}{
+			"user": map[string]interface{}{
+				"name": "alice",
+			},
+			"ids": []interface{}{1, 2},
+		},
+		"steps": []map[string]interface{}{},
+		"bound": "value",
+	}
+	tests := []struct {
+		in   interface{}
+		want string
+	}{
+		{"no expression", ""},
+		{"{{ vars.user.name }}", ""},
+		{`{{ vars["user"]["name"] }}`, ""},
+		{"{{ vars.ids[0] }}", ""},
+		{"{{ bound }} and {{ len(steps) }}", ""},
+		{"{{ vars.usr.name }}", "undefined variable 'vars.usr' in '{{vars.usr.name}}'"},
+		{"{{ vars.user.nmae }}", "undefined variable 'vars.user.nmae' in '{{vars.user.nmae}}'"},
+		{"{{ vars.user?.nmae }}", ""},
+		{"/users/{{ bound }}/{{ bnd }}", "undefined variable 'bnd' in '{{bnd}}'"},
+		{
+			map[string]interface{}{
+				"/users": map[string]interface{}{
+					"get": map[string]interface{}{
+						"headers": map[string]interface{}{
+							"Authorization": "Bearer {{ vars.token }}",
+						},
+					},
+				},
+			},
+			"undefined variable 'vars.token' in '{{vars.token}}'",
+		},
+		{
+			[]interface{}{"{{ bound }}", map[string]interface{}{"{{ key }}": "value"}},
+			"undefined variable 'key' in '{{key}}'",
+		},
+	}
+	for _, tt := range tests {
+		err := findUndefinedVar(tt.in, store)
+		if tt.want == "" {
+			if err != nil {
+				t.Errorf("got %v\nwant no error", err)
+			}
+			continue
+		}
+		if err == nil || err.Error() != tt.want {
+			t.Errorf("got %v\nwant %v", err, tt.want)
+		}
+	}
+}
+
+func TestStrictVars(t *testing.T) {
+	tests := []struct {
+		strictVars bool
+		vars       map[string]interface{}
+		wantErr    string
+	}{
+		{false, nil, ""},
+		{true, map[string]interface{}{"key": "value"}, ""},
+		{true, nil, "undefined variable 'vars.key' in '{{vars.key}}' on 'Reference variables in steps'.steps[2]"},
+	}
+	ctx := context.Background()
+	for _, tt := range tests {
+		opts := []Option{Book("testdata/strict_vars.yml"), StrictVars(tt.strictVars)}
+		if tt.vars != nil {
+			opts = append(opts, Var("key", "user"))
+		}
+		o, err := New(opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = o.Run(ctx)
+		if tt.wantErr == "" {
+			if err != nil {
+				t.Error(err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("got %v\nwant %v", err, tt.wantErr)
+		}
+	}
+}
+
+func TestStrictKeys(t *testing.T) {
+	tests := []struct {
+		strictKeys bool
+		step       map[string]interface{}
+		wantErr    string
+	}{
+		{false, map[string]interface{}{"test": "true", "dumP": "current"}, ""},
+		{true, map[string]interface{}{"test": "true", "dump": "current"}, ""},
+		{true, map[string]interface{}{"desc": "Get users", "req": map[string]interface{}{}, "test": "true"}, ""},
+		{true, map[string]interface{}{"test": "true", "dumP": "current"}, "unknown keys in step: dumP"},
+		{true, map[string]interface{}{"tests": "true", "ifs": "true", "req": map[string]interface{}{}}, "unknown keys in step: ifs, tests"},
+	}
+	for _, tt := range tests {
+		o, err := New(HTTPRunner("req", "https://api.example.com", nil), StrictKeys(tt.strictKeys))
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = o.AppendStep("0", tt.step)
+		if tt.wantErr == "" {
+			if err != nil && strings.Contains(err.Error(), "unknown keys") {
+				t.Error(err)
+			}
+			continue
+		}
+		if err == nil || err.Error() != tt.wantErr {
+			t.Errorf("got %v\nwant %v", err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package runn
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.uber.org/multierr"
+)
+
+const (
+	teardownAlwaysKey    = "always"
+	teardownOnFailureKey = "onFailure"
+	teardownOnSuccessKey = "onSuccess"
+)
+
+// teardownSectionKeys - sub-sections of `teardown:` in the order of running.
+// Diagnostics on failure run before the cleanup by `always:`.
+var teardownSectionKeys = []string{teardownOnFailureKey, teardownOnSuccessKey, teardownAlwaysKey}
+
+// runTeardown - run the steps of the sub-sections of `teardown:` selected by the result of the steps.
+// All selected steps run even if some of them fail, and the steps not selected are recorded as skipped.
+func (o *operator) runTeardown(ctx context.Context, failed bool) error {
+	var terr error
+	for i, s := range o.steps {
+		if s.teardown == "" {
+			continue
+		}
+		if !s.teardownSelected(failed) {
+			s.setResult(errStepSkiped)
+			o.recordNotRun(i)
+			o.recordToLatest(storeOutcomeKey, resultSkipped)
+			continue
+		}
+		err := o.runStep(ctx, i, s)
+		s.setResult(err)
+		switch {
+		case errors.Is(errStepSkiped, err):
+			o.recordNotRun(i)
+			o.recordToLatest(storeOutcomeKey, resultSkipped)
+		case err != nil:
+			o.recordNotRun(i)
+			o.recordToLatest(storeOutcomeKey, resultFailure)
+			terr = multierr.Append(terr, fmt.Errorf("teardown.%s failed on %s: %w", s.teardown, o.stepName(i), err))
+		default:
+			o.recordToLatest(storeOutcomeKey, resultSuccess)
+		}
+	}
+	return terr
+}
+
+// teardownSelected - whether the step of `teardown:` runs on the result of the steps.
+func (s *step) teardownSelected(failed bool) bool {
+	switch s.teardown {
+	case teardownAlwaysKey:
+		return true
+	case teardownOnFailureKey:
+		return failed
+	case teardownOnSuccessKey:
+		return !failed
+	default:
+		return false
+	}
+}
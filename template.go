@@ -0,0 +1,31 @@
+package runn
+
+import "fmt"
+
+const useSectionKey = "use"
+
+// resolveTemplate returns the step that the template of `use:` is merged with the step ( the values of the step take precedence ).
+// Maps are merged recursively and the other values ( including arrays ) are replaced.
+func (o *operator) resolveTemplate(s map[string]interface{}) (map[string]interface{}, error) {
+	v := s[useSectionKey]
+	name, ok := v.(string)
+	if !ok {
+		return nil, fmt.Errorf("invalid use: %v", v)
+	}
+	t, ok := o.templates[name]
+	if !ok {
+		return nil, fmt.Errorf("template not found: %s", name)
+	}
+	overrides := map[string]interface{}{}
+	for k, vv := range s {
+		if k == useSectionKey {
+			continue
+		}
+		overrides[k] = vv
+	}
+	resolved := deepMerge(dcopy(t).(map[string]interface{}), overrides)
+	if err := validateStepKeys(resolved); err != nil {
+		return nil, fmt.Errorf("invalid step using template '%s'. %w", name, err)
+	}
+	return resolved, nil
+}
@@ -14,6 +14,8 @@ type testRunner struct {
 type condFalseError struct {
 	cond string
 	tree string
+	// diffs of the `compare` and `matchesAny` calls that are false
+	diffs string
 }
 
 func newCondFalseError(cond, tree string) *condFalseError {
@@ -24,9 +26,22 @@ func newCondFalseError(cond, tree string) *condFalseError {
 }
 
 func (fe *condFalseError) Error() string {
+	if fe.diffs != "" {
+		return fmt.Sprintf("(%s) is not true\n%s%s", fe.cond, fe.tree, fe.diffs)
+	}
 	return fmt.Sprintf("(%s) is not true\n%s", fe.cond, fe.tree)
 }
 
+// AssertionResult - Result of each condition of `test:` split by the top-level `&&`.
+type AssertionResult struct {
+	// Cond is the expression of the condition
+	Cond string `json:"cond"`
+	// Result is the evaluated boolean of the condition
+	Result bool `json:"result"`
+	// Values are the values involved in the condition ( except literals ) when it is not true
+	Values map[string]interface{} `json:"values,omitempty"`
+}
+
 func newTestRunner(o *operator) (*testRunner, error) {
 	return &testRunner{
 		operator: o,
@@ -42,6 +57,7 @@ func (rnr *testRunner) Run(ctx context.Context, cond string, first bool) error {
 		store[storePreviousKey] = rnr.operator.store.previous()
 		store[storeCurrentKey] = rnr.operator.store.latest()
 	}
+	cond = rnr.operator.store.resolveRelativeIndex(cond).(string)
 	t, err := buildTree(cond, store)
 	if err != nil {
 		return err
@@ -50,11 +66,52 @@ func (rnr *testRunner) Run(ctx context.Context, cond string, first bool) error {
 	if err != nil {
 		return err
 	}
+	rnr.recordAssertions(cond, store)
 	if !tf {
-		return newCondFalseError(cond, t)
+		err := newCondFalseError(cond, t)
+		err.diffs = compareDiffs(cond, store)
+		return err
 	}
 	if first {
 		rnr.operator.record(nil)
 	}
 	return nil
 }
+
+// recordAssertions evaluates each condition of `test:` and sets the results to the current step.
+func (rnr *testRunner) recordAssertions(cond string, store map[string]interface{}) {
+	o := rnr.operator
+	if o.stepIdx >= len(o.steps) {
+		return
+	}
+	assertions := []AssertionResult{}
+	for _, c := range splitCond(cond) {
+		a := AssertionResult{Cond: c}
+		tf, err := EvalCond(c, store)
+		if err == nil && tf {
+			a.Result = true
+			assertions = append(assertions, a)
+			continue
+		}
+		vs, err := values(replaceContainsFuncCall(c))
+		if err != nil {
+			assertions = append(assertions, a)
+			continue
+		}
+		for _, v := range vs {
+			if isLiteral(v) {
+				continue
+			}
+			vv, err := Eval(v, store)
+			if err != nil {
+				continue
+			}
+			if a.Values == nil {
+				a.Values = map[string]interface{}{}
+			}
+			a.Values[v] = o.secretMasker.maskValue(vv)
+		}
+		assertions = append(assertions, a)
+	}
+	o.steps[o.stepIdx].assertions = assertions
+}
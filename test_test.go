@@ -3,7 +3,10 @@ package runn
 import (
 	"context"
 	"errors"
+	"strings"
 	"testing"
+
+	"github.com/google/go-cmp/cmp"
 )
 
 func TestTestRun(t *testing.T) {
@@ -16,6 +19,16 @@ func TestTestRun(t *testing.T) {
 		{"vars.foo.bar == 'xxx'", false, &condFalseError{}},
 		{"steps[0].res.status == 403", false, nil},
 		{"current.res.status == 403", false, nil},
+		{"jsonpath(current.res.body, '$.items[?(@.active == true)].id') == [1, 3]", false, nil},
+		{"len(jsonpath(current.res.body, '$.items[*].id')) == 3", false, nil},
+		{"len(jsonpath(current.res.body, '$.items[?(@.id > 3)]')) == 0", false, nil},
+		{"contains(current.res.body, {items: [{id: 2, active: false}]})", false, nil},
+		{"contains(current.res.body, {items: [{id: 4}]})", false, &condFalseError{}},
+		{"'status' contains 'tat' && contains(current.res, {status: 403})", false, nil},
+		{"approx(current.res.status, 403.0001, 0.001)", false, nil},
+		{"approx(current.res.status, 404, 0.5)", false, &condFalseError{}},
+		{"isSorted(current.res.body.items, 'id')", false, nil},
+		{"isSorted(current.res.body.items, 'id', 'desc')", false, &condFalseError{}},
 	}
 	ctx := context.Background()
 	for _, tt := range tests {
@@ -27,6 +40,13 @@ func TestTestRun(t *testing.T) {
 				{
 					"res": map[string]interface{}{
 						"status": 403,
+						"body": map[string]interface{}{
+							"items": []interface{}{
+								map[string]interface{}{"id": 1, "active": true},
+								map[string]interface{}{"id": 2, "active": false},
+								map[string]interface{}{"id": 3, "active": true},
+							},
+						},
 					},
 				},
 			}
@@ -49,3 +69,143 @@ func TestTestRun(t *testing.T) {
 		})
 	}
 }
+
+func TestTestRunCompareDiff(t *testing.T) {
+	tests := []struct {
+		cond      string
+		wantDiffs []string
+	}{
+		{`compare(steps[0].res.body, steps[1].res.body, "meta.requestId")`, []string{`compare(steps[0].res.body, steps[1].res.body, "meta.requestId") diff:`, `"name": string("alice")`, `"name": string("bob")`}},
+		{`compare(steps[0].res.body, steps[1].res.body, "meta.requestId", "name")`, nil},
+		{`steps[0].res.status == 200 && compare(steps[0].res.body, steps[1].res.body, "name")`, []string{`"requestId": string("a")`, `"requestId": string("b")`}},
+		{`steps[0].res.status == 201 && compare(steps[0].res.body, steps[1].res.body, "meta.requestId", "name")`, []string{}},
+	}
+	ctx := context.Background()
+	for _, tt := range tests {
+		t.Run(tt.cond, func(t *testing.T) {
+			o, err := New()
+			if err != nil {
+				t.Fatal(err)
+			}
+			o.store.steps = []map[string]interface{}{
+				{"res": map[string]interface{}{"status": 200, "body": map[string]interface{}{"name": "alice", "meta": map[string]interface{}{"requestId": "a"}}}},
+				{"res": map[string]interface{}{"status": 200, "body": map[string]interface{}{"name": "bob", "meta": map[string]interface{}{"requestId": "b"}}}},
+			}
+			r, err := newTestRunner(o)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = r.Run(ctx, tt.cond, false)
+			if tt.wantDiffs == nil {
+				if err != nil {
+					t.Error(err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("want error")
+			}
+			if len(tt.wantDiffs) == 0 && strings.Contains(err.Error(), "diff:") {
+				t.Errorf("got %v", err)
+			}
+			for _, want := range tt.wantDiffs {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("got %v\nwant %v", err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestTestRunMatchesAnyDiff(t *testing.T) {
+	tests := []struct {
+		cond      string
+		wantDiffs []string
+	}{
+		{`matchesAny(steps[0].res.body, [{name: "alice"}, {name: "bob"}])`, nil},
+		{`matchesAny(steps[0].res.body, [vars.userSchema, {error: "not found"}])`, nil},
+		{`matchesAny(steps[0].res.body, [{name: "bob"}, {error: "not found"}])`, []string{`matchesAny(steps[0].res.body, [{name: "bob"}, {error: "not found"}]) diff:`, `candidate[0]:`, `.name: got "alice", want "bob"`, `candidate[1]:`, `.error: missing`}},
+		{`matchesAny(steps[0].res.body, [vars.errorSchema])`, []string{`candidate[0] (schema):`, `/error: property "error" is missing`}},
+	}
+	ctx := context.Background()
+	for _, tt := range tests {
+		t.Run(tt.cond, func(t *testing.T) {
+			o, err := New(Var("userSchema", map[string]interface{}{
+				"$schema":    "https://json-schema.org/draft/2020-12/schema",
+				"type":       "object",
+				"required":   []interface{}{"name"},
+				"properties": map[string]interface{}{"name": map[string]interface{}{"type": "string"}},
+			}), Var("errorSchema", map[string]interface{}{
+				"$schema":  "https://json-schema.org/draft/2020-12/schema",
+				"type":     "object",
+				"required": []interface{}{"error"},
+			}))
+			if err != nil {
+				t.Fatal(err)
+			}
+			o.store.steps = []map[string]interface{}{
+				{"res": map[string]interface{}{"status": 200, "body": map[string]interface{}{"name": "alice", "meta": map[string]interface{}{"requestId": "a"}}}},
+			}
+			r, err := newTestRunner(o)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = r.Run(ctx, tt.cond, false)
+			if tt.wantDiffs == nil {
+				if err != nil {
+					t.Error(err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("want error")
+			}
+			for _, want := range tt.wantDiffs {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("got %v\nwant %v", err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestTestRunAssertions(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []Option
+		want []AssertionResult
+	}{
+		{
+			"all true",
+			nil,
+			[]AssertionResult{
+				{Cond: "vars.status == 200", Result: true},
+				{Cond: "vars.name == 'alice'", Result: true},
+				{Cond: "len(vars.items) == 3", Result: true},
+			},
+		},
+		{
+			"some false",
+			[]Option{Var("name", "bob"), Var("items", []interface{}{1})},
+			[]AssertionResult{
+				{Cond: "vars.status == 200", Result: true},
+				{Cond: "vars.name == 'alice'", Result: false, Values: map[string]interface{}{"vars.name": "bob"}},
+				{Cond: "len(vars.items) == 3", Result: false, Values: map[string]interface{}{"len(vars.items)": 1, "vars.items": []interface{}{1}}},
+			},
+		},
+	}
+	ctx := context.Background()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o, err := New(append([]Option{Book("testdata/test_assertions.yml")}, tt.opts...)...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_ = o.Run(ctx)
+			got := o.Result().StepResults[0].Assertions
+			if diff := cmp.Diff(got, tt.want, nil); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
@@ -60,3 +60,11 @@ INSERT INTO various_types (
   '2022-01-03 10:57:00',
   'TWO'
 );
+
+DELIMITER //
+CREATE PROCEDURE multiple_result_sets()
+BEGIN
+  SELECT 1 AS one;
+  SELECT 2 AS two, 3 AS three;
+END //
+DELIMITER ;
@@ -0,0 +1,394 @@
+package runn
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/goccy/go-json"
+	"go.uber.org/multierr"
+)
+
+// TraceVersion is the version of the format of the trace file.
+const TraceVersion = 1
+
+const (
+	traceEventHeader               = "header"
+	traceEventRunbookStart         = "runbook_start"
+	traceEventRunbookResult        = "runbook_result"
+	traceEventRunbookEnd           = "runbook_end"
+	traceEventStepStart            = "step_start"
+	traceEventStepResult           = "step_result"
+	traceEventHTTPRequest          = "http_request"
+	traceEventHTTPResponse         = "http_response"
+	traceEventGRPCStart            = "grpc_start"
+	traceEventGRPCRequestHeaders   = "grpc_request_headers"
+	traceEventGRPCRequestMessage   = "grpc_request_message"
+	traceEventGRPCResponseStatus   = "grpc_response_status"
+	traceEventGRPCResponseHeaders  = "grpc_response_headers"
+	traceEventGRPCResponseMessage  = "grpc_response_message"
+	traceEventGRPCResponseTrailers = "grpc_response_trailers"
+	traceEventGRPCClientClose      = "grpc_client_close"
+	traceEventGRPCEnd              = "grpc_end"
+	traceEventCDPStart             = "cdp_start"
+	traceEventCDPAction            = "cdp_action"
+	traceEventCDPResponse          = "cdp_response"
+	traceEventCDPEnd               = "cdp_end"
+	traceEventSSHCommand           = "ssh_command"
+	traceEventSSHStdout            = "ssh_stdout"
+	traceEventSSHStderr            = "ssh_stderr"
+	traceEventDBStatement          = "db_statement"
+	traceEventDBResponse           = "db_response"
+	traceEventExecCommand          = "exec_command"
+	traceEventExecEnv              = "exec_env"
+	traceEventExecStdin            = "exec_stdin"
+	traceEventExecStdout           = "exec_stdout"
+	traceEventExecStderr           = "exec_stderr"
+)
+
+// TraceEvent - Event of the run written to the trace file ( TraceFile ) as a line of JSON ( JSON Lines ).
+type TraceEvent struct {
+	// Time is the time when the event occurred
+	Time time.Time `json:"time"`
+	// Offset is the elapsed seconds from the start of the trace ( like the events of asciinema )
+	Offset float64 `json:"offset"`
+	// Type is the type of the event ( e.g. "http_request", "step_result" )
+	Type string `json:"type"`
+	// IDs are the IDs of the runbook and the step where the event occurred
+	IDs IDs `json:"ids,omitempty"`
+	// Data is the payload of the event
+	Data map[string]interface{} `json:"data,omitempty"`
+}
+
+var _ Capturer = (*tracer)(nil)
+
+// traceFile - Trace file shared among the runbooks.
+// The file is open while any runbook is running, and closed when all runbooks finish.
+type traceFile struct {
+	path   string
+	start  time.Time
+	out    *os.File
+	active int
+	errs   error
+	mu     sync.Mutex
+}
+
+func newTraceFile(path string) (*traceFile, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	tf := &traceFile{
+		path:  path,
+		start: time.Now(),
+		out:   f,
+	}
+	tf.write(nil, traceEventHeader, map[string]interface{}{"version": TraceVersion})
+	if err := f.Close(); err != nil {
+		tf.errs = multierr.Append(tf.errs, err)
+	}
+	tf.out = nil
+	if tf.errs != nil {
+		return nil, tf.errs
+	}
+	return tf, nil
+}
+
+// open opens the trace file when the first running runbook starts.
+func (tf *traceFile) open() {
+	tf.mu.Lock()
+	defer tf.mu.Unlock()
+	tf.active++
+	if tf.out != nil {
+		return
+	}
+	f, err := os.OpenFile(tf.path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		tf.errs = multierr.Append(tf.errs, err)
+		return
+	}
+	tf.out = f
+}
+
+// close closes the trace file when the last running runbook ends.
+func (tf *traceFile) close() {
+	tf.mu.Lock()
+	defer tf.mu.Unlock()
+	if tf.active > 0 {
+		tf.active--
+	}
+	if tf.active > 0 || tf.out == nil {
+		return
+	}
+	if err := tf.out.Close(); err != nil {
+		tf.errs = multierr.Append(tf.errs, err)
+	}
+	tf.out = nil
+}
+
+// write writes the event as a line of JSON. The writes are not buffered so that the trace is left even if the run is interrupted.
+func (tf *traceFile) write(ids IDs, typ string, data map[string]interface{}) {
+	tf.mu.Lock()
+	defer tf.mu.Unlock()
+	if tf.out == nil {
+		// not running ( e.g. the trace file failed to be opened )
+		return
+	}
+	now := time.Now()
+	b, err := json.Marshal(&TraceEvent{
+		Time:   now,
+		Offset: now.Sub(tf.start).Seconds(),
+		Type:   typ,
+		IDs:    ids,
+		Data:   data,
+	})
+	if err != nil {
+		tf.errs = multierr.Append(tf.errs, err)
+		return
+	}
+	if _, err := tf.out.Write(append(b, '\n')); err != nil {
+		tf.errs = multierr.Append(tf.errs, err)
+	}
+}
+
+// tracer - Capturer that records the run of the runbook to the trace file.
+// Each runbook has its own tracer to keep the IDs of the current step, because the runbooks sharing the trace file may run concurrently.
+type tracer struct {
+	file       *traceFile
+	currentIDs IDs
+	mu         sync.Mutex
+}
+
+func newTracer(file *traceFile) *tracer {
+	return &tracer{file: file}
+}
+
+// write writes the event with the IDs ( the IDs of the current step if ids is nil ).
+func (t *tracer) write(ids IDs, typ string, data map[string]interface{}) {
+	if ids == nil {
+		t.mu.Lock()
+		ids = t.currentIDs
+		t.mu.Unlock()
+	}
+	t.file.write(ids, typ, data)
+}
+
+func (t *tracer) CaptureStart(ids IDs, bookPath, desc string) {
+	t.file.open()
+	t.write(ids, traceEventRunbookStart, map[string]interface{}{"path": bookPath, "desc": desc})
+}
+
+func (t *tracer) CaptureResult(ids IDs, result *RunResult) {
+	for _, sr := range result.StepResults {
+		if sr == nil {
+			continue
+		}
+		d := map[string]interface{}{
+			"key":         sr.Key,
+			"desc":        sr.Desc,
+			"runner_key":  sr.RunnerKey,
+			"runner_type": sr.RunnerType,
+			"summary":     sr.Summary,
+			"skipped":     sr.Skipped,
+			"elapsed":     milliseconds(sr.Elapsed),
+			"assertions":  sr.Assertions,
+		}
+		if sr.TTFB > 0 {
+			d["ttfb"] = milliseconds(sr.TTFB)
+		}
+		if sr.Err != nil {
+			d["error"] = sr.Err.Error()
+		}
+		t.write(ids, traceEventStepResult, d)
+	}
+	d := map[string]interface{}{
+		"path":    result.Path,
+		"desc":    result.Desc,
+		"skipped": result.Skipped,
+	}
+	if result.Err != nil {
+		d["error"] = result.masker.maskError(result.Err).Error()
+	}
+	t.write(ids, traceEventRunbookResult, d)
+}
+
+func (t *tracer) CaptureEnd(ids IDs, bookPath, desc string) {
+	t.write(ids, traceEventRunbookEnd, map[string]interface{}{"path": bookPath, "desc": desc})
+	t.file.close()
+}
+
+func (t *tracer) CaptureHTTPRequest(name string, req *http.Request) {
+	var body string
+	if req.Body != nil && req.Body != http.NoBody {
+		b, err := io.ReadAll(req.Body)
+		if err == nil {
+			req.Body = io.NopCloser(bytes.NewReader(b))
+			body = string(b)
+		}
+	}
+	t.write(nil, traceEventHTTPRequest, map[string]interface{}{
+		"name":    name,
+		"method":  req.Method,
+		"url":     req.URL.String(),
+		"headers": traceHeaders(req.Header),
+		"body":    body,
+	})
+}
+
+func (t *tracer) CaptureHTTPResponse(name string, res *http.Response) {
+	var body string
+	if res.Body != nil && res.Body != http.NoBody {
+		b, err := io.ReadAll(res.Body)
+		if err == nil {
+			res.Body = io.NopCloser(bytes.NewReader(b))
+			body = string(b)
+		}
+	}
+	t.write(nil, traceEventHTTPResponse, map[string]interface{}{
+		"name":    name,
+		"status":  res.StatusCode,
+		"headers": traceHeaders(res.Header),
+		"body":    body,
+	})
+}
+
+func (t *tracer) CaptureGRPCStart(name string, typ GRPCType, service, method string) {
+	t.write(nil, traceEventGRPCStart, map[string]interface{}{"name": name, "type": typ, "service": service, "method": method})
+}
+
+func (t *tracer) CaptureGRPCRequestHeaders(h map[string][]string) {
+	t.write(nil, traceEventGRPCRequestHeaders, map[string]interface{}{"headers": traceHeaders(h)})
+}
+
+func (t *tracer) CaptureGRPCRequestMessage(m map[string]interface{}) {
+	t.write(nil, traceEventGRPCRequestMessage, map[string]interface{}{"message": m})
+}
+
+func (t *tracer) CaptureGRPCResponseStatus(status int) {
+	t.write(nil, traceEventGRPCResponseStatus, map[string]interface{}{"status": status})
+}
+
+func (t *tracer) CaptureGRPCResponseHeaders(h map[string][]string) {
+	t.write(nil, traceEventGRPCResponseHeaders, map[string]interface{}{"headers": traceHeaders(h)})
+}
+
+func (t *tracer) CaptureGRPCResponseMessage(m map[string]interface{}) {
+	t.write(nil, traceEventGRPCResponseMessage, map[string]interface{}{"message": m})
+}
+
+func (t *tracer) CaptureGRPCResponseTrailers(tr map[string][]string) {
+	t.write(nil, traceEventGRPCResponseTrailers, map[string]interface{}{"trailers": traceHeaders(tr)})
+}
+
+func (t *tracer) CaptureGRPCClientClose() {
+	t.write(nil, traceEventGRPCClientClose, nil)
+}
+
+func (t *tracer) CaptureGRPCEnd(name string, typ GRPCType, service, method string) {
+	t.write(nil, traceEventGRPCEnd, map[string]interface{}{"name": name, "type": typ, "service": service, "method": method})
+}
+
+func (t *tracer) CaptureCDPStart(name string) {
+	t.write(nil, traceEventCDPStart, map[string]interface{}{"name": name})
+}
+
+func (t *tracer) CaptureCDPAction(a CDPAction) {
+	t.write(nil, traceEventCDPAction, map[string]interface{}{"fn": a.Fn, "args": a.Args})
+}
+
+func (t *tracer) CaptureCDPResponse(a CDPAction, res map[string]interface{}) {
+	t.write(nil, traceEventCDPResponse, map[string]interface{}{"fn": a.Fn, "response": res})
+}
+
+func (t *tracer) CaptureCDPEnd(name string) {
+	t.write(nil, traceEventCDPEnd, map[string]interface{}{"name": name})
+}
+
+func (t *tracer) CaptureSSHCommand(command string) {
+	t.write(nil, traceEventSSHCommand, map[string]interface{}{"command": command})
+}
+
+func (t *tracer) CaptureSSHStdout(stdout string) {
+	t.write(nil, traceEventSSHStdout, map[string]interface{}{"stdout": stdout})
+}
+
+func (t *tracer) CaptureSSHStderr(stderr string) {
+	t.write(nil, traceEventSSHStderr, map[string]interface{}{"stderr": stderr})
+}
+
+func (t *tracer) CaptureDBStatement(name string, stmt string) {
+	t.write(nil, traceEventDBStatement, map[string]interface{}{"name": name, "stmt": stmt})
+}
+
+func (t *tracer) CaptureDBResponse(name string, res *DBResponse) {
+	data := map[string]interface{}{
+		"name":           name,
+		"last_insert_id": res.LastInsertID,
+		"rows_affected":  res.RowsAffected,
+		"columns":        res.Columns,
+		"rows":           res.Rows,
+	}
+	if res.Runner != "" {
+		data["served_by"] = res.Runner
+	}
+	if res.DSN != "" {
+		data["dsn"] = res.DSN
+	}
+	t.write(nil, traceEventDBResponse, data)
+}
+
+func (t *tracer) CaptureExecCommand(command string) {
+	t.write(nil, traceEventExecCommand, map[string]interface{}{"command": command})
+}
+
+func (t *tracer) CaptureExecEnv(env map[string]string) {
+	masked := make(map[string]interface{}, len(env))
+	for k := range env {
+		// the values of env are always masked because they often contain credentials
+		masked[k] = maskedValue
+	}
+	t.write(nil, traceEventExecEnv, map[string]interface{}{"env": masked})
+}
+
+func (t *tracer) CaptureExecStdin(stdin string) {
+	t.write(nil, traceEventExecStdin, map[string]interface{}{"stdin": stdin})
+}
+
+func (t *tracer) CaptureExecStdout(stdout string) {
+	t.write(nil, traceEventExecStdout, map[string]interface{}{"stdout": stdout})
+}
+
+func (t *tracer) CaptureExecStderr(stderr string) {
+	t.write(nil, traceEventExecStderr, map[string]interface{}{"stderr": stderr})
+}
+
+func (t *tracer) SetCurrentIDs(ids IDs) {
+	t.mu.Lock()
+	t.currentIDs = ids
+	t.mu.Unlock()
+	if len(ids) > 0 && ids[len(ids)-1].Type == IDTypeStep {
+		t.write(ids, traceEventStepStart, nil)
+	}
+}
+
+func (t *tracer) Errs() error {
+	t.file.mu.Lock()
+	defer t.file.mu.Unlock()
+	return t.file.errs
+}
+
+// traceHeaders returns a copy of the headers whose values of sensitive keys ( e.g. Authorization, Cookie ) are masked.
+func traceHeaders(h map[string][]string) map[string][]string {
+	masked := map[string][]string{}
+	for k, v := range h {
+		if sensitiveKeyRe.MatchString(k) {
+			masked[k] = []string{maskedValue}
+			continue
+		}
+		masked[k] = v
+	}
+	return masked
+}
@@ -0,0 +1,141 @@
+package runn
+
+import (
+	"bufio"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/goccy/go-json"
+	"github.com/k1LoW/runn/testutil"
+)
+
+func TestTraceFile(t *testing.T) {
+	const secret = "s3cr3t-t0ken"
+	ts := testutil.HTTPServer(t)
+	p := filepath.Join(t.TempDir(), "trace.jsonl")
+	o, err := New(Book("testdata/secrets.yml"), HTTPRunner("req", ts.URL, ts.Client()), Secret("apiKey", secret), TraceFile(p))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := o.Run(context.Background()); err == nil {
+		t.Fatal("want error")
+	}
+
+	b, err := os.ReadFile(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(b), secret) {
+		t.Errorf("the trace should be masked: %s", string(b))
+	}
+	var events []TraceEvent
+	s := bufio.NewScanner(strings.NewReader(string(b)))
+	for s.Scan() {
+		e := TraceEvent{}
+		if err := json.Unmarshal(s.Bytes(), &e); err != nil {
+			t.Fatalf("invalid line %q: %v", s.Text(), err)
+		}
+		events = append(events, e)
+	}
+	if len(events) == 0 || events[0].Type != traceEventHeader {
+		t.Fatalf("the trace should start with the header: %v", events)
+	}
+	counts := map[string]int{}
+	var prev float64
+	for _, e := range events {
+		counts[e.Type]++
+		if e.Offset < prev {
+			t.Errorf("the offsets should be in order: %v < %v", e.Offset, prev)
+		}
+		prev = e.Offset
+		switch e.Type {
+		case traceEventHTTPRequest:
+			h, _ := e.Data["headers"].(map[string]interface{})
+			if got := h["Authorization"]; got == nil || got.([]interface{})[0] != maskedValue {
+				t.Errorf("the Authorization header should be masked: %v", h)
+			}
+		case traceEventStepResult:
+			if e.IDs[len(e.IDs)-1].Type != IDTypeRunbook {
+				t.Errorf("the step result should have the IDs of the runbook: %v", e.IDs)
+			}
+		}
+	}
+	want := map[string]int{
+		traceEventRunbookStart:  1,
+		traceEventStepStart:     3,
+		traceEventHTTPRequest:   1,
+		traceEventHTTPResponse:  1,
+		traceEventExecCommand:   1,
+		traceEventExecStdout:    1,
+		traceEventStepResult:    3,
+		traceEventRunbookResult: 1,
+		traceEventRunbookEnd:    1,
+	}
+	for typ, n := range want {
+		if counts[typ] != n {
+			t.Errorf("got %d events of %s, want %d", counts[typ], typ, n)
+		}
+	}
+	if events[len(events)-1].Type != traceEventRunbookEnd {
+		t.Errorf("the trace should end with %s: %v", traceEventRunbookEnd, events[len(events)-1].Type)
+	}
+}
+
+func TestTraceFileConcurrent(t *testing.T) {
+	p := filepath.Join(t.TempDir(), "trace.jsonl")
+	ops, err := Load("testdata/trace/*.yml", RunConcurrent(true, 3), TraceFile(p))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ops.RunN(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	for _, o := range ops.ops {
+		for _, c := range o.capturers {
+			if tc, ok := c.(*tracer); ok && tc.file.out != nil {
+				t.Errorf("the trace file should be closed after the run: %s", o.bookPath)
+			}
+		}
+	}
+
+	b, err := os.ReadFile(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := bufio.NewScanner(strings.NewReader(string(b)))
+	n := 0
+	for s.Scan() {
+		e := TraceEvent{}
+		if err := json.Unmarshal(s.Bytes(), &e); err != nil {
+			t.Fatalf("invalid line %q: %v", s.Text(), err)
+		}
+		if e.Type != traceEventExecCommand {
+			continue
+		}
+		n++
+		var path string
+		for _, id := range e.IDs {
+			if id.Type == IDTypeRunbook {
+				path = id.RunbookPath
+			}
+		}
+		// the command echoes the name of the runbook
+		name := strings.TrimSuffix(filepath.Base(path), ".yml")
+		if got := e.Data["command"].(string); !strings.HasSuffix(got, name) {
+			t.Errorf("the command %q should be recorded with the IDs of its runbook: %s", got, path)
+		}
+	}
+	if want := 9; n != want {
+		t.Errorf("got %d commands, want %d", n, want)
+	}
+}
+
+func TestTraceFileInvalidPath(t *testing.T) {
+	p := filepath.Join(t.TempDir(), "not", "exist", "trace.jsonl")
+	if _, err := New(TraceFile(p)); err == nil {
+		t.Error("want error")
+	}
+}
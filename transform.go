@@ -0,0 +1,25 @@
+package runn
+
+import "fmt"
+
+const (
+	transformSectionKey = "transform"
+	storeTransformedKey = "transformed"
+)
+
+// transform - Evaluate the `transform:` expr of the step against the result of the step and record the value to `transformed`.
+func (o *operator) transform(s *step) error {
+	store := o.store.toMap()
+	store[storeIncludedKey] = o.included
+	store[storePreviousKey] = o.store.previous()
+	store[storeCurrentKey] = o.store.latest()
+	e := o.store.resolveRelativeIndex(s.transform).(string)
+	v, err := Eval(e, store)
+	if err != nil {
+		return err
+	}
+	if err := o.store.recordToLatest(storeTransformedKey, v); err != nil {
+		return fmt.Errorf("failed to record the transformed value: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package runn
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+const waitRunnerKey = "wait"
+
+// waitStoreWaitedKey - key of the time waited ( seconds ).
+const waitStoreWaitedKey = "waited"
+
+const (
+	defaultWaitInterval = 1 * time.Second
+	defaultWaitTimeout  = 1 * time.Minute
+)
+
+type waitRunner struct {
+	operator *operator
+}
+
+// waitConfig - Wait for the duration, until the wall-clock time or until the condition becomes true.
+type waitConfig struct {
+	duration time.Duration
+	// wall-clock time to wait until ( `time:` )
+	at *time.Time
+	// condition to wait until ( `until:` )
+	until string
+	// interval of polling the condition
+	interval time.Duration
+	// timeout of waiting for the condition
+	timeout time.Duration
+}
+
+func newWaitRunner(o *operator) (*waitRunner, error) {
+	return &waitRunner{
+		operator: o,
+	}, nil
+}
+
+func (rnr *waitRunner) Run(ctx context.Context, c *waitConfig) error {
+	start := time.Now()
+	var err error
+	switch {
+	case c.until != "":
+		err = rnr.waitUntil(ctx, c, start)
+	case c.at != nil:
+		err = sleepContext(ctx, time.Until(*c.at))
+	default:
+		err = sleepContext(ctx, c.duration)
+	}
+	rnr.operator.record(map[string]interface{}{
+		waitStoreWaitedKey: time.Since(start).Seconds(),
+	})
+	return err
+}
+
+// waitUntil polls the condition at the interval until it becomes true or the timeout.
+// `current.waited` is the time waited so far ( seconds ) in the condition.
+func (rnr *waitRunner) waitUntil(ctx context.Context, c *waitConfig, start time.Time) error {
+	o := rnr.operator
+	deadline := start.Add(c.timeout)
+	for {
+		store := o.store.toMap()
+		store[storeIncludedKey] = o.included
+		store[storePreviousKey] = o.store.previous()
+		store[storeCurrentKey] = map[string]interface{}{
+			waitStoreWaitedKey: time.Since(start).Seconds(),
+		}
+		tf, err := EvalCond(c.until, store)
+		if err != nil {
+			return err
+		}
+		if tf {
+			return nil
+		}
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			bt, err := buildTree(c.until, store)
+			if err != nil {
+				return err
+			}
+			return fmt.Errorf("timeout (%v): (%s) is not true\n%s", c.timeout, c.until, bt)
+		}
+		if remaining > c.interval {
+			remaining = c.interval
+		}
+		if err := sleepContext(ctx, remaining); err != nil {
+			return err
+		}
+	}
+}
+
+// sleepContext sleeps for the duration or until the context is done.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
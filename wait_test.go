@@ -0,0 +1,91 @@
+package runn
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWaitRunner(t *testing.T) {
+	tests := []struct {
+		wait    interface{}
+		vars    map[string]interface{}
+		wantMin time.Duration
+		wantMax time.Duration
+		wantErr string
+	}{
+		{"100ms", nil, 100 * time.Millisecond, time.Second, ""},
+		{map[string]interface{}{"duration": "100ms"}, nil, 100 * time.Millisecond, time.Second, ""},
+		{0, nil, 0, time.Second, ""},
+		{map[string]interface{}{"time": "{{ vars.at }}"}, map[string]interface{}{"at": 300 * time.Millisecond}, 200 * time.Millisecond, time.Second, ""},
+		{map[string]interface{}{"time": "2000-01-01T00:00:00Z"}, nil, 0, time.Second, ""},
+		{map[string]interface{}{"until": "current.waited >= 0.2", "interval": "50ms"}, nil, 200 * time.Millisecond, time.Second, ""},
+		{map[string]interface{}{"until": "vars.ready", "interval": "50ms"}, map[string]interface{}{"ready": true}, 0, time.Second, ""},
+		{map[string]interface{}{"until": "vars.ready", "interval": "50ms", "timeout": "200ms"}, map[string]interface{}{"ready": false}, 200 * time.Millisecond, time.Second, "timeout (200ms): (vars.ready) is not true"},
+		{map[string]interface{}{"duration": "1sec", "until": "true"}, nil, 0, 0, "one of duration, time and until should be specified"},
+		{map[string]interface{}{"time": "tomorrow"}, nil, 0, 0, "unsupported time format"},
+		{map[string]interface{}{"unknown": "1sec"}, nil, 0, 0, "unknown key unknown"},
+	}
+	ctx := context.Background()
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%v", tt.wait), func(t *testing.T) {
+			opts := []Option{}
+			for k, v := range tt.vars {
+				if d, ok := v.(time.Duration); ok {
+					// the wall-clock time after the duration
+					v = time.Now().Add(d).Format(time.RFC3339Nano)
+				}
+				opts = append(opts, Var(k, v))
+			}
+			o, err := New(opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := o.AppendStep("0", map[string]interface{}{"wait": tt.wait}); err != nil {
+				t.Fatal(err)
+			}
+			start := time.Now()
+			err = o.Run(ctx)
+			elapsed := time.Since(start)
+			if err != nil {
+				if tt.wantErr == "" || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("got %v want %q", err, tt.wantErr)
+				}
+				if tt.wantMin > 0 && elapsed < tt.wantMin {
+					t.Errorf("got %v want >= %v", elapsed, tt.wantMin)
+				}
+				return
+			}
+			if tt.wantErr != "" {
+				t.Fatalf("want error %q", tt.wantErr)
+			}
+			if elapsed < tt.wantMin || elapsed > tt.wantMax {
+				t.Errorf("got %v want %v..%v", elapsed, tt.wantMin, tt.wantMax)
+			}
+			waited, ok := o.store.steps[0][waitStoreWaitedKey].(float64)
+			if !ok {
+				t.Fatalf("waited is not recorded: %v", o.store.steps[0])
+			}
+			if got := time.Duration(waited * float64(time.Second)); got < tt.wantMin || got > elapsed {
+				t.Errorf("got %v want %v..%v", got, tt.wantMin, elapsed)
+			}
+		})
+	}
+}
+
+func TestWaitRunnerCancel(t *testing.T) {
+	o, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := o.AppendStep("0", map[string]interface{}{"wait": "1min"}); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if err := o.Run(ctx); err == nil || !strings.Contains(err.Error(), context.DeadlineExceeded.Error()) {
+		t.Errorf("got %v want %v", err, context.DeadlineExceeded)
+	}
+}